@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"errors"
+	"os"
+	"runtime"
 	"time"
 
 	"github.com/apex/log"
@@ -22,6 +25,10 @@ type buildOpts struct {
 	snapshot      bool
 	skipValidate  bool
 	skipPostHooks bool
+	buildCache    bool
+	singleTarget  bool
+	targets       []string
+	ids           []string
 	rmDist        bool
 	deprecated    bool
 	parallelism   int
@@ -38,6 +45,10 @@ func newBuildCmd() *buildCmd {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if root.opts.singleTarget && len(root.opts.targets) > 0 {
+				return errors.New("--single-target and --targets can't be used together")
+			}
+
 			start := time.Now()
 
 			log.Infof(color.New(color.Bold).Sprint("building..."))
@@ -60,6 +71,10 @@ func newBuildCmd() *buildCmd {
 	cmd.Flags().BoolVar(&root.opts.snapshot, "snapshot", false, "Generate an unversioned snapshot build, skipping all validations and without publishing any artifacts")
 	cmd.Flags().BoolVar(&root.opts.skipValidate, "skip-validate", false, "Skips several sanity checks")
 	cmd.Flags().BoolVar(&root.opts.skipPostHooks, "skip-post-hooks", false, "Skips all post-build hooks")
+	cmd.Flags().BoolVar(&root.opts.buildCache, "build-cache", false, "Uses the cached binaries of the targets that didn't change instead of building them again")
+	cmd.Flags().BoolVar(&root.opts.singleTarget, "single-target", false, "Builds only for the target of the host, or of GOOS and GOARCH if set")
+	cmd.Flags().StringSliceVar(&root.opts.targets, "targets", nil, "Builds only for the given targets, e.g. linux_amd64,darwin_arm64")
+	cmd.Flags().StringSliceVar(&root.opts.ids, "id", nil, "Builds only the builds with the given IDs, and the ones they depend on")
 	cmd.Flags().BoolVar(&root.opts.rmDist, "rm-dist", false, "Remove the dist folder before building")
 	cmd.Flags().IntVarP(&root.opts.parallelism, "parallelism", "p", 4, "Amount tasks to run concurrently")
 	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire build process")
@@ -79,7 +94,7 @@ func buildProject(options buildOpts) (*context.Context, error) {
 	defer cancel()
 	setupBuildContext(ctx, options)
 	return ctx, ctrlc.Default.Run(ctx, func() error {
-		for _, pipe := range pipeline.BuildPipeline {
+		for _, pipe := range pipeline.BuildCmdPipeline {
 			if err := middleware.Logging(
 				pipe.String(),
 				middleware.ErrHandler(pipe.Run),
@@ -98,6 +113,13 @@ func setupBuildContext(ctx *context.Context, options buildOpts) *context.Context
 	ctx.Snapshot = options.snapshot
 	ctx.SkipValidate = ctx.Snapshot || options.skipValidate
 	ctx.SkipPostBuildHooks = options.skipPostHooks
+	ctx.BuildCache = options.buildCache
+	ctx.SingleTarget = options.singleTarget
+	ctx.Targets = options.targets
+	if options.singleTarget {
+		ctx.Targets = []string{hostTarget()}
+	}
+	ctx.BuildIDs = options.ids
 	ctx.RmDist = options.rmDist
 	ctx.SkipTokenCheck = true
 
@@ -105,3 +127,17 @@ func setupBuildContext(ctx *context.Context, options buildOpts) *context.Context
 	ctx.Deprecated = options.deprecated
 	return ctx
 }
+
+// hostTarget is the target of the host, or of the GOOS and GOARCH
+// environment variables if set.
+func hostTarget() string {
+	var goos = runtime.GOOS
+	if s := os.Getenv("GOOS"); s != "" {
+		goos = s
+	}
+	var goarch = runtime.GOARCH
+	if s := os.Getenv("GOARCH"); s != "" {
+		goarch = s
+	}
+	return goos + "_" + goarch
+}
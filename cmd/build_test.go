@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"runtime"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -16,6 +18,22 @@ func TestBuild(t *testing.T) {
 	require.NoError(t, cmd.cmd.Execute())
 }
 
+func TestBuildSingleTarget(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var cmd = newBuildCmd()
+	cmd.cmd.SetArgs([]string{"--snapshot", "--timeout=1m", "--parallelism=2", "--single-target"})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestBuildSingleTargetAndTargets(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var cmd = newBuildCmd()
+	cmd.cmd.SetArgs([]string{"--snapshot", "--single-target", "--targets=linux_amd64"})
+	require.EqualError(t, cmd.cmd.Execute(), "--single-target and --targets can't be used together")
+}
+
 func TestBuildInvalidConfig(t *testing.T) {
 	_, back := setup(t)
 	defer back()
@@ -52,9 +70,11 @@ func TestBuildFlags(t *testing.T) {
 		var ctx = setup(buildOpts{
 			skipValidate:  true,
 			skipPostHooks: true,
+			buildCache:    true,
 		})
 		require.True(t, ctx.SkipValidate)
 		require.True(t, ctx.SkipPostBuildHooks)
+		require.True(t, ctx.BuildCache)
 		require.True(t, ctx.SkipTokenCheck)
 	})
 
@@ -64,6 +84,34 @@ func TestBuildFlags(t *testing.T) {
 		}).Parallelism)
 	})
 
+	t.Run("single target", func(t *testing.T) {
+		var ctx = setup(buildOpts{
+			singleTarget: true,
+		})
+		require.True(t, ctx.SingleTarget)
+		require.Equal(t, []string{runtime.GOOS + "_" + runtime.GOARCH}, ctx.Targets)
+	})
+
+	t.Run("single target with GOOS and GOARCH", func(t *testing.T) {
+		require.NoError(t, os.Setenv("GOOS", "windows"))
+		require.NoError(t, os.Setenv("GOARCH", "arm64"))
+		defer os.Unsetenv("GOOS")
+		defer os.Unsetenv("GOARCH")
+		require.Equal(t, []string{"windows_arm64"}, setup(buildOpts{
+			singleTarget: true,
+		}).Targets)
+	})
+
+	t.Run("targets and ids", func(t *testing.T) {
+		var ctx = setup(buildOpts{
+			targets: []string{"linux_amd64", "darwin_arm64"},
+			ids:     []string{"cli"},
+		})
+		require.False(t, ctx.SingleTarget)
+		require.Equal(t, []string{"linux_amd64", "darwin_arm64"}, ctx.Targets)
+		require.Equal(t, []string{"cli"}, ctx.BuildIDs)
+	})
+
 	t.Run("rm dist", func(t *testing.T) {
 		require.True(t, setup(buildOpts{
 			rmDist: true,
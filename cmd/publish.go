@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/caarlos0/ctrlc"
+	"github.com/fatih/color"
+	"github.com/goreleaser/goreleaser/internal/middleware"
+	"github.com/goreleaser/goreleaser/internal/pipe/state"
+	"github.com/goreleaser/goreleaser/internal/pipeline"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/spf13/cobra"
+)
+
+type publishCmd struct {
+	cmd  *cobra.Command
+	opts publishOpts
+}
+
+type publishOpts struct {
+	dist        string
+	skipSign    bool
+	resume      bool
+	parallelism int
+	timeout     time.Duration
+}
+
+func newPublishCmd() *publishCmd {
+	var root = &publishCmd{}
+	var cmd = &cobra.Command{
+		Use:           "publish",
+		Aliases:       []string{"p"},
+		Short:         "Signs and publishes a release previously prepared with `release --prepare`",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+
+			log.Infof(color.New(color.Bold).Sprint("publishing..."))
+
+			if _, err := publishProject(root.opts); err != nil {
+				return wrapError(err, color.New(color.Bold).Sprintf("publish failed after %0.2fs", time.Since(start).Seconds()))
+			}
+
+			log.Infof(color.New(color.Bold).Sprintf("publish succeeded after %0.2fs", time.Since(start).Seconds()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&root.opts.dist, "dist", "dist", "Dist folder of the prepared release")
+	cmd.Flags().BoolVar(&root.opts.skipSign, "skip-sign", false, "Skips signing the artifacts")
+	cmd.Flags().BoolVar(&root.opts.resume, "resume", false, "Resumes a previously failed publish, skipping the publishers and uploads that already succeeded")
+	cmd.Flags().IntVarP(&root.opts.parallelism, "parallelism", "p", 4, "Amount tasks to run concurrently")
+	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire publish process")
+
+	root.cmd = cmd
+	return root
+}
+
+func publishProject(options publishOpts) (*context.Context, error) {
+	st, err := state.Load(options.dist)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.NewWithTimeout(st.Config, options.timeout)
+	defer cancel()
+	st.Restore(ctx)
+	setupPublishContext(ctx, options)
+	return ctx, ctrlc.Default.Run(ctx, func() error {
+		for _, pipe := range pipeline.PublishCmdPipeline {
+			if err := middleware.Logging(
+				pipe.String(),
+				middleware.ErrHandler(pipe.Run),
+				middleware.DefaultInitialPadding,
+			)(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func setupPublishContext(ctx *context.Context, options publishOpts) *context.Context {
+	ctx.Parallelism = options.parallelism
+	log.Debugf("parallelism: %v", ctx.Parallelism)
+	ctx.Config.Dist = options.dist
+	ctx.SkipSign = options.skipSign
+	ctx.Resume = options.resume
+	ctx.SkipPublish = ctx.Snapshot
+	return ctx
+}
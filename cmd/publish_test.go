@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	var release = newReleaseCmd()
+	release.cmd.SetArgs([]string{"--snapshot", "--prepare", "--timeout=1m", "--parallelism=2"})
+	require.NoError(t, release.cmd.Execute())
+	require.FileExists(t, filepath.Join(folder, "dist", "context.json"))
+
+	var cmd = newPublishCmd()
+	cmd.cmd.SetArgs([]string{"--timeout=1m", "--parallelism=2"})
+	require.NoError(t, cmd.cmd.Execute())
+}
+
+func TestPublishNotPrepared(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var cmd = newPublishCmd()
+	cmd.cmd.SetArgs([]string{"--timeout=1m", "--parallelism=2"})
+	require.Error(t, cmd.cmd.Execute())
+}
+
+func TestPublishFlags(t *testing.T) {
+	var setup = func(opts publishOpts) *context.Context {
+		return setupPublishContext(context.New(config.Project{}), opts)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		var ctx = setup(publishOpts{
+			dist:        "foo/dist",
+			parallelism: 1,
+		})
+		require.Equal(t, "foo/dist", ctx.Config.Dist)
+		require.Equal(t, 1, ctx.Parallelism)
+		require.False(t, ctx.SkipSign)
+		require.False(t, ctx.SkipPublish)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		var ctx = setup(publishOpts{
+			resume: true,
+		})
+		require.True(t, ctx.Resume)
+	})
+
+	t.Run("skip sign", func(t *testing.T) {
+		var ctx = setup(publishOpts{
+			skipSign: true,
+		})
+		require.True(t, ctx.SkipSign)
+	})
+}
@@ -26,7 +26,9 @@ type releaseOpts struct {
 	skipPublish   bool
 	skipSign      bool
 	skipValidate  bool
+	buildCache    bool
 	rmDist        bool
+	prepare       bool
 	deprecated    bool
 	parallelism   int
 	timeout       time.Duration
@@ -68,7 +70,9 @@ func newReleaseCmd() *releaseCmd {
 	cmd.Flags().BoolVar(&root.opts.skipPublish, "skip-publish", false, "Skips publishing artifacts")
 	cmd.Flags().BoolVar(&root.opts.skipSign, "skip-sign", false, "Skips signing the artifacts")
 	cmd.Flags().BoolVar(&root.opts.skipValidate, "skip-validate", false, "Skips several sanity checks")
+	cmd.Flags().BoolVar(&root.opts.buildCache, "build-cache", false, "Uses the cached binaries of the targets that didn't change instead of building them again")
 	cmd.Flags().BoolVar(&root.opts.rmDist, "rm-dist", false, "Remove the dist folder before building")
+	cmd.Flags().BoolVar(&root.opts.prepare, "prepare", false, "Build and package the release without signing nor publishing it, saving its state to dist so it can be published later with `goreleaser publish`")
 	cmd.Flags().IntVarP(&root.opts.parallelism, "parallelism", "p", 4, "Amount tasks to run concurrently")
 	cmd.Flags().DurationVar(&root.opts.timeout, "timeout", 30*time.Minute, "Timeout to the entire release process")
 	cmd.Flags().BoolVar(&root.opts.deprecated, "deprecated", false, "Force print the deprecation message - tests only")
@@ -86,8 +90,12 @@ func releaseProject(options releaseOpts) (*context.Context, error) {
 	ctx, cancel := context.NewWithTimeout(cfg, options.timeout)
 	defer cancel()
 	setupReleaseContext(ctx, options)
+	var pipes = pipeline.Pipeline
+	if options.prepare {
+		pipes = pipeline.PrepareCmdPipeline
+	}
 	return ctx, ctrlc.Default.Run(ctx, func() error {
-		for _, pipe := range pipeline.Pipeline {
+		for _, pipe := range pipes {
 			if err := middleware.Logging(
 				pipe.String(),
 				middleware.ErrHandler(pipe.Run),
@@ -110,7 +118,10 @@ func setupReleaseContext(ctx *context.Context, options releaseOpts) *context.Con
 	ctx.SkipPublish = ctx.Snapshot || options.skipPublish
 	ctx.SkipValidate = ctx.Snapshot || options.skipValidate
 	ctx.SkipSign = options.skipSign
+	ctx.BuildCache = options.buildCache
 	ctx.RmDist = options.rmDist
+	// tokens are only needed when publishing, which happens elsewhere
+	ctx.SkipTokenCheck = options.prepare
 
 	// test only
 	ctx.Deprecated = options.deprecated
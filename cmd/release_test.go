@@ -59,6 +59,14 @@ func TestReleaseFlags(t *testing.T) {
 		require.True(t, ctx.SkipPublish)
 	})
 
+	t.Run("prepare", func(t *testing.T) {
+		var ctx = setup(releaseOpts{
+			prepare: true,
+		})
+		require.True(t, ctx.SkipTokenCheck)
+		require.False(t, ctx.SkipPublish)
+	})
+
 	t.Run("parallelism", func(t *testing.T) {
 		require.Equal(t, 1, setup(releaseOpts{
 			parallelism: 1,
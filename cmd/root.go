@@ -72,6 +72,7 @@ func newRootCmd(version string, exit func(int)) *rootCmd {
 	cmd.AddCommand(
 		newBuildCmd().cmd,
 		newReleaseCmd().cmd,
+		newPublishCmd().cmd,
 		newCheckCmd().cmd,
 		newInitCmd().cmd,
 	)
@@ -9,6 +9,7 @@ require (
 	github.com/caarlos0/ctrlc v1.0.0
 	github.com/campoy/unique v0.0.0-20180121183637-88950e537e7e
 	github.com/client9/misspell v0.3.4
+	github.com/dsnet/compress v0.0.1
 	github.com/fatih/color v1.9.0
 	github.com/golangci/golangci-lint v1.31.0
 	github.com/google/go-github/v28 v28.1.1
@@ -16,6 +17,7 @@ require (
 	github.com/hashicorp/go-version v1.2.1 // indirect
 	github.com/imdario/mergo v0.3.11
 	github.com/jarcoal/httpmock v1.0.6
+	github.com/klauspost/compress v1.10.10
 	github.com/mattn/go-shellwords v1.0.10
 	github.com/mattn/go-zglob v0.0.3
 	github.com/mitchellh/go-homedir v1.1.0
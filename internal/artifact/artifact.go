@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"hash"
 	"hash/crc32"
@@ -46,6 +47,17 @@ const (
 	Signature
 	// UploadableSourceArchive is the archive with the current commit source code.
 	UploadableSourceArchive
+	// Published is something published by a publisher, e.g. an URL or an OCI
+	// artifact reference.
+	Published
+	// DockerManifest is a published Docker manifest list.
+	DockerManifest
+	// SBOM is a software bill of materials of a binary or archive.
+	SBOM
+	// Attestation is an in-toto attestation of the release artifacts.
+	Attestation
+	// WindowsInstaller is a windows installer, e.g. an msi.
+	WindowsInstaller
 )
 
 func (t Type) String() string {
@@ -68,6 +80,16 @@ func (t Type) String() string {
 		return "Signature"
 	case UploadableSourceArchive:
 		return "Source"
+	case Published:
+		return "Published"
+	case DockerManifest:
+		return "Docker Manifest"
+	case SBOM:
+		return "SBOM"
+	case Attestation:
+		return "Attestation"
+	case WindowsInstaller:
+		return "Windows Installer"
 	default:
 		return "unknown"
 	}
@@ -75,14 +97,14 @@ func (t Type) String() string {
 
 // Artifact represents an artifact and its relevant info.
 type Artifact struct {
-	Name   string
-	Path   string
-	Goos   string
-	Goarch string
-	Goarm  string
-	Gomips string
-	Type   Type
-	Extra  map[string]interface{}
+	Name   string                 `json:"name,omitempty"`
+	Path   string                 `json:"path,omitempty"`
+	Goos   string                 `json:"goos,omitempty"`
+	Goarch string                 `json:"goarch,omitempty"`
+	Goarm  string                 `json:"goarm,omitempty"`
+	Gomips string                 `json:"gomips,omitempty"`
+	Type   Type                   `json:"internal_type"`
+	Extra  map[string]interface{} `json:"extra,omitempty"`
 }
 
 // ExtraOr returns the Extra field with the given key or the or value specified
@@ -94,6 +116,53 @@ func (a Artifact) ExtraOr(key string, or interface{}) interface{} {
 	return a.Extra[key]
 }
 
+// SetExtra sets the Extra field with the given key to the given value,
+// initializing the Extra map if needed.
+func (a *Artifact) SetExtra(key string, value interface{}) {
+	if a.Extra == nil {
+		a.Extra = map[string]interface{}{}
+	}
+	a.Extra[key] = value
+}
+
+// MarshalJSON gives the JSON representation of the artifact, with its type
+// as a string besides the internal one.
+func (a Artifact) MarshalJSON() ([]byte, error) {
+	type alias Artifact
+	return json.Marshal(struct {
+		alias
+		TypeS string `json:"type"`
+	}{
+		alias: alias(a),
+		TypeS: a.Type.String(),
+	})
+}
+
+// UnmarshalJSON restores an artifact from its JSON representation, making
+// sure the Builds extra field is decoded back into a list of artifacts.
+func (a *Artifact) UnmarshalJSON(b []byte) error {
+	type alias Artifact
+	var aux alias
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*a = Artifact(aux)
+	builds, ok := a.Extra["Builds"]
+	if !ok {
+		return nil
+	}
+	bts, err := json.Marshal(builds)
+	if err != nil {
+		return err
+	}
+	var artifacts []*Artifact
+	if err := json.Unmarshal(bts, &artifacts); err != nil {
+		return fmt.Errorf("failed to decode builds of %s: %w", a.Name, err)
+	}
+	a.Extra["Builds"] = artifacts
+	return nil
+}
+
 // Checksum calculates the checksum of the artifact.
 // nolint: gosec
 func (a Artifact) Checksum(algorithm string) (string, error) {
@@ -170,6 +239,26 @@ func (artifacts *Artifacts) Add(a *Artifact) {
 	artifacts.items = append(artifacts.items, a)
 }
 
+// Remove safely removes the artifacts that match the given filter from an
+// artifact list.
+func (artifacts *Artifacts) Remove(filter Filter) {
+	artifacts.lock.Lock()
+	defer artifacts.lock.Unlock()
+	var result = make([]*Artifact, 0, len(artifacts.items))
+	for _, a := range artifacts.items {
+		if filter(a) {
+			log.WithFields(log.Fields{
+				"name": a.Name,
+				"path": a.Path,
+				"type": a.Type,
+			}).Debug("removed artifact")
+			continue
+		}
+		result = append(result, a)
+	}
+	artifacts.items = result
+}
+
 // Filter defines an artifact filter which can be used within the Filter
 // function.
 type Filter func(a *Artifact) bool
@@ -220,9 +309,10 @@ func ByIDs(ids ...string) Filter {
 	for _, id := range ids {
 		id := id
 		filters = append(filters, func(a *Artifact) bool {
-			// checksum and source archive are always for all artifacts, so return always true.
+			// checksum, source archive and attestation are always for all artifacts, so return always true.
 			return a.Type == Checksum ||
 				a.Type == UploadableSourceArchive ||
+				a.Type == Attestation ||
 				a.ExtraOr("ID", "") == id
 		})
 	}
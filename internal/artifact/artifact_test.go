@@ -1,6 +1,7 @@
 package artifact
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -44,6 +45,23 @@ func TestAdd(t *testing.T) {
 	require.Len(t, artifacts.List(), 4)
 }
 
+func TestRemove(t *testing.T) {
+	var artifacts = New()
+	for _, a := range []*Artifact{
+		{Name: "foo", Type: Binary, Goos: "darwin", Goarch: "amd64"},
+		{Name: "bar", Type: Binary, Goos: "darwin", Goarch: "arm64"},
+		{Name: "foobar", Type: Binary, Goos: "linux", Goarch: "amd64"},
+		{Name: "check", Type: Checksum},
+	} {
+		artifacts.Add(a)
+	}
+	artifacts.Remove(And(ByType(Binary), ByGoos("darwin")))
+	require.Equal(t, []*Artifact{
+		{Name: "foobar", Type: Binary, Goos: "linux", Goarch: "amd64"},
+		{Name: "check", Type: Checksum},
+	}, artifacts.List())
+}
+
 func TestFilter(t *testing.T) {
 	var data = []*Artifact{
 		{
@@ -241,15 +259,19 @@ func TestByIDs(t *testing.T) {
 			Name: "checksum",
 			Type: Checksum,
 		},
+		{
+			Name: "provenance",
+			Type: Attestation,
+		},
 	}
 	var artifacts = New()
 	for _, a := range data {
 		artifacts.Add(a)
 	}
 
-	require.Len(t, artifacts.Filter(ByIDs("check")).items, 2)
-	require.Len(t, artifacts.Filter(ByIDs("foo")).items, 3)
-	require.Len(t, artifacts.Filter(ByIDs("foo", "bar")).items, 4)
+	require.Len(t, artifacts.Filter(ByIDs("check")).items, 3)
+	require.Len(t, artifacts.Filter(ByIDs("foo")).items, 4)
+	require.Len(t, artifacts.Filter(ByIDs("foo", "bar")).items, 5)
 }
 
 func TestByFormats(t *testing.T) {
@@ -288,3 +310,47 @@ func TestByFormats(t *testing.T) {
 	require.Len(t, artifacts.Filter(ByFormats("zip")).items, 2)
 	require.Len(t, artifacts.Filter(ByFormats("zip", "tar.gz")).items, 3)
 }
+
+func TestJSONRoundTrip(t *testing.T) {
+	var bin = &Artifact{
+		Name:   "foo",
+		Path:   "dist/foo_linux_amd64/foo",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   Binary,
+		Extra: map[string]interface{}{
+			"ID":  "foo",
+			"Ext": "",
+		},
+	}
+	var archive = &Artifact{
+		Name:   "foo.tar.gz",
+		Path:   "dist/foo.tar.gz",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   UploadableArchive,
+		Extra: map[string]interface{}{
+			"ID":     "default",
+			"Builds": []*Artifact{bin},
+		},
+	}
+	bts, err := json.Marshal([]*Artifact{bin, archive})
+	require.NoError(t, err)
+	var result []*Artifact
+	require.NoError(t, json.Unmarshal(bts, &result))
+	require.Equal(t, []*Artifact{bin, archive}, result)
+}
+
+func TestJSONType(t *testing.T) {
+	bts, err := json.Marshal(Artifact{Name: "foo", Type: UploadableArchive})
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(bts, &result))
+	require.Equal(t, "Archive", result["type"])
+	require.Equal(t, float64(UploadableArchive), result["internal_type"])
+}
+
+func TestJSONInvalidBuilds(t *testing.T) {
+	var a Artifact
+	require.Error(t, json.Unmarshal([]byte(`{"name":"foo","extra":{"Builds":"nope"}}`), &a))
+}
@@ -41,6 +41,9 @@ func (*Builder) WithDefaults(build config.Build) config.Build {
 	if build.Main == "" {
 		build.Main = "."
 	}
+	if len(build.Ldflags) == 0 && build.BuildInfo {
+		build.Ldflags = []string{"-s -w"}
+	}
 	if len(build.Ldflags) == 0 {
 		build.Ldflags = []string{"-s -w -X main.version={{.Version}} -X main.commit={{.Commit}} -X main.date={{.Date}} -X main.builtBy=goreleaser"}
 	}
@@ -59,7 +62,7 @@ func (*Builder) WithDefaults(build config.Build) config.Build {
 	if build.GoBinary == "" {
 		build.GoBinary = "go"
 	}
-	return build
+	return windowsResourceDefaults(build)
 }
 
 // Build builds a golang build.
@@ -71,6 +74,12 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 	if err != nil {
 		return err
 	}
+	if build.BuildInfo {
+		build = withBuildInfo(build)
+	}
+	if ctx.Config.Reproducible.Enabled {
+		build = reproducible(ctx, build)
+	}
 
 	var cmd = []string{build.GoBinary, "build"}
 
@@ -92,6 +101,12 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 		},
 	}
 
+	cgo, err := cgoEnv(ctx, build, target, options.Target, artifact, env)
+	if err != nil {
+		return fmt.Errorf("failed to set up cgo for %s: %w", options.Target, err)
+	}
+	env = append(env, cgo...)
+
 	flags, err := processFlags(ctx, artifact, env, build.Flags, "")
 	if err != nil {
 		return err
@@ -120,9 +135,43 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 
 	cmd = append(cmd, processedLdFlags)
 
-	cmd = append(cmd, "-o", options.Path, build.Main)
-	if err := run(ctx, cmd, env, build.Dir); err != nil {
-		return fmt.Errorf("failed to build for %s: %w", options.Target, err)
+	var resources []string
+	if target.os == "windows" && hasWindowsResource(build) {
+		path, cleanup, err := writeWindowsResource(ctx, build, target, artifact, env)
+		if err != nil {
+			return fmt.Errorf("failed to create windows resource for %s: %w", options.Target, err)
+		}
+		defer cleanup()
+		resources = append(resources, path)
+	}
+
+	var key string
+	if ctx.BuildCache {
+		key, err = buildCacheKey(ctx, build, artifact, cmd, env, processedLdFlags, resources)
+		if err != nil {
+			log.WithError(err).Warn("failed to fingerprint build, not using the build cache")
+		}
+	}
+	var cached bool
+	if key != "" {
+		cached, err = fromCache(key, options.Path)
+		if err != nil {
+			log.WithError(err).Warn("failed to read from the build cache, building the binary")
+		}
+	}
+
+	if cached {
+		log.WithField("binary", options.Path).Info("binary didn't change, using the cached one")
+	} else {
+		cmd = append(cmd, "-o", options.Path, build.Main)
+		if err := run(ctx, cmd, env, build.Dir); err != nil {
+			return fmt.Errorf("failed to build for %s: %w", options.Target, err)
+		}
+		if key != "" {
+			if err := toCache(key, options.Path); err != nil {
+				log.WithError(err).Warn("failed to write to the build cache")
+			}
+		}
 	}
 
 	if build.ModTimestamp != "" {
@@ -145,6 +194,40 @@ func (*Builder) Build(ctx *context.Context, build config.Build, options api.Opti
 	return nil
 }
 
+func buildCacheKey(ctx *context.Context, build config.Build, a *artifact.Artifact, cmd, env []string, ldflags string, resources []string) (string, error) {
+	cmd, err := cacheCmd(ctx, build, a, cmd, env, ldflags)
+	if err != nil {
+		return "", err
+	}
+	return cacheKey(ctx, build, cmd, env, resources...)
+}
+
+// reproducible changes the build so its output doesn't depend on where and
+// when it was built: paths are trimmed, the build id is cleared and the binary
+// gets the commit date as its modification time.
+func reproducible(ctx *context.Context, build config.Build) config.Build {
+	// copy the flags so we don't change the ones of other targets
+	var flags = append(config.FlagArray{}, build.Flags...)
+	if !contains(flags, "-trimpath") {
+		flags = append(flags, "-trimpath")
+	}
+	build.Flags = flags
+	build.Ldflags = append(append(config.StringArray{}, build.Ldflags...), "-buildid=")
+	if build.ModTimestamp == "" && !ctx.Git.CommitDate.IsZero() {
+		build.ModTimestamp = "{{ .CommitTimestamp }}"
+	}
+	return build
+}
+
+func contains(ss []string, s string) bool {
+	for _, z := range ss {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
+
 func processFlags(ctx *context.Context, a *artifact.Artifact, env, flags []string, flagPrefix string) ([]string, error) {
 	processed := make([]string, 0, len(flags))
 	for _, rawFlag := range flags {
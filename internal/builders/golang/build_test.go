@@ -708,6 +708,66 @@ func TestBuildModTimestamp(t *testing.T) {
 	}
 }
 
+func TestBuildReproducible(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var commitDate = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	var ctx = context.New(config.Project{
+		Reproducible: config.Reproducible{Enabled: true},
+	})
+	ctx.Git.CurrentTag = "5.6.7"
+	ctx.Git.CommitDate = commitDate
+
+	// build the same code from two different folders, which would otherwise
+	// end up in the binaries
+	var checksums []string
+	for _, dir := range []string{"a", "b"} {
+		require.NoError(t, os.Mkdir(filepath.Join(folder, dir), 0755))
+		writeGoodMain(t, filepath.Join(folder, dir))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(folder, dir, "go.mod"), []byte("module foo\n"), 0644))
+		var path = filepath.Join(folder, "dist", dir, "foo")
+		require.NoError(t, Default.Build(ctx, config.Build{
+			ID:       "foo",
+			Dir:      dir,
+			Main:     ".",
+			Env:      []string{"GO111MODULE=on"},
+			Binary:   "foo",
+			Ldflags:  []string{"-s -w"},
+			GoBinary: "go",
+		}, api.Options{
+			Target: "linux_amd64",
+			Name:   "foo",
+			Path:   path,
+		}))
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		require.True(t, commitDate.Equal(fi.ModTime()))
+		sum, err := artifact.Artifact{Path: path}.Checksum("sha256")
+		require.NoError(t, err)
+		checksums = append(checksums, sum)
+	}
+	require.Equal(t, checksums[0], checksums[1])
+}
+
+func TestReproducibleFlags(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	var build = config.Build{
+		Flags:   []string{"-v", "-trimpath"},
+		Ldflags: []string{"-s -w"},
+	}
+	var result = reproducible(ctx, build)
+	require.Equal(t, config.FlagArray{"-v", "-trimpath"}, result.Flags)
+	require.Equal(t, config.StringArray{"-s -w", "-buildid="}, result.Ldflags)
+	require.Empty(t, result.ModTimestamp)
+	require.Equal(t, config.StringArray{"-s -w"}, build.Ldflags)
+
+	ctx.Git.CommitDate = time.Now()
+	result = reproducible(ctx, config.Build{})
+	require.Equal(t, config.FlagArray{"-trimpath"}, result.Flags)
+	require.Equal(t, config.StringArray{"-buildid="}, result.Ldflags)
+	require.Equal(t, "{{ .CommitTimestamp }}", result.ModTimestamp)
+}
+
 //
 // Helpers
 //
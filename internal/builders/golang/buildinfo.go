@@ -0,0 +1,28 @@
+package golang
+
+import (
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// buildInfoPackage is where the build info is set, see pkg/buildinfo.
+const buildInfoPackage = "github.com/goreleaser/goreleaser/pkg/buildinfo"
+
+// buildInfoLdflags are the ldflags setting the variables of the buildinfo
+// package.
+// nolint: gochecknoglobals
+var buildInfoLdflags = []string{
+	"-X " + buildInfoPackage + ".version={{ .Version }}",
+	"-X " + buildInfoPackage + ".commit={{ .FullCommit }}",
+	"-X " + buildInfoPackage + ".date={{ .Date }}",
+	"-X " + buildInfoPackage + ".builtBy=goreleaser",
+	"-X " + buildInfoPackage + ".major={{ .Major }}",
+	"-X " + buildInfoPackage + ".minor={{ .Minor }}",
+	"-X " + buildInfoPackage + ".patch={{ .Patch }}",
+	"-X " + buildInfoPackage + ".prerelease={{ .Prerelease }}",
+}
+
+// withBuildInfo adds the buildinfo ldflags to the ones of the build.
+func withBuildInfo(build config.Build) config.Build {
+	build.Ldflags = append(append(config.StringArray{}, build.Ldflags...), buildInfoLdflags...)
+	return build
+}
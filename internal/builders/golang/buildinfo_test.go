@@ -0,0 +1,51 @@
+package golang
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{BuildInfo: true})
+	require.Equal(t, config.StringArray{"-s -w"}, build.Ldflags)
+
+	build = Default.WithDefaults(config.Build{BuildInfo: true, Ldflags: []string{"-X main.foo=bar"}})
+	require.Equal(t, config.StringArray{"-X main.foo=bar"}, build.Ldflags)
+}
+
+func TestBuildWithBuildInfo(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("testdata", "buildinfo"))
+	require.NoError(t, err)
+	var ctx = context.New(config.Project{})
+	ctx.Git.CurrentTag = "v1.2.3-rc1"
+	ctx.Git.Commit = "5a1a6d3"
+	ctx.Git.FullCommit = "5a1a6d3"
+	ctx.Version = "1.2.3-rc1"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3, Prerelease: "rc1"}
+	ctx.Date = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var build = Default.WithDefaults(config.Build{
+		ID:        "foo",
+		Dir:       dir,
+		Binary:    "foo",
+		BuildInfo: true,
+		Targets:   []string{runtimeTarget},
+	})
+	var path = filepath.Join(t.TempDir(), "foo")
+	require.NoError(t, Default.Build(ctx, build, api.Options{
+		Target: runtimeTarget,
+		Name:   "foo",
+		Path:   path,
+	}))
+
+	out, err := exec.Command(path).CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.Equal(t, "1.2.3-rc1, commit 5a1a6d3, built at 2021-01-02T03:04:05Z by goreleaser\n1.2.3-rc1\n", string(out))
+}
@@ -0,0 +1,359 @@
+package golang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// cacheEnvPrefixes are the prefixes of the environment variables that change
+// the output of go build and are part of the cache key.
+// nolint: gochecknoglobals
+var cacheEnvPrefixes = []string{"GO", "CGO_", "CC=", "CXX=", "AR=", "PKG_CONFIG"}
+
+// cacheDir returns the directory the built binaries are cached in, which is
+// $GORELEASER_CACHE_DIR or the goreleaser folder of the user cache dir.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("GORELEASER_CACHE_DIR"); dir != "" {
+		return filepath.Join(dir, "build"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "goreleaser", "build"), nil
+}
+
+// cacheKey fingerprints a build of a target: the go build command, its
+// environment, the go version, the source trees of its module and of the
+// local modules it uses, go.mod and go.sum included, and the given files
+// generated for the target.
+//
+// The source trees are checked for every target, after its pre hooks ran, as
+// they may generate sources.
+func cacheKey(ctx *context.Context, build config.Build, cmd, env []string, files ...string) (string, error) {
+	var h = sha256.New()
+	fmt.Fprintf(h, "cmd %q\nmain %q\n", cmd, build.Main)
+	for _, file := range files {
+		bts, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %s %x\n", filepath.Base(file), sha256.Sum256(bts))
+	}
+
+	var vars []string
+	for _, e := range env {
+		for _, prefix := range cacheEnvPrefixes {
+			if strings.HasPrefix(e, prefix) {
+				vars = append(vars, e)
+				break
+			}
+		}
+	}
+	sort.Strings(vars)
+	fmt.Fprintf(h, "env %q\n", vars)
+
+	var memoPrefix = fmt.Sprintf("%s %s %q ", build.GoBinary, build.Dir, build.Env)
+	version, err := memoize(ctx, memoPrefix+"version", func() (string, error) {
+		return output(ctx, []string{build.GoBinary, "version"}, env, build.Dir)
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "version %s\n", version)
+
+	dirs, err := moduleDirs(ctx, build, env, memoPrefix)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range dirs {
+		tree, err := memoTreeHash(ctx, dir)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "tree %s\n", tree)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// module is a module as listed by go list -m -json.
+type module struct {
+	Main    bool
+	Dir     string
+	Version string
+	Replace *module
+}
+
+// moduleDirs gives the source directories the build depends on: the one of its
+// module, or its dir when not in module mode, and the ones of the local
+// modules it uses, through replace directives or a go.work workspace.
+func moduleDirs(ctx *context.Context, build config.Build, env []string, memoPrefix string) ([]string, error) {
+	gomod, err := memoize(ctx, memoPrefix+"gomod", func() (string, error) {
+		return output(ctx, []string{build.GoBinary, "env", "GOMOD"}, env, build.Dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var root = build.Dir
+	if root == "" {
+		root = "."
+	}
+	if gomod = strings.TrimSpace(gomod); gomod == "" || gomod == os.DevNull {
+		return uniqueDirs([]string{root})
+	}
+	var dirs = []string{filepath.Dir(gomod)}
+
+	list, err := memoize(ctx, memoPrefix+"modules", func() (string, error) {
+		return output(ctx, []string{build.GoBinary, "list", "-m", "-json", "all"}, env, build.Dir)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var dec = json.NewDecoder(strings.NewReader(list))
+	for dec.More() {
+		var m module
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("failed to read the modules of %s: %w", gomod, err)
+		}
+		switch {
+		case m.Main && m.Dir != "":
+			dirs = append(dirs, m.Dir)
+		case m.Replace != nil && m.Replace.Version == "" && m.Replace.Dir != "":
+			dirs = append(dirs, m.Replace.Dir)
+		}
+	}
+	return uniqueDirs(dirs)
+}
+
+// uniqueDirs gives the absolute paths of the given dirs, sorted and without
+// duplicates.
+func uniqueDirs(dirs []string) ([]string, error) {
+	var seen = map[string]bool{}
+	var result = make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		result = append(result, abs)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// memoTreeHash hashes the source tree in the given directory once per build
+// pipe run and state of the tree, given by the names, sizes, modes and
+// modification times of its files, so targets only read the whole tree again
+// if a pre hook changed it.
+func memoTreeHash(ctx *context.Context, root string) (string, error) {
+	state, err := walkTree(root, ctx.Config.Dist, func(rel string, info os.FileInfo, h io.Writer) error {
+		_, err := fmt.Fprintf(h, "%s %d %s %d\n", rel, info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return memoize(ctx, "tree "+root+" "+state, func() (string, error) {
+		return treeHash(root, ctx.Config.Dist)
+	})
+}
+
+// treeHash hashes the files in the given directory, except for the .git and
+// dist directories and the windows resources generated for each target.
+func treeHash(root, dist string) (string, error) {
+	return walkTree(root, dist, func(rel string, info os.FileInfo, h io.Writer) error {
+		fmt.Fprintf(h, "%s %s\n", rel, info.Mode())
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(h, f)
+		return err
+	})
+}
+
+// walkTree hashes what the given function writes for each file of the given
+// directory, except for the .git and dist directories and the windows
+// resources generated for each target.
+func walkTree(root, dist string, fn func(rel string, info os.FileInfo, h io.Writer) error) (string, error) {
+	if dist != "" {
+		abs, err := filepath.Abs(dist)
+		if err != nil {
+			return "", err
+		}
+		dist = abs
+	}
+	var h = sha256.New()
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if info.Name() == ".git" || abs == dist {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), sysoPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info, h)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash source tree: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fromCache copies the binary cached with the given key to the given path,
+// returning false if there is none.
+func fromCache(key, path string) (bool, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return false, err
+	}
+	var cached = filepath.Join(dir, key, filepath.Base(path))
+	if _, err := os.Stat(cached); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := api.CopyFile(cached, path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// toCache caches the binary in the given path with the given key.
+func toCache(key, path string) error {
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	dir = filepath.Join(dir, key)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// copy and rename so concurrent runs never see a partial binary
+	tmp, err := ioutil.TempFile(dir, "tmp")
+	if err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := api.CopyFile(path, tmp.Name()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, filepath.Base(path)))
+}
+
+// cacheCmd gives the go build command of the cache key, in which the ldflags
+// don't have the build date, which changes on every run: otherwise the
+// default ldflags would never hit the cache. Binaries taken from the cache
+// keep the date of the build that cached them.
+// Reproducible builds keep the date, as it is the commit date.
+func cacheCmd(ctx *context.Context, build config.Build, a *artifact.Artifact, cmd, env []string, ldflags string) ([]string, error) {
+	if ctx.Config.Reproducible.Enabled {
+		return cmd, nil
+	}
+	var undated = make([]string, 0, len(build.Ldflags))
+	for _, rawFlag := range build.Ldflags {
+		flag, err := tmpl.New(ctx).
+			WithEnvS(env).
+			WithArtifact(a, map[string]string{}).
+			WithExtraFields(tmpl.Fields{"Date": "", "Timestamp": 0}).
+			Apply(rawFlag)
+		if err != nil {
+			return nil, err
+		}
+		undated = append(undated, flag)
+	}
+	var result = make([]string, 0, len(cmd))
+	for _, arg := range cmd {
+		if arg == ldflags {
+			arg = joinLdFlags(undated)
+		}
+		result = append(result, arg)
+	}
+	return result, nil
+}
+
+// memos are the memoized values of the running builds, keyed by the context
+// of their build pipe run.
+// nolint: gochecknoglobals
+var memos sync.Map
+
+type memoValue struct {
+	once  sync.Once
+	value string
+	err   error
+}
+
+// memoize runs the given function once per key during the build pipe run of
+// the given context, as every target of a build shares the same go version
+// and module. The values are dropped when the run is done.
+func memoize(ctx *context.Context, key string, fn func() (string, error)) (string, error) {
+	var done = ctx.Done()
+	if done == nil {
+		// not in a build pipe run, which cancels its context when done
+		return fn()
+	}
+	var run = ctx.Context
+	v, loaded := memos.LoadOrStore(run, &sync.Map{})
+	if !loaded {
+		go func() {
+			<-done
+			memos.Delete(run)
+		}()
+	}
+	m, _ := v.(*sync.Map).LoadOrStore(key, &memoValue{})
+	var memo = m.(*memoValue)
+	memo.once.Do(func() {
+		memo.value, memo.err = fn()
+	})
+	return memo.value, memo.err
+}
+
+func output(ctx *context.Context, command, env []string, dir string) (string, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = env
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", string(out), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,327 @@
+package golang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// never use the user cache dir in the tests
+	dir, err := ioutil.TempDir("", "goreleaser-cache")
+	if err != nil {
+		fmt.Println("failed to create cache dir:", err)
+		os.Exit(1)
+	}
+	os.Setenv("GORELEASER_CACHE_DIR", dir)
+	var code = m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestCacheDir(t *testing.T) {
+	dir, err := cacheDir()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(os.Getenv("GORELEASER_CACHE_DIR"), "build"), dir)
+}
+
+func TestBuildCache(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var build = config.Build{
+		ID:       "foo",
+		Env:      []string{"GO111MODULE=off"},
+		Binary:   "foo",
+		Ldflags:  []string{"-s -w -X main.version={{.Version}} -X main.date={{.Date}}"},
+		GoBinary: "go",
+		Dir:      ".",
+		Main:     ".",
+	}
+	var path = filepath.Join(folder, "dist", runtimeTarget, "foo")
+	var buildWith = func(t *testing.T, ctx *context.Context) {
+		t.Helper()
+		require.NoError(t, os.RemoveAll(filepath.Join(folder, "dist")))
+		require.NoError(t, Default.Build(ctx, build, api.Options{
+			Target: runtimeTarget,
+			Name:   "foo",
+			Path:   path,
+		}))
+		require.FileExists(t, path)
+	}
+	var newCtx = func(version string) *context.Context {
+		var ctx = context.New(config.Project{
+			Dist:   "dist",
+			Builds: []config.Build{build},
+		})
+		ctx.Version = version
+		ctx.BuildCache = true
+		return ctx
+	}
+	// the cached binary is tampered with so cache hits can be told apart
+	// from actual builds
+	var tamper = func() {
+		t.Helper()
+		entries, err := filepath.Glob(filepath.Join(os.Getenv("GORELEASER_CACHE_DIR"), "build", "*", "foo"))
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		for _, entry := range entries {
+			require.NoError(t, ioutil.WriteFile(entry, []byte("cached"), 0755))
+		}
+	}
+	var content = func(t *testing.T) string {
+		t.Helper()
+		bts, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		return string(bts)
+	}
+
+	buildWith(t, newCtx("1.0.0"))
+	tamper()
+
+	t.Run("hit", func(t *testing.T) {
+		buildWith(t, newCtx("1.0.0"))
+		require.Equal(t, "cached", content(t))
+	})
+
+	t.Run("date changed", func(t *testing.T) {
+		var ctx = newCtx("1.0.0")
+		ctx.Date = ctx.Date.Add(time.Hour)
+		buildWith(t, ctx)
+		require.Equal(t, "cached", content(t))
+	})
+
+	t.Run("cache disabled", func(t *testing.T) {
+		var ctx = newCtx("1.0.0")
+		ctx.BuildCache = false
+		buildWith(t, ctx)
+		require.NotEqual(t, "cached", content(t))
+	})
+
+	t.Run("ldflags changed", func(t *testing.T) {
+		buildWith(t, newCtx("1.0.1"))
+		require.NotEqual(t, "cached", content(t))
+	})
+
+	t.Run("source changed", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(
+			filepath.Join(folder, "main.go"),
+			[]byte("package main\nvar a = 2\nfunc main() {println(1)}"),
+			0644,
+		))
+		buildWith(t, newCtx("1.0.0"))
+		require.NotEqual(t, "cached", content(t))
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var build = config.Build{
+		Env:      []string{"GO111MODULE=off"},
+		GoBinary: "go",
+		Dir:      ".",
+		Main:     ".",
+	}
+	var ctx = context.New(config.Project{Dist: "dist"})
+	var cmd = []string{"go", "build", "-ldflags=-s -w"}
+	var env = append(os.Environ(), "GOOS=linux", "GOARCH=amd64")
+
+	key, err := cacheKey(ctx, build, cmd, env)
+	require.NoError(t, err)
+	require.Len(t, key, 64)
+
+	t.Run("same", func(t *testing.T) {
+		k, err := cacheKey(ctx, build, cmd, env)
+		require.NoError(t, err)
+		require.Equal(t, key, k)
+	})
+
+	t.Run("unrelated env", func(t *testing.T) {
+		k, err := cacheKey(ctx, build, cmd, append(env, "FOO=bar"))
+		require.NoError(t, err)
+		require.Equal(t, key, k)
+	})
+
+	t.Run("dist is ignored", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(folder, "dist"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "dist", "foo"), []byte("foo"), 0644))
+		k, err := cacheKey(context.New(config.Project{Dist: "dist"}), build, cmd, env)
+		require.NoError(t, err)
+		require.Equal(t, key, k)
+	})
+
+	for name, args := range map[string]struct {
+		cmd []string
+		env []string
+	}{
+		"flags":  {cmd: []string{"go", "build", "-ldflags=-s"}, env: env},
+		"goos":   {cmd: cmd, env: append(env, "GOOS=darwin")},
+		"cgo":    {cmd: cmd, env: append(env, "CGO_ENABLED=1")},
+		"cc":     {cmd: cmd, env: append(env, "CC=clang")},
+		"goarch": {cmd: cmd, env: append(env, "GOARCH=arm64")},
+	} {
+		args := args
+		t.Run(name, func(t *testing.T) {
+			k, err := cacheKey(ctx, build, args.cmd, args.env)
+			require.NoError(t, err)
+			require.NotEqual(t, key, k)
+		})
+	}
+}
+
+func TestCacheKeyLocalReplacements(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.Mkdir(filepath.Join(folder, "app"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(folder, "lib"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "app", "go.mod"), []byte("module app\n\nrequire lib v0.0.0\n\nreplace lib => ../lib\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "app", "main.go"), []byte("package main\nimport _ \"lib\"\nfunc main() {}"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "lib", "go.mod"), []byte("module lib\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "lib", "lib.go"), []byte("package lib"), 0644))
+	var build = config.Build{
+		Env:      []string{"GO111MODULE=on", "GOWORK=off", "GOFLAGS=-mod=mod"},
+		GoBinary: "go",
+		Dir:      "app",
+		Main:     ".",
+	}
+	var ctx = context.New(config.Project{Dist: "dist"})
+	var cmd = []string{"go", "build"}
+	var env = append(os.Environ(), build.Env...)
+
+	key, err := cacheKey(ctx, build, cmd, env)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "lib", "lib.go"), []byte("package lib\nvar A = 1"), 0644))
+	changed, err := cacheKey(ctx, build, cmd, env)
+	require.NoError(t, err)
+	require.NotEqual(t, key, changed)
+}
+
+func TestFromCache(t *testing.T) {
+	var dir = t.TempDir()
+	var path = filepath.Join(dir, "dist", "foo")
+
+	cached, err := fromCache("nope", path)
+	require.NoError(t, err)
+	require.False(t, cached)
+
+	// a cached entry which can't be copied isn't a cache hit
+	cacheDir, err := cacheDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "broken", "foo"), 0755))
+	cached, err = fromCache("broken", path)
+	require.Error(t, err)
+	require.False(t, cached)
+	require.NoFileExists(t, path)
+}
+
+func TestCacheKeySourcesChangedDuringRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var build = config.Build{
+		Env:      []string{"GO111MODULE=off"},
+		GoBinary: "go",
+		Dir:      ".",
+		Main:     ".",
+	}
+	var ctx, cancel = context.NewWithTimeout(config.Project{Dist: "dist"}, time.Minute)
+	defer cancel()
+	var cmd = []string{"go", "build"}
+
+	key, err := cacheKey(ctx, build, cmd, os.Environ())
+	require.NoError(t, err)
+	// e.g. a pre hook running go generate
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "gen.go"), []byte("package main"), 0644))
+	changed, err := cacheKey(ctx, build, cmd, os.Environ())
+	require.NoError(t, err)
+	require.NotEqual(t, key, changed)
+}
+
+func TestCacheCmd(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.0.0"
+	var build = config.Build{Ldflags: []string{"-X main.version={{.Version}} -X main.date={{.Date}}"}}
+	var a = &artifact.Artifact{}
+	var ldflags = "-ldflags=-X main.version=1.0.0 -X main.date=" + ctx.Date.UTC().Format(time.RFC3339)
+	var cmd = []string{"go", "build", "-v", ldflags}
+
+	undated, err := cacheCmd(ctx, build, a, cmd, nil, ldflags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"go", "build", "-v", "-ldflags=-X main.version=1.0.0 -X main.date="}, undated)
+
+	ctx.Config.Reproducible.Enabled = true
+	reproducible, err := cacheCmd(ctx, build, a, cmd, nil, ldflags)
+	require.NoError(t, err)
+	require.Equal(t, cmd, reproducible)
+}
+
+func TestMemoize(t *testing.T) {
+	var calls int
+	var fn = func() (string, error) {
+		calls++
+		return "value", nil
+	}
+
+	t.Run("not in a run", func(t *testing.T) {
+		calls = 0
+		var ctx = context.New(config.Project{})
+		for i := 0; i < 2; i++ {
+			v, err := memoize(ctx, "key", fn)
+			require.NoError(t, err)
+			require.Equal(t, "value", v)
+		}
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("in a run", func(t *testing.T) {
+		calls = 0
+		var ctx, cancel = context.NewWithTimeout(config.Project{}, time.Minute)
+		for i := 0; i < 2; i++ {
+			v, err := memoize(ctx, "key", fn)
+			require.NoError(t, err)
+			require.Equal(t, "value", v)
+		}
+		require.Equal(t, 1, calls)
+		cancel()
+		require.Eventually(t, func() bool {
+			_, ok := memos.Load(ctx.Context)
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestTreeHash(t *testing.T) {
+	var folder = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "main.go"), []byte("package main"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(folder, ".git"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(folder, "dist"), 0755))
+
+	hash, err := treeHash(folder, filepath.Join(folder, "dist"))
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, ".git", "HEAD"), []byte("ref"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "dist", "foo"), []byte("foo"), 0644))
+	same, err := treeHash(folder, filepath.Join(folder, "dist"))
+	require.NoError(t, err)
+	require.Equal(t, hash, same)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "go.sum"), []byte("sum"), 0644))
+	changed, err := treeHash(folder, filepath.Join(folder, "dist"))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changed)
+
+	_, err = treeHash(filepath.Join(folder, "nope"), "")
+	require.Error(t, err)
+}
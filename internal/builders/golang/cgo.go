@@ -0,0 +1,171 @@
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// zigTargets maps goos_goarch to the target triples of zig cc.
+// nolint: gochecknoglobals
+var zigTargets = map[string]string{
+	"linux_amd64":    "x86_64-linux-gnu",
+	"linux_386":      "x86-linux-gnu",
+	"linux_arm64":    "aarch64-linux-gnu",
+	"linux_arm":      "arm-linux-gnueabihf",
+	"linux_ppc64le":  "powerpc64le-linux-gnu",
+	"linux_s390x":    "s390x-linux-gnu",
+	"linux_riscv64":  "riscv64-linux-gnu",
+	"linux_mips":     "mips-linux-gnueabihf",
+	"linux_mipsle":   "mipsel-linux-gnueabihf",
+	"linux_mips64":   "mips64-linux-gnuabi64",
+	"linux_mips64le": "mips64el-linux-gnuabi64",
+	"darwin_amd64":   "x86_64-macos",
+	"darwin_arm64":   "aarch64-macos",
+	"windows_amd64":  "x86_64-windows-gnu",
+	"windows_386":    "x86-windows-gnu",
+	"windows_arm64":  "aarch64-windows-gnu",
+}
+
+// crossTargets maps goos_goarch to the C and C++ compilers of the usual
+// cross toolchains: the gnu ones of debian and ubuntu, mingw-w64 and
+// osxcross.
+// nolint: gochecknoglobals
+var crossTargets = map[string][2]string{
+	"linux_amd64":    {"x86_64-linux-gnu-gcc", "x86_64-linux-gnu-g++"},
+	"linux_386":      {"i686-linux-gnu-gcc", "i686-linux-gnu-g++"},
+	"linux_arm64":    {"aarch64-linux-gnu-gcc", "aarch64-linux-gnu-g++"},
+	"linux_arm":      {"arm-linux-gnueabihf-gcc", "arm-linux-gnueabihf-g++"},
+	"linux_ppc64le":  {"powerpc64le-linux-gnu-gcc", "powerpc64le-linux-gnu-g++"},
+	"linux_s390x":    {"s390x-linux-gnu-gcc", "s390x-linux-gnu-g++"},
+	"linux_riscv64":  {"riscv64-linux-gnu-gcc", "riscv64-linux-gnu-g++"},
+	"linux_mips":     {"mips-linux-gnu-gcc", "mips-linux-gnu-g++"},
+	"linux_mipsle":   {"mipsel-linux-gnu-gcc", "mipsel-linux-gnu-g++"},
+	"linux_mips64":   {"mips64-linux-gnuabi64-gcc", "mips64-linux-gnuabi64-g++"},
+	"linux_mips64le": {"mips64el-linux-gnuabi64-gcc", "mips64el-linux-gnuabi64-g++"},
+	"darwin_amd64":   {"o64-clang", "o64-clang++"},
+	"darwin_arm64":   {"oa64-clang", "oa64-clang++"},
+	"windows_amd64":  {"x86_64-w64-mingw32-gcc", "x86_64-w64-mingw32-g++"},
+	"windows_386":    {"i686-w64-mingw32-gcc", "i686-w64-mingw32-g++"},
+}
+
+// cgoToolchain returns the default C toolchain of the given target for the
+// given toolchain name.
+func cgoToolchain(name string, t buildTarget) (config.CgoTarget, error) {
+	var key = t.os + "_" + t.arch
+	switch name {
+	case "":
+		return config.CgoTarget{}, nil
+	case "zig":
+		triple, ok := zigTargets[key]
+		if !ok {
+			return config.CgoTarget{}, fmt.Errorf("no default zig target for %s, set one in cgo.targets", key)
+		}
+		if t.arm == "5" {
+			triple = strings.TrimSuffix(triple, "hf")
+		}
+		if t.mips == "softfloat" {
+			triple = strings.Replace(triple, "gnueabihf", "gnueabi", 1)
+		}
+		return config.CgoTarget{
+			CC:  "zig cc -target " + triple,
+			CXX: "zig c++ -target " + triple,
+		}, nil
+	case "cross":
+		compilers, ok := crossTargets[key]
+		if !ok {
+			return config.CgoTarget{}, fmt.Errorf("no default cross toolchain for %s, set one in cgo.targets", key)
+		}
+		var cc, cxx = compilers[0], compilers[1]
+		if t.arm == "5" {
+			cc = strings.Replace(cc, "gnueabihf", "gnueabi", 1)
+			cxx = strings.Replace(cxx, "gnueabihf", "gnueabi", 1)
+		}
+		return config.CgoTarget{CC: cc, CXX: cxx}, nil
+	default:
+		return config.CgoTarget{}, fmt.Errorf("invalid cgo toolchain: %s: should be either zig or cross", name)
+	}
+}
+
+// cgoTarget merges the C toolchain configured for the given target, either
+// by its full name or by goos_goarch, on top of the toolchain defaults.
+func cgoTarget(cgo config.Cgo, t buildTarget, name string) (config.CgoTarget, bool, error) {
+	result, err := cgoToolchain(cgo.Toolchain, t)
+	if err != nil {
+		return result, false, err
+	}
+	custom, ok := cgo.Targets[name]
+	if !ok {
+		custom, ok = cgo.Targets[t.os+"_"+t.arch]
+	}
+	if !ok && cgo.Toolchain == "" {
+		return result, false, nil
+	}
+	if custom.CC != "" {
+		result.CC = custom.CC
+	}
+	if custom.CXX != "" {
+		result.CXX = custom.CXX
+	}
+	if custom.Sysroot != "" {
+		result.Sysroot = custom.Sysroot
+	}
+	if custom.Cflags != "" {
+		result.Cflags = custom.Cflags
+	}
+	if custom.Ldflags != "" {
+		result.Ldflags = custom.Ldflags
+	}
+	result.Env = append(result.Env, custom.Env...)
+	return result, true, nil
+}
+
+// cgoEnv returns the environment enabling cgo with the C toolchain of the
+// given target, or nothing if the build has no cgo toolchain for it.
+func cgoEnv(ctx *context.Context, build config.Build, t buildTarget, name string, a *artifact.Artifact, env []string) ([]string, error) {
+	cgo, ok, err := cgoTarget(build.Cgo, t, name)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var apply = func(s string) (string, error) {
+		return tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(s)
+	}
+	var flags = func(flags string) (string, error) {
+		if cgo.Sysroot != "" {
+			flags = strings.TrimSpace("--sysroot=" + cgo.Sysroot + " " + flags)
+		}
+		return apply(flags)
+	}
+
+	var result = []string{"CGO_ENABLED=1"}
+	for _, v := range []struct {
+		name, value string
+		apply       func(string) (string, error)
+	}{
+		{name: "CC", value: cgo.CC, apply: apply},
+		{name: "CXX", value: cgo.CXX, apply: apply},
+		{name: "CGO_CFLAGS", value: cgo.Cflags, apply: flags},
+		{name: "CGO_CXXFLAGS", value: cgo.Cflags, apply: flags},
+		{name: "CGO_LDFLAGS", value: cgo.Ldflags, apply: flags},
+	} {
+		value, err := v.apply(v.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to template %s: %w", v.name, err)
+		}
+		if value != "" {
+			result = append(result, v.name+"="+value)
+		}
+	}
+	for _, e := range cgo.Env {
+		value, err := apply(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to template cgo env: %w", err)
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package golang
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgoEnv(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cgo    config.Cgo
+		target string
+		env    []string
+	}{
+		"no cgo": {
+			target: "linux_amd64",
+		},
+		"other target": {
+			cgo: config.Cgo{
+				Targets: map[string]config.CgoTarget{
+					"linux_arm64": {CC: "gcc"},
+				},
+			},
+			target: "linux_amd64",
+		},
+		"zig": {
+			cgo:    config.Cgo{Toolchain: "zig"},
+			target: "linux_arm64",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=zig cc -target aarch64-linux-gnu",
+				"CXX=zig c++ -target aarch64-linux-gnu",
+			},
+		},
+		"zig armv5": {
+			cgo:    config.Cgo{Toolchain: "zig"},
+			target: "linux_arm_5",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=zig cc -target arm-linux-gnueabi",
+				"CXX=zig c++ -target arm-linux-gnueabi",
+			},
+		},
+		"zig mips softfloat": {
+			cgo:    config.Cgo{Toolchain: "zig"},
+			target: "linux_mips_softfloat",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=zig cc -target mips-linux-gnueabi",
+				"CXX=zig c++ -target mips-linux-gnueabi",
+			},
+		},
+		"zig darwin": {
+			cgo:    config.Cgo{Toolchain: "zig"},
+			target: "darwin_arm64",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=zig cc -target aarch64-macos",
+				"CXX=zig c++ -target aarch64-macos",
+			},
+		},
+		"cross windows": {
+			cgo:    config.Cgo{Toolchain: "cross"},
+			target: "windows_amd64",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=x86_64-w64-mingw32-gcc",
+				"CXX=x86_64-w64-mingw32-g++",
+			},
+		},
+		"cross armv7": {
+			cgo:    config.Cgo{Toolchain: "cross"},
+			target: "linux_arm_7",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=arm-linux-gnueabihf-gcc",
+				"CXX=arm-linux-gnueabihf-g++",
+			},
+		},
+		"override": {
+			cgo: config.Cgo{
+				Toolchain: "cross",
+				Targets: map[string]config.CgoTarget{
+					"linux_arm64": {
+						CC:      "{{ .Env.TOOLCHAIN }}/bin/aarch64-linux-musl-gcc",
+						Sysroot: "{{ .Env.TOOLCHAIN }}/sysroot",
+						Cflags:  "-O2",
+						Env:     []string{"PKG_CONFIG_PATH={{ .Env.TOOLCHAIN }}/lib/pkgconfig"},
+					},
+				},
+			},
+			target: "linux_arm64",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=/opt/musl/bin/aarch64-linux-musl-gcc",
+				"CXX=aarch64-linux-gnu-g++",
+				"CGO_CFLAGS=--sysroot=/opt/musl/sysroot -O2",
+				"CGO_CXXFLAGS=--sysroot=/opt/musl/sysroot -O2",
+				"CGO_LDFLAGS=--sysroot=/opt/musl/sysroot",
+				"PKG_CONFIG_PATH=/opt/musl/lib/pkgconfig",
+			},
+		},
+		"full target name first": {
+			cgo: config.Cgo{
+				Targets: map[string]config.CgoTarget{
+					"linux_arm":   {CC: "arm-gcc"},
+					"linux_arm_6": {CC: "armv6-gcc"},
+				},
+			},
+			target: "linux_arm_6",
+			env: []string{
+				"CGO_ENABLED=1",
+				"CC=armv6-gcc",
+			},
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			target, err := newBuildTarget(tt.target)
+			require.NoError(t, err)
+			env, err := cgoEnv(ctx, config.Build{Cgo: tt.cgo}, target, tt.target, &artifact.Artifact{}, []string{"TOOLCHAIN=/opt/musl"})
+			require.NoError(t, err)
+			require.Equal(t, tt.env, env)
+		})
+	}
+}
+
+func TestCgoEnvErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cgo    config.Cgo
+		target string
+		err    string
+	}{
+		"invalid toolchain": {
+			cgo:    config.Cgo{Toolchain: "tcc"},
+			target: "linux_amd64",
+			err:    "invalid cgo toolchain: tcc: should be either zig or cross",
+		},
+		"no zig target": {
+			cgo:    config.Cgo{Toolchain: "zig"},
+			target: "js_wasm",
+			err:    "no default zig target for js_wasm, set one in cgo.targets",
+		},
+		"no cross toolchain": {
+			cgo:    config.Cgo{Toolchain: "cross"},
+			target: "windows_arm64",
+			err:    "no default cross toolchain for windows_arm64, set one in cgo.targets",
+		},
+		"invalid template": {
+			cgo: config.Cgo{
+				Targets: map[string]config.CgoTarget{
+					"linux_amd64": {CC: "{{ .Foo }"},
+				},
+			},
+			target: "linux_amd64",
+			err:    `failed to template CC: template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			target, err := newBuildTarget(tt.target)
+			require.NoError(t, err)
+			_, err = cgoEnv(ctx, config.Build{Cgo: tt.cgo}, target, tt.target, &artifact.Artifact{}, nil)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestBuildCgo(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "main.go"),
+		[]byte("package main\n// int one() { return 1; }\nimport \"C\"\nfunc main() {println(C.one())}"),
+		0644,
+	))
+	var build = config.Build{
+		ID:       "foo",
+		Env:      []string{"GO111MODULE=off", "CGO_ENABLED=0"},
+		Binary:   "foo",
+		GoBinary: "go",
+		Cgo: config.Cgo{
+			Targets: map[string]config.CgoTarget{
+				runtimeTarget: {CC: "goreleaser-missing-cc"},
+			},
+		},
+	}
+	var ctx = context.New(config.Project{Builds: []config.Build{build}})
+	var err = Default.Build(ctx, build, api.Options{
+		Target: runtimeTarget,
+		Name:   "foo",
+		Path:   filepath.Join(folder, "dist", runtimeTarget, "foo"),
+	})
+	assertContainsError(t, err, "goreleaser-missing-cc")
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/goreleaser/goreleaser/pkg/buildinfo"
+)
+
+func main() {
+	var info = buildinfo.Get()
+	fmt.Println(info)
+	fmt.Println(info.Semver())
+}
@@ -0,0 +1,443 @@
+package golang
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// sysoPrefix is the prefix of the resource objects written to the main
+// package of windows builds, which the go linker links into the binaries.
+const sysoPrefix = "goreleaser_rsrc_"
+
+const (
+	rtIcon      = 3
+	rtGroupIcon = 14
+	rtVersion   = 16
+	rtManifest  = 24
+
+	// langEnUS is the language of all the resources.
+	langEnUS = 0x0409
+	// codePageUnicode is the code page of the version info strings.
+	codePageUnicode = 1200
+)
+
+// machines maps goarch to the COFF machine and ADDR32NB relocation type.
+// nolint: gochecknoglobals
+var machines = map[string][2]uint16{
+	"386":   {0x14c, 0x7},
+	"amd64": {0x8664, 0x3},
+	"arm":   {0x1c4, 0x2},
+	"arm64": {0xaa64, 0x2},
+}
+
+// nolint: gochecknoglobals
+var (
+	sysoLocks sync.Map
+	versionRe = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:\.(\d+))?`)
+)
+
+// hasWindowsResource tells whether the windows binaries of the given build
+// have a resource.
+func hasWindowsResource(build config.Build) bool {
+	return build.WindowsResource != config.WindowsResource{}
+}
+
+// windowsResourceDefaults sets the defaults of the version info of the
+// windows resource of the given build.
+func windowsResourceDefaults(build config.Build) config.Build {
+	if !hasWindowsResource(build) {
+		return build
+	}
+	var info = &build.WindowsResource.VersionInfo
+	if info.FileDescription == "" {
+		info.FileDescription = "{{ .ProjectName }}"
+	}
+	if info.FileVersion == "" {
+		info.FileVersion = "{{ .Version }}"
+	}
+	if info.InternalName == "" {
+		info.InternalName = "{{ .Binary }}"
+	}
+	if info.OriginalFilename == "" {
+		info.OriginalFilename = "{{ .ArtifactName }}"
+	}
+	if info.ProductName == "" {
+		info.ProductName = "{{ .ProjectName }}"
+	}
+	if info.ProductVersion == "" {
+		info.ProductVersion = "{{ .Version }}"
+	}
+	return build
+}
+
+// writeWindowsResource writes the windows resource of the build to its main
+// package, so it is linked into the binary of the given target. The returned
+// function removes it, and must be called once the binary is built.
+func writeWindowsResource(ctx *context.Context, build config.Build, t buildTarget, a *artifact.Artifact, env []string) (string, func(), error) {
+	var dir = filepath.Join(build.Dir, build.Main)
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		return "", nil, errors.New("main must be a package directory to link a windows resource")
+	}
+	bts, err := windowsResource(ctx, build.WindowsResource, t, a, env)
+	if err != nil {
+		return "", nil, err
+	}
+	var path = filepath.Join(dir, fmt.Sprintf("%swindows_%s.syso", sysoPrefix, t.arch))
+
+	// several builds may share the same main package
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, err
+	}
+	lock, _ := sysoLocks.LoadOrStore(abs, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+		lock.(*sync.Mutex).Unlock()
+		return "", nil, err
+	}
+	return path, func() {
+		_ = os.Remove(path)
+		lock.(*sync.Mutex).Unlock()
+	}, nil
+}
+
+// windowsResource returns a COFF object with the windows resource of the
+// given target.
+func windowsResource(ctx *context.Context, cfg config.WindowsResource, t buildTarget, a *artifact.Artifact, env []string) ([]byte, error) {
+	machine, ok := machines[t.arch]
+	if !ok {
+		return nil, fmt.Errorf("windows resources are not supported on %s", t.arch)
+	}
+	var apply = func(s string) (string, error) {
+		return tmpl.New(ctx).WithEnvS(env).WithArtifact(a, map[string]string{}).Apply(s)
+	}
+	var resources []resource
+
+	if cfg.Icon != "" {
+		path, err := apply(cfg.Icon)
+		if err != nil {
+			return nil, err
+		}
+		bts, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		icons, err := iconResources(bts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid icon %s: %w", path, err)
+		}
+		resources = append(resources, icons...)
+	}
+
+	if cfg.Manifest != "" {
+		path, err := apply(cfg.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		bts, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource{typ: rtManifest, id: 1, data: bts})
+	}
+
+	var info = cfg.VersionInfo
+	var values = make([][2]string, 0, 10)
+	for _, s := range []struct {
+		key, value string
+	}{
+		{"Comments", info.Comments},
+		{"CompanyName", info.CompanyName},
+		{"FileDescription", info.FileDescription},
+		{"FileVersion", info.FileVersion},
+		{"InternalName", info.InternalName},
+		{"LegalCopyright", info.LegalCopyright},
+		{"LegalTrademarks", info.LegalTrademarks},
+		{"OriginalFilename", info.OriginalFilename},
+		{"ProductName", info.ProductName},
+		{"ProductVersion", info.ProductVersion},
+	} {
+		value, err := apply(s.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to template %s: %w", s.key, err)
+		}
+		if value != "" {
+			values = append(values, [2]string{s.key, value})
+		}
+	}
+	if len(values) > 0 {
+		resources = append(resources, resource{typ: rtVersion, id: 1, data: versionInfo(values)})
+	}
+
+	section, relocations := resourceSection(resources)
+	return coff(machine[0], machine[1], section, relocations), nil
+}
+
+type resource struct {
+	typ, id uint32
+	data    []byte
+}
+
+// iconResources returns the RT_ICON resources of each image of the given .ico
+// file, and their RT_GROUP_ICON.
+func iconResources(ico []byte) ([]resource, error) {
+	const headerSize, entrySize = 6, 16
+	var le = binary.LittleEndian
+	if len(ico) < headerSize || le.Uint16(ico[0:]) != 0 || le.Uint16(ico[2:]) != 1 {
+		return nil, errors.New("not an .ico file")
+	}
+	var count = int(le.Uint16(ico[4:]))
+	if count == 0 || len(ico) < headerSize+entrySize*count {
+		return nil, errors.New("no images")
+	}
+	var resources = make([]resource, 0, count+1)
+	var group = append([]byte{}, ico[:headerSize]...)
+	for i := 0; i < count; i++ {
+		var entry = ico[headerSize+entrySize*i : headerSize+entrySize*(i+1)]
+		var size, offset = le.Uint32(entry[8:]), le.Uint32(entry[12:])
+		if uint64(offset)+uint64(size) > uint64(len(ico)) {
+			return nil, fmt.Errorf("image %d is out of bounds", i)
+		}
+		var id = uint32(i + 1)
+		resources = append(resources, resource{typ: rtIcon, id: id, data: ico[offset : offset+size]})
+		// the group entries are the ico entries with the image id in place
+		// of its offset
+		group = append(group, entry[:12]...)
+		group = append(group, byte(id), byte(id>>8))
+	}
+	return append(resources, resource{typ: rtGroupIcon, id: 1, data: group}), nil
+}
+
+// versionInfo returns the VS_VERSIONINFO with the given strings. Its fixed
+// file and product versions are parsed from the FileVersion and
+// ProductVersion strings.
+func versionInfo(values [][2]string) []byte {
+	var fileVersion, productVersion [4]uint16
+	var children = make([]versionBlock, 0, len(values))
+	for _, s := range values {
+		switch s[0] {
+		case "FileVersion":
+			fileVersion = parseVersion(s[1])
+		case "ProductVersion":
+			productVersion = parseVersion(s[1])
+		}
+		children = append(children, versionBlock{key: s[0], text: true, value: utf16z(s[1])})
+	}
+
+	var fixed = new(bytes.Buffer)
+	_ = binary.Write(fixed, binary.LittleEndian, []uint32{
+		0xfeef04bd, // signature
+		0x00010000, // struct version
+		uint32(fileVersion[0])<<16 | uint32(fileVersion[1]),
+		uint32(fileVersion[2])<<16 | uint32(fileVersion[3]),
+		uint32(productVersion[0])<<16 | uint32(productVersion[1]),
+		uint32(productVersion[2])<<16 | uint32(productVersion[3]),
+		0x3f,    // file flags mask
+		0,       // file flags
+		0x40004, // VOS_NT_WINDOWS32
+		1,       // VFT_APP
+		0,       // file subtype
+		0, 0,    // file date
+	})
+
+	var translation = make([]byte, 4)
+	binary.LittleEndian.PutUint16(translation[0:], langEnUS)
+	binary.LittleEndian.PutUint16(translation[2:], codePageUnicode)
+	return versionBlock{
+		key:   "VS_VERSION_INFO",
+		value: fixed.Bytes(),
+		children: []versionBlock{
+			{
+				key:  "StringFileInfo",
+				text: true,
+				children: []versionBlock{{
+					key:      fmt.Sprintf("%04x%04x", langEnUS, codePageUnicode),
+					text:     true,
+					children: children,
+				}},
+			},
+			{
+				key:  "VarFileInfo",
+				text: true,
+				children: []versionBlock{{
+					key:   "Translation",
+					value: translation,
+				}},
+			},
+		},
+	}.encode()
+}
+
+func parseVersion(s string) [4]uint16 {
+	var result [4]uint16
+	var match = versionRe.FindStringSubmatch(s)
+	for i := 1; i < len(match); i++ {
+		n, _ := strconv.ParseUint(match[i], 10, 16)
+		result[i-1] = uint16(n)
+	}
+	return result
+}
+
+// versionBlock is a block of the version info: a key and a value followed by
+// children blocks.
+type versionBlock struct {
+	key      string
+	text     bool
+	value    []byte
+	children []versionBlock
+}
+
+func (b versionBlock) encode() []byte {
+	var buf = make([]byte, 6, 64)
+	buf = append(buf, utf16z(b.key)...)
+	buf = pad(buf, 4)
+	buf = append(buf, b.value...)
+	for _, child := range b.children {
+		buf = pad(buf, 4)
+		buf = append(buf, child.encode()...)
+	}
+	var valueLength = len(b.value)
+	var typ uint16
+	if b.text {
+		valueLength /= 2
+		typ = 1
+	}
+	binary.LittleEndian.PutUint16(buf[0:], uint16(len(buf)))
+	binary.LittleEndian.PutUint16(buf[2:], uint16(valueLength))
+	binary.LittleEndian.PutUint16(buf[4:], typ)
+	return buf
+}
+
+// utf16z encodes the given string as null terminated UTF-16LE.
+func utf16z(s string) []byte {
+	var chars = append(utf16.Encode([]rune(s)), 0)
+	var result = make([]byte, 2*len(chars))
+	for i, c := range chars {
+		binary.LittleEndian.PutUint16(result[2*i:], c)
+	}
+	return result
+}
+
+func pad(buf []byte, alignment int) []byte {
+	for len(buf)%alignment != 0 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// resourceSection lays out the given resources as a .rsrc section: a tree of
+// directories by type, id and language, whose leaves point to the data of
+// the resources. It also returns the offsets of those pointers, which are
+// relative to the section and need relocations.
+func resourceSection(resources []resource) ([]byte, []uint32) {
+	const dirSize, entrySize, dataEntrySize = 16, 8, 16
+	sort.SliceStable(resources, func(i, j int) bool {
+		if resources[i].typ != resources[j].typ {
+			return resources[i].typ < resources[j].typ
+		}
+		return resources[i].id < resources[j].id
+	})
+	var types []uint32
+	var counts = map[uint32]int{}
+	for _, r := range resources {
+		if counts[r.typ] == 0 {
+			types = append(types, r.typ)
+		}
+		counts[r.typ]++
+	}
+
+	var offset = uint32(dirSize + entrySize*len(types))
+	var typeDirs = map[uint32]uint32{}
+	for _, typ := range types {
+		typeDirs[typ] = offset
+		offset += uint32(dirSize + entrySize*counts[typ])
+	}
+	var langDirs = make([]uint32, len(resources))
+	for i := range resources {
+		langDirs[i] = offset
+		offset += dirSize + entrySize
+	}
+	var dataEntries = make([]uint32, len(resources))
+	for i := range resources {
+		dataEntries[i] = offset
+		offset += dataEntrySize
+	}
+	var data = make([]uint32, len(resources))
+	for i, r := range resources {
+		offset = (offset + 7) &^ 7
+		data[i] = offset
+		offset += uint32(len(r.data))
+	}
+
+	var buf = make([]byte, offset)
+	var le = binary.LittleEndian
+	var dir = func(at uint32, entries int) {
+		le.PutUint16(buf[at+14:], uint16(entries))
+	}
+	var entry = func(at, id, to uint32) {
+		le.PutUint32(buf[at:], id)
+		le.PutUint32(buf[at+4:], to)
+	}
+	const subdir = 0x80000000
+	dir(0, len(types))
+	var i int
+	for t, typ := range types {
+		entry(uint32(dirSize+entrySize*t), typ, subdir|typeDirs[typ])
+		dir(typeDirs[typ], counts[typ])
+		for n := 0; n < counts[typ]; n++ {
+			entry(typeDirs[typ]+uint32(dirSize+entrySize*n), resources[i].id, subdir|langDirs[i])
+			dir(langDirs[i], 1)
+			entry(langDirs[i]+dirSize, langEnUS, dataEntries[i])
+			le.PutUint32(buf[dataEntries[i]:], data[i])
+			le.PutUint32(buf[dataEntries[i]+4:], uint32(len(resources[i].data)))
+			copy(buf[data[i]:], resources[i].data)
+			i++
+		}
+	}
+	return buf, dataEntries
+}
+
+// coff returns a COFF object with the given .rsrc section, its relocations
+// and the section symbol they refer to.
+func coff(machine, relocation uint16, section []byte, relocations []uint32) []byte {
+	const fileHeaderSize, sectionHeaderSize, relocationSize = 20, 40, 10
+	var name = [8]byte{'.', 'r', 's', 'r', 'c'}
+	var dataOffset = uint32(fileHeaderSize + sectionHeaderSize)
+	var relocationsOffset = dataOffset + uint32(len(section))
+	var symbolsOffset = relocationsOffset + uint32(relocationSize*len(relocations))
+
+	var buf = new(bytes.Buffer)
+	var write = func(v ...interface{}) {
+		for _, v := range v {
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+	// file header
+	write(machine, uint16(1), uint32(0), symbolsOffset, uint32(1), uint16(0), uint16(0))
+	// section header: initialized, readable data
+	write(name, uint32(0), uint32(0), uint32(len(section)), dataOffset, relocationsOffset,
+		uint32(0), uint16(len(relocations)), uint16(0), uint32(0x40000040))
+	buf.Write(section)
+	for _, offset := range relocations {
+		write(offset, uint32(0), relocation)
+	}
+	// the static symbol of the section, and an empty string table
+	write(name, uint32(0), int16(1), uint16(0), uint8(3), uint8(0))
+	write(uint32(4))
+	return buf.Bytes()
+}
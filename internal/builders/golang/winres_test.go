@@ -0,0 +1,304 @@
+package golang
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowsResourceDefaults(t *testing.T) {
+	require.Equal(t, config.WindowsVersionInfo{}, Default.WithDefaults(config.Build{}).WindowsResource.VersionInfo)
+
+	var build = Default.WithDefaults(config.Build{
+		WindowsResource: config.WindowsResource{
+			VersionInfo: config.WindowsVersionInfo{
+				CompanyName: "Acme",
+				ProductName: "Foo",
+			},
+		},
+	})
+	require.Equal(t, config.WindowsVersionInfo{
+		CompanyName:      "Acme",
+		FileDescription:  "{{ .ProjectName }}",
+		FileVersion:      "{{ .Version }}",
+		InternalName:     "{{ .Binary }}",
+		OriginalFilename: "{{ .ArtifactName }}",
+		ProductName:      "Foo",
+		ProductVersion:   "{{ .Version }}",
+	}, build.WindowsResource.VersionInfo)
+}
+
+func TestParseVersion(t *testing.T) {
+	for version, expected := range map[string][4]uint16{
+		"1.2.3":          {1, 2, 3, 0},
+		"v1.2.3-rc1":     {1, 2, 3, 0},
+		"1.2.3.4":        {1, 2, 3, 4},
+		"2":              {2, 0, 0, 0},
+		"1.2.3-SNAPSHOT": {1, 2, 3, 0},
+		"SNAPSHOT-abc":   {0, 0, 0, 0},
+	} {
+		require.Equal(t, expected, parseVersion(version), version)
+	}
+}
+
+func TestIconResources(t *testing.T) {
+	resources, err := iconResources(testIcon())
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+	require.Equal(t, resource{typ: rtIcon, id: 1, data: []byte("first")}, resources[0])
+	require.Equal(t, resource{typ: rtIcon, id: 2, data: []byte("second!")}, resources[1])
+	require.Equal(t, uint32(rtGroupIcon), resources[2].typ)
+	require.Equal(t, []byte{
+		0, 0, 1, 0, 2, 0,
+		16, 16, 0, 0, 1, 0, 32, 0, 5, 0, 0, 0, 1, 0,
+		32, 32, 0, 0, 1, 0, 32, 0, 7, 0, 0, 0, 2, 0,
+	}, resources[2].data)
+
+	for name, ico := range map[string][]byte{
+		"not an .ico file": []byte("PNG"),
+		"no images":        {0, 0, 1, 0, 0, 0},
+		"image 0 is out of bounds": append([]byte{
+			0, 0, 1, 0, 1, 0,
+		}, testIconEntry(16, 100, 22)...),
+	} {
+		_, err := iconResources(ico)
+		require.EqualError(t, err, name)
+	}
+}
+
+func TestBuildWindowsResource(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "icon.ico"), testIcon(), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "app.manifest"), []byte("<assembly/>"), 0644))
+
+	var build = Default.WithDefaults(config.Build{
+		ID:       "foo",
+		Env:      []string{"GO111MODULE=off"},
+		Binary:   "foo",
+		Targets:  []string{"windows_amd64", "windows_386"},
+		GoBinary: "go",
+		WindowsResource: config.WindowsResource{
+			Icon:     "icon.ico",
+			Manifest: "{{ .Env.MANIFEST }}",
+			VersionInfo: config.WindowsVersionInfo{
+				CompanyName:    "Acme",
+				LegalCopyright: "© {{ .ProjectName }}",
+			},
+		},
+	})
+	var ctx = context.New(config.Project{ProjectName: "proj", Builds: []config.Build{build}})
+	ctx.Env["MANIFEST"] = "app.manifest"
+	ctx.Version = "1.2.3"
+
+	for _, target := range build.Targets {
+		var path = filepath.Join(folder, "dist", target, "foo.exe")
+		require.NoError(t, Default.Build(ctx, build, api.Options{
+			Target: target,
+			Name:   "foo.exe",
+			Path:   path,
+			Ext:    ".exe",
+		}))
+
+		var resources = readResources(t, path)
+		require.Equal(t, []byte("first"), resources[rtIcon][1])
+		require.Equal(t, []byte("second!"), resources[rtIcon][2])
+		require.Len(t, resources[rtGroupIcon][1], 34)
+		require.Equal(t, []byte("<assembly/>"), resources[rtManifest][1])
+
+		var info = resources[rtVersion][1]
+		require.Equal(t, uint32(0xfeef04bd), binary.LittleEndian.Uint32(info[40:]))
+		require.Equal(t, uint32(1<<16|2), binary.LittleEndian.Uint32(info[48:]))
+		require.Equal(t, uint32(3<<16), binary.LittleEndian.Uint32(info[52:]))
+		for key, value := range map[string]string{
+			"CompanyName":      "Acme",
+			"FileDescription":  "proj",
+			"FileVersion":      "1.2.3",
+			"InternalName":     "foo",
+			"LegalCopyright":   "© proj",
+			"OriginalFilename": "foo.exe",
+			"ProductName":      "proj",
+			"ProductVersion":   "1.2.3",
+		} {
+			require.True(t, bytes.Contains(info, append(utf16z(key), utf16z(value)...)) ||
+				bytes.Contains(info, append(append(utf16z(key), 0, 0), utf16z(value)...)), key)
+		}
+	}
+
+	// the resource objects are removed once built
+	sysos, err := filepath.Glob(filepath.Join(folder, "*.syso"))
+	require.NoError(t, err)
+	require.Empty(t, sysos)
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List(), 2)
+}
+
+func TestBuildWindowsResourceErrors(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "icon.ico"), []byte("nope"), 0644))
+
+	for name, tt := range map[string]struct {
+		main     string
+		target   string
+		resource config.WindowsResource
+		err      string
+	}{
+		"main file": {
+			main:     "main.go",
+			target:   "windows_amd64",
+			resource: config.WindowsResource{Icon: "icon.ico"},
+			err:      "failed to create windows resource for windows_amd64: main must be a package directory to link a windows resource",
+		},
+		"unsupported arch": {
+			target:   "windows_mips",
+			resource: config.WindowsResource{Icon: "icon.ico"},
+			err:      "failed to create windows resource for windows_mips: windows resources are not supported on mips",
+		},
+		"invalid icon": {
+			target:   "windows_amd64",
+			resource: config.WindowsResource{Icon: "icon.ico"},
+			err:      "failed to create windows resource for windows_amd64: invalid icon icon.ico: not an .ico file",
+		},
+		"missing manifest": {
+			target:   "windows_amd64",
+			resource: config.WindowsResource{Manifest: "nope.manifest"},
+			err:      "failed to create windows resource for windows_amd64: open nope.manifest: no such file or directory",
+		},
+		"invalid template": {
+			target: "windows_amd64",
+			resource: config.WindowsResource{
+				VersionInfo: config.WindowsVersionInfo{CompanyName: "{{ .Foo }"},
+			},
+			err: `failed to create windows resource for windows_amd64: failed to template CompanyName: template: tmpl:1: unexpected "}" in operand`,
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var build = Default.WithDefaults(config.Build{
+				ID:              "foo",
+				Main:            tt.main,
+				Binary:          "foo",
+				GoBinary:        "go",
+				WindowsResource: tt.resource,
+			})
+			var ctx = context.New(config.Project{Builds: []config.Build{build}})
+			var err = Default.Build(ctx, build, api.Options{
+				Target: tt.target,
+				Name:   "foo.exe",
+				Path:   filepath.Join(folder, "dist", tt.target, "foo.exe"),
+				Ext:    ".exe",
+			})
+			require.EqualError(t, err, tt.err)
+		})
+	}
+
+	// non windows targets don't get a resource
+	var build = Default.WithDefaults(config.Build{
+		ID:              "foo",
+		Env:             []string{"GO111MODULE=off"},
+		Binary:          "foo",
+		GoBinary:        "go",
+		WindowsResource: config.WindowsResource{Icon: "icon.ico"},
+	})
+	var ctx = context.New(config.Project{Builds: []config.Build{build}})
+	require.NoError(t, Default.Build(ctx, build, api.Options{
+		Target: runtimeTarget,
+		Name:   "foo",
+		Path:   filepath.Join(folder, "dist", runtimeTarget, "foo"),
+	}))
+}
+
+// testIcon returns an .ico with two fake images.
+func testIcon() []byte {
+	var ico = []byte{0, 0, 1, 0, 2, 0}
+	ico = append(ico, testIconEntry(16, 5, 38)...)
+	ico = append(ico, testIconEntry(32, 7, 43)...)
+	return append(ico, []byte("firstsecond!")...)
+}
+
+func testIconEntry(size byte, length, offset uint32) []byte {
+	var entry = []byte{size, size, 0, 0, 1, 0, 32, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(entry[8:], length)
+	binary.LittleEndian.PutUint32(entry[12:], offset)
+	return entry
+}
+
+// readResources reads the resources of the given executable by type and id.
+func readResources(t *testing.T, path string) map[uint32]map[uint32][]byte {
+	t.Helper()
+	f, err := pe.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var section = f.Section(".rsrc")
+	require.NotNil(t, section)
+	data, err := section.Data()
+	require.NoError(t, err)
+
+	var le = binary.LittleEndian
+	var entries = func(dir uint32) [][2]uint32 {
+		var count = uint32(le.Uint16(data[dir+12:]) + le.Uint16(data[dir+14:]))
+		var result [][2]uint32
+		for i := uint32(0); i < count; i++ {
+			var at = dir + 16 + 8*i
+			result = append(result, [2]uint32{le.Uint32(data[at:]), le.Uint32(data[at+4:]) &^ 0x80000000})
+		}
+		return result
+	}
+	var result = map[uint32]map[uint32][]byte{}
+	for _, typ := range entries(0) {
+		result[typ[0]] = map[uint32][]byte{}
+		for _, id := range entries(typ[1]) {
+			var langs = entries(id[1])
+			require.Len(t, langs, 1)
+			var entry = langs[0][1]
+			var offset = le.Uint32(data[entry:]) - section.VirtualAddress
+			var size = le.Uint32(data[entry+4:])
+			result[typ[0]][id[0]] = data[offset : offset+size]
+		}
+	}
+	return result
+}
+
+func TestUTF16Z(t *testing.T) {
+	var bts = utf16z("a©")
+	require.Len(t, bts, 6)
+	var chars = make([]uint16, 3)
+	require.NoError(t, binary.Read(bytes.NewReader(bts), binary.LittleEndian, chars))
+	require.Equal(t, "a©\x00", string(utf16.Decode(chars)))
+}
+
+func TestRemoveSysoOnFailure(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "main.go"), []byte("package main\nfunc main() {nope()}"), 0644))
+	var build = Default.WithDefaults(config.Build{
+		ID:              "foo",
+		Env:             []string{"GO111MODULE=off"},
+		Binary:          "foo",
+		GoBinary:        "go",
+		WindowsResource: config.WindowsResource{VersionInfo: config.WindowsVersionInfo{CompanyName: "Acme"}},
+	})
+	var ctx = context.New(config.Project{Builds: []config.Build{build}})
+	require.Error(t, Default.Build(ctx, build, api.Options{
+		Target: "windows_amd64",
+		Name:   "foo.exe",
+		Path:   filepath.Join(folder, "dist", "windows_amd64", "foo.exe"),
+		Ext:    ".exe",
+	}))
+	_, err := os.Stat(filepath.Join(folder, sysoPrefix+"windows_amd64.syso"))
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,86 @@
+// Package plugin provides a Builder implementation that delegates the build
+// of each target to an executable.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/mattn/go-shellwords"
+)
+
+// Default builder instance.
+// nolint: gochecknoglobals
+var Default = &Builder{}
+
+// nolint: gochecknoinits
+func init() {
+	api.Register("plugin", Default)
+}
+
+// Builder is the plugin builder.
+type Builder struct{}
+
+// WithDefaults sets the defaults for a plugin build and returns it.
+func (*Builder) WithDefaults(build config.Build) config.Build {
+	if build.Dir == "" {
+		build.Dir = "."
+	}
+	return build
+}
+
+// Build runs the plugin command of the build, which gets the build options
+// of the target as JSON in its standard input, and must write the binary to
+// their path.
+func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	if build.Plugin.Command == "" {
+		return errors.New("plugin builds need a command")
+	}
+	var env = append(ctx.Env.Strings(), build.Env...)
+	sh, err := tmpl.New(ctx).WithBuildOptions(options).WithEnvS(env).Apply(build.Plugin.Command)
+	if err != nil {
+		return err
+	}
+	command, err := shellwords.Parse(sh)
+	if err != nil {
+		return err
+	}
+	if len(command) == 0 {
+		return errors.New("plugin builds need a command")
+	}
+	bts, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
+		return err
+	}
+
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	var log = log.WithField("cmd", command)
+	cmd.Env = env
+	cmd.Dir = build.Dir
+	cmd.Stdin = bytes.NewReader(bts)
+	log.WithField("options", string(bts)).Debug("running")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.WithError(err).Debug("failed")
+		return fmt.Errorf("failed to build for %s: %w: %s", options.Target, err, string(out))
+	}
+	if _, err := os.Stat(options.Path); err != nil {
+		return fmt.Errorf("plugin did not build %s: %w", options.Path, err)
+	}
+
+	ctx.Artifacts.Add(api.Binary(build, options))
+	return nil
+}
@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// script saves the build options it gets and writes a fake binary to their
+// path.
+const script = `#!/bin/sh
+set -e
+options="$(cat)"
+echo "$options" > options.json
+path="$(echo "$options" | sed -n 's/.*"path":"\([^"]*\)".*/\1/p')"
+echo "$FOO" > "$path"
+`
+
+func TestWithDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{Lang: "plugin"})
+	require.Equal(t, ".", build.Dir)
+	require.Equal(t, Default, api.For("plugin"))
+}
+
+func TestBuild(t *testing.T) {
+	var folder = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "build.sh"), []byte(script), 0755))
+	var build = Default.WithDefaults(config.Build{
+		ID:   "rust",
+		Dir:  folder,
+		Lang: "plugin",
+		Env:  []string{"FOO=bar"},
+		Plugin: config.PluginBuild{
+			Command: "sh ./build.sh {{ .Target }}",
+		},
+	})
+	var ctx = context.New(config.Project{})
+	var options = api.Options{
+		Target: "linux_mips_softfloat",
+		Name:   "mycli",
+		Path:   filepath.Join(folder, "dist", "rust_linux_mips_softfloat", "mycli"),
+		Os:     "linux",
+		Arch:   "mips",
+	}
+	require.NoError(t, Default.Build(ctx, build, options))
+
+	require.Equal(t, []*artifact.Artifact{{
+		Type:   artifact.Binary,
+		Name:   "mycli",
+		Path:   options.Path,
+		Goos:   "linux",
+		Goarch: "mips",
+		Gomips: "softfloat",
+		Extra: map[string]interface{}{
+			"Binary": "mycli",
+			"Ext":    "",
+			"ID":     "rust",
+		},
+	}}, ctx.Artifacts.List())
+
+	bts, err := ioutil.ReadFile(options.Path)
+	require.NoError(t, err)
+	require.Equal(t, "bar\n", string(bts))
+
+	bts, err = ioutil.ReadFile(filepath.Join(folder, "options.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"name": "mycli",
+		"path": "`+options.Path+`",
+		"ext": "",
+		"target": "linux_mips_softfloat",
+		"os": "linux",
+		"arch": "mips"
+	}`, string(bts))
+}
+
+func TestBuildErrors(t *testing.T) {
+	var folder = t.TempDir()
+	var options = api.Options{
+		Target: "linux_amd64",
+		Name:   "mycli",
+		Path:   filepath.Join(folder, "dist", "mycli"),
+		Os:     "linux",
+		Arch:   "amd64",
+	}
+	for name, tt := range map[string]struct {
+		command string
+		err     string
+	}{
+		"no command": {
+			err: "plugin builds need a command",
+		},
+		"blank command": {
+			command: "  ",
+			err:     "plugin builds need a command",
+		},
+		"invalid template": {
+			command: "{{ .Foo }",
+			err:     `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"failed": {
+			command: `sh -c "echo oops && exit 1"`,
+			err:     "failed to build for linux_amd64: exit status 1: oops\n",
+		},
+		"no binary": {
+			command: "true",
+			err:     "plugin did not build " + options.Path + ": stat " + options.Path + ": no such file or directory",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			var build = Default.WithDefaults(config.Build{
+				Dir:    folder,
+				Plugin: config.PluginBuild{Command: tt.command},
+			})
+			require.EqualError(t, Default.Build(ctx, build, options), tt.err)
+			require.Empty(t, ctx.Artifacts.List())
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package prebuilt provides a Builder implementation that imports binaries
+// built by other tools.
+package prebuilt
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Default builder instance.
+// nolint: gochecknoglobals
+var Default = &Builder{}
+
+// nolint: gochecknoinits
+func init() {
+	api.Register("prebuilt", Default)
+}
+
+// Builder is the prebuilt builder.
+type Builder struct{}
+
+// WithDefaults sets the defaults for a prebuilt build and returns it.
+func (*Builder) WithDefaults(build config.Build) config.Build {
+	if build.Dir == "" {
+		build.Dir = "."
+	}
+	return build
+}
+
+// Build copies the prebuilt binary of the given target to the dist folder.
+func (*Builder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	if build.Prebuilt.Path == "" {
+		return errors.New("prebuilt builds need a path")
+	}
+	var env = append(ctx.Env.Strings(), build.Env...)
+	path, err := tmpl.New(ctx).WithBuildOptions(options).WithEnvS(env).Apply(build.Prebuilt.Path)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(build.Dir, path)
+	}
+	log.WithField("binary", path).Debug("importing prebuilt binary")
+	if err := api.CopyFile(path, options.Path); err != nil {
+		return fmt.Errorf("failed to import prebuilt binary for %s: %w", options.Target, err)
+	}
+
+	ctx.Artifacts.Add(api.Binary(build, options))
+	return nil
+}
@@ -0,0 +1,132 @@
+package prebuilt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaults(t *testing.T) {
+	var build = Default.WithDefaults(config.Build{Lang: "prebuilt"})
+	require.Equal(t, ".", build.Dir)
+	require.Equal(t, Default, api.For("prebuilt"))
+}
+
+func TestBuild(t *testing.T) {
+	var folder = t.TempDir()
+	for _, target := range []string{"x86_64-unknown-linux-gnu", "armv7-unknown-linux-gnueabihf"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(folder, "target", target, "release"), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "target", target, "release", "mycli"), []byte(target), 0755))
+	}
+	var build = Default.WithDefaults(config.Build{
+		ID:   "rust",
+		Dir:  folder,
+		Lang: "prebuilt",
+		Prebuilt: config.PrebuiltBuild{
+			Path: `target/{{ if eq .Arch "arm" }}armv7-unknown-linux-gnueabihf{{ else }}x86_64-unknown-linux-gnu{{ end }}/release/mycli{{ .Ext }}`,
+		},
+	})
+	var ctx = context.New(config.Project{})
+
+	for _, options := range []api.Options{
+		{
+			Target: "linux_amd64",
+			Name:   "mycli",
+			Path:   filepath.Join(folder, "dist", "rust_linux_amd64", "mycli"),
+			Os:     "linux",
+			Arch:   "amd64",
+		},
+		{
+			Target: "linux_arm_7",
+			Name:   "mycli",
+			Path:   filepath.Join(folder, "dist", "rust_linux_arm_7", "mycli"),
+			Os:     "linux",
+			Arch:   "arm",
+		},
+	} {
+		require.NoError(t, Default.Build(ctx, build, options))
+	}
+
+	require.Equal(t, []*artifact.Artifact{
+		{
+			Type:   artifact.Binary,
+			Name:   "mycli",
+			Path:   filepath.Join(folder, "dist", "rust_linux_amd64", "mycli"),
+			Goos:   "linux",
+			Goarch: "amd64",
+			Extra: map[string]interface{}{
+				"Binary": "mycli",
+				"Ext":    "",
+				"ID":     "rust",
+			},
+		},
+		{
+			Type:   artifact.Binary,
+			Name:   "mycli",
+			Path:   filepath.Join(folder, "dist", "rust_linux_arm_7", "mycli"),
+			Goos:   "linux",
+			Goarch: "arm",
+			Goarm:  "7",
+			Extra: map[string]interface{}{
+				"Binary": "mycli",
+				"Ext":    "",
+				"ID":     "rust",
+			},
+		},
+	}, ctx.Artifacts.List())
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "dist", "rust_linux_arm_7", "mycli"))
+	require.NoError(t, err)
+	require.Equal(t, "armv7-unknown-linux-gnueabihf", string(bts))
+	info, err := os.Stat(filepath.Join(folder, "dist", "rust_linux_arm_7", "mycli"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode())
+}
+
+func TestBuildErrors(t *testing.T) {
+	var folder = t.TempDir()
+	var options = api.Options{
+		Target: "linux_amd64",
+		Name:   "mycli",
+		Path:   filepath.Join(folder, "dist", "mycli"),
+		Os:     "linux",
+		Arch:   "amd64",
+	}
+	for name, tt := range map[string]struct {
+		path string
+		err  string
+	}{
+		"no path": {
+			err: "prebuilt builds need a path",
+		},
+		"invalid template": {
+			path: "{{ .Foo }",
+			err:  `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"missing binary": {
+			path: filepath.Join(folder, "mycli_{{ .Os }}"),
+			err:  "failed to import prebuilt binary for linux_amd64: stat " + filepath.Join(folder, "mycli_linux") + ": no such file or directory",
+		},
+		"directory": {
+			path: folder,
+			err:  "failed to import prebuilt binary for linux_amd64: " + folder + " is a directory",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			var build = Default.WithDefaults(config.Build{
+				Prebuilt: config.PrebuiltBuild{Path: tt.path},
+			})
+			require.EqualError(t, Default.Build(ctx, build, options), tt.err)
+			require.Empty(t, ctx.Artifacts.List())
+		})
+	}
+}
@@ -35,17 +35,32 @@ func executePublisher(ctx *context.Context, publisher config.Publisher) error {
 	log.Debugf("filtering %d artifacts", len(ctx.Artifacts.List()))
 	artifacts := filterArtifacts(ctx.Artifacts, publisher)
 	log.Debugf("will execute custom publisher with %d artifacts", len(artifacts))
+	switch publisher.Protocol {
+	case "":
+	case ProtocolJSON:
+		return executePlugin(ctx, publisher, artifacts)
+	default:
+		return fmt.Errorf("publishing: %s: invalid protocol: %s", publisher.Name, publisher.Protocol)
+	}
 
 	var g = semerrgroup.New(ctx.Parallelism)
+	var kind = "publisher:" + publisher.Name
 	for _, artifact := range artifacts {
 		artifact := artifact
+		if ctx.Progress.IsUploaded(kind, artifact.Name) {
+			log.WithField("artifact", artifact.Name).Info("already published, skipping")
+			continue
+		}
 		g.Go(func() error {
 			c, err := resolveCommand(ctx, publisher, artifact)
 			if err != nil {
 				return err
 			}
 
-			return executeCommand(c)
+			if err := executeCommand(c); err != nil {
+				return err
+			}
+			return ctx.Progress.Uploaded(kind, artifact.Name)
 		})
 	}
 
@@ -83,6 +98,7 @@ func filterArtifacts(artifacts artifact.Artifacts, publisher config.Publisher) [
 		artifact.ByType(artifact.UploadableArchive),
 		artifact.ByType(artifact.UploadableFile),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.WindowsInstaller),
 		artifact.ByType(artifact.UploadableBinary),
 	}
 
@@ -0,0 +1,174 @@
+package exec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/logext"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/mattn/go-shellwords"
+)
+
+// ProtocolJSON is the publisher protocol in which the command is executed
+// only once, receiving all artifacts at once as JSON.
+const ProtocolJSON = "json"
+
+// PluginRequest is written as JSON to the stdin of json protocol publishers.
+type PluginRequest struct {
+	Metadata  metadata.Metadata    `json:"metadata"`
+	Artifacts []*artifact.Artifact `json:"artifacts"`
+}
+
+// PluginMessage is written as JSON by json protocol publishers to their
+// stdout, one message per line.
+type PluginMessage struct {
+	// Artifact is the name of an artifact the publisher is done with.
+	Artifact string `json:"artifact,omitempty"`
+	// Error is the reason the publisher failed to publish Artifact, if any.
+	Error string `json:"error,omitempty"`
+	// New is an artifact created by the publisher, e.g. the URL something was
+	// published to. It is added to the artifact list.
+	New *artifact.Artifact `json:"new,omitempty"`
+}
+
+func executePlugin(ctx *context.Context, publisher config.Publisher, artifacts []*artifact.Artifact) error {
+	var kind = "publisher:" + publisher.Name
+	var request = PluginRequest{
+		Metadata: metadata.New(ctx),
+	}
+	for _, a := range artifacts {
+		if ctx.Progress.IsUploaded(kind, a.Name) {
+			log.WithField("artifact", a.Name).Info("already published, skipping")
+			continue
+		}
+		request.Artifacts = append(request.Artifacts, a)
+	}
+	if len(request.Artifacts) == 0 {
+		log.WithField("name", publisher.Name).Info("nothing left to publish")
+		return nil
+	}
+	stdin, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	c, err := resolvePluginCommand(ctx, publisher)
+	if err != nil {
+		return err
+	}
+
+	// nolint: gosec
+	var cmd = exec.CommandContext(c.Ctx, c.Args[0], c.Args[1:]...)
+	cmd.Env = c.Env
+	cmd.Dir = c.Dir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = logext.NewErrWriter(log.WithField("cmd", c.Args[0]))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	log.WithField("cmd", cmd.Args).
+		WithField("artifacts", len(request.Artifacts)).
+		Info("publishing")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("publishing: %s failed: %w", c.Args[0], err)
+	}
+
+	var failures []string
+	var scanner = bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var line = scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var msg PluginMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return fmt.Errorf("publishing: %s sent an invalid message: %q: %w", c.Args[0], string(line), err)
+		}
+		if err := handlePluginMessage(ctx, kind, publisher, msg); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("publishing: failed to read %s output: %w", c.Args[0], err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("publishing: %s failed: %w", c.Args[0], err)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("publishing: %s failed to publish %d artifacts: %s", c.Args[0], len(failures), strings.Join(failures, "; "))
+	}
+	log.Debugf("command %s finished successfully", c.Args[0])
+	return nil
+}
+
+func handlePluginMessage(ctx *context.Context, kind string, publisher config.Publisher, msg PluginMessage) error {
+	if msg.New != nil {
+		msg.New.Type = artifact.Published
+		msg.New.SetExtra("Publisher", publisher.Name)
+		log.WithField("name", msg.New.Name).
+			WithField("path", msg.New.Path).
+			Info("adding published artifact")
+		ctx.Artifacts.Add(msg.New)
+	}
+	if msg.Artifact == "" {
+		return nil
+	}
+	if msg.Error != "" {
+		log.WithField("artifact", msg.Artifact).
+			WithField("error", msg.Error).
+			Error("failed to publish")
+		return fmt.Errorf("%s: %s", msg.Artifact, msg.Error)
+	}
+	log.WithField("artifact", msg.Artifact).Info("published")
+	return ctx.Progress.Uploaded(kind, msg.Artifact)
+}
+
+// resolvePluginCommand returns the command of a json protocol publisher, with
+// its templates applied against the context only, as it handles all artifacts
+// at once.
+func resolvePluginCommand(ctx *context.Context, publisher config.Publisher) (*command, error) {
+	dir, err := tmpl.New(ctx).Apply(publisher.Dir)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := tmpl.New(ctx).Apply(publisher.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	args, err := shellwords.Parse(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("publishing: %s: cmd is empty", publisher.Name)
+	}
+	env := make([]string, len(publisher.Env))
+	for i, e := range publisher.Env {
+		e, err = tmpl.New(ctx).Apply(e)
+		if err != nil {
+			return nil, err
+		}
+		env[i] = e
+	}
+	return &command{
+		Ctx:  ctx,
+		Dir:  dir,
+		Env:  env,
+		Args: args,
+	}, nil
+}
@@ -0,0 +1,171 @@
+package exec
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/progress"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func pluginContext(t *testing.T) (*context.Context, string) {
+	var folder = t.TempDir()
+	var ctx = context.New(config.Project{
+		ProjectName: "blah",
+		Dist:        folder,
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Progress = progress.New(folder)
+	for _, name := range []string{"a.tar.gz", "b.tar.gz"} {
+		var file = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(file, []byte("lorem ipsum"), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: name,
+			Path: file,
+			Type: artifact.UploadableArchive,
+			Extra: map[string]interface{}{
+				"ID": "default",
+			},
+		})
+	}
+	return ctx, folder
+}
+
+func writeScript(t *testing.T, folder, content string) string {
+	var script = filepath.Join(folder, "plugin.sh")
+	require.NoError(t, ioutil.WriteFile(script, []byte(content), 0755))
+	return script
+}
+
+func TestExecutePlugin(t *testing.T) {
+	ctx, folder := pluginContext(t)
+	var script = writeScript(t, folder, `#!/bin/sh
+cat > "$1"
+echo '{"artifact":"a.tar.gz"}'
+echo
+echo '{"artifact":"b.tar.gz","new":{"name":"b","path":"https://example.com/b"}}'
+`)
+	var stdinFile = filepath.Join(folder, "stdin.json")
+	require.NoError(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "sh " + script + " " + stdinFile,
+		},
+	}))
+
+	bts, err := ioutil.ReadFile(stdinFile)
+	require.NoError(t, err)
+	var req PluginRequest
+	require.NoError(t, json.Unmarshal(bts, &req))
+	require.Equal(t, "blah", req.Metadata.ProjectName)
+	require.Equal(t, "v1.0.0", req.Metadata.Tag)
+	require.Len(t, req.Artifacts, 2)
+	require.Equal(t, "a.tar.gz", req.Artifacts[0].Name)
+	require.Equal(t, artifact.UploadableArchive, req.Artifacts[0].Type)
+
+	var published = ctx.Artifacts.Filter(artifact.ByType(artifact.Published)).List()
+	require.Len(t, published, 1)
+	require.Equal(t, "b", published[0].Name)
+	require.Equal(t, "https://example.com/b", published[0].Path)
+	require.Equal(t, "plugin", published[0].ExtraOr("Publisher", ""))
+
+	require.True(t, ctx.Progress.IsUploaded("publisher:plugin", "a.tar.gz"))
+	require.True(t, ctx.Progress.IsUploaded("publisher:plugin", "b.tar.gz"))
+}
+
+func TestExecutePluginResume(t *testing.T) {
+	ctx, folder := pluginContext(t)
+	require.NoError(t, ctx.Progress.Uploaded("publisher:plugin", "a.tar.gz"))
+	var script = writeScript(t, folder, `#!/bin/sh
+cat > "$1"
+echo '{"artifact":"b.tar.gz"}'
+`)
+	var stdinFile = filepath.Join(folder, "stdin.json")
+	require.NoError(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "sh " + script + " " + stdinFile,
+		},
+	}))
+	bts, err := ioutil.ReadFile(stdinFile)
+	require.NoError(t, err)
+	var req PluginRequest
+	require.NoError(t, json.Unmarshal(bts, &req))
+	require.Len(t, req.Artifacts, 1)
+	require.Equal(t, "b.tar.gz", req.Artifacts[0].Name)
+}
+
+func TestExecutePluginFailures(t *testing.T) {
+	ctx, folder := pluginContext(t)
+	var script = writeScript(t, folder, `#!/bin/sh
+echo '{"artifact":"a.tar.gz"}'
+echo '{"artifact":"b.tar.gz","error":"registry is down"}'
+`)
+	require.EqualError(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "sh " + script,
+		},
+	}), "publishing: sh failed to publish 1 artifacts: b.tar.gz: registry is down")
+	require.True(t, ctx.Progress.IsUploaded("publisher:plugin", "a.tar.gz"))
+	require.False(t, ctx.Progress.IsUploaded("publisher:plugin", "b.tar.gz"))
+}
+
+func TestExecutePluginInvalidMessage(t *testing.T) {
+	ctx, folder := pluginContext(t)
+	var script = writeScript(t, folder, `#!/bin/sh
+echo 'hello'
+`)
+	require.Error(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "sh " + script,
+		},
+	}))
+}
+
+func TestExecutePluginExitCode(t *testing.T) {
+	ctx, folder := pluginContext(t)
+	var script = writeScript(t, folder, `#!/bin/sh
+exit 1
+`)
+	require.EqualError(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "sh " + script,
+		},
+	}), "publishing: sh failed: exit status 1")
+}
+
+func TestExecutePluginInvalidTemplate(t *testing.T) {
+	ctx, _ := pluginContext(t)
+	require.Error(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: ProtocolJSON,
+			Cmd:      "{{ .Nope }}",
+		},
+	}))
+}
+
+func TestExecuteInvalidProtocol(t *testing.T) {
+	ctx, _ := pluginContext(t)
+	require.EqualError(t, Execute(ctx, []config.Publisher{
+		{
+			Name:     "plugin",
+			Protocol: "grpc",
+			Cmd:      "echo",
+		},
+	}), "publishing: plugin: invalid protocol: grpc")
+}
@@ -165,9 +165,14 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 			filters = append(filters,
 				artifact.ByType(artifact.UploadableArchive),
 				artifact.ByType(artifact.LinuxPackage),
+				artifact.ByType(artifact.WindowsInstaller),
+				bySBOMOf("Archive"),
 			)
 		case ModeBinary:
-			filters = append(filters, artifact.ByType(artifact.UploadableBinary))
+			filters = append(filters,
+				artifact.ByType(artifact.UploadableBinary),
+				bySBOMOf("Binary"),
+			)
 		default:
 			err := fmt.Errorf("%s: mode \"%s\" not supported", kind, v)
 			log.WithFields(log.Fields{
@@ -189,6 +194,16 @@ func Upload(ctx *context.Context, uploads []config.Upload, kind string, check Re
 	return nil
 }
 
+// bySBOMOf filters the SBOMs of the given type of artifacts.
+func bySBOMOf(kind string) artifact.Filter {
+	return artifact.And(
+		artifact.ByType(artifact.SBOM),
+		func(a *artifact.Artifact) bool {
+			return a.ExtraOr("ArtifactType", "") == kind
+		},
+	)
+}
+
 func uploadWithFilter(ctx *context.Context, upload *config.Upload, filter artifact.Filter, kind string, check ResponseChecker) error {
 	var artifacts = ctx.Artifacts.Filter(filter).List()
 	log.Debugf("will upload %d artifacts", len(artifacts))
@@ -238,6 +253,10 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		targetURL += artifact.Name
 	}
 	log.Debugf("generated target url: %s", targetURL)
+	if ctx.Progress.IsUploaded(kind, targetURL) {
+		log.WithField("target", targetURL).Info("already uploaded, skipping")
+		return nil
+	}
 
 	var headers = map[string]string{}
 	if upload.ChecksumHeader != "" {
@@ -266,7 +285,7 @@ func uploadAsset(ctx *context.Context, upload *config.Upload, artifact *artifact
 		"mode":     upload.Mode,
 	}).Info("uploaded successful")
 
-	return nil
+	return ctx.Progress.Uploaded(kind, targetURL)
 }
 
 // uploadAssetToServer uploads the asset file to target.
@@ -6,10 +6,13 @@ package archive
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/campoy/unique"
@@ -27,6 +30,8 @@ import (
 const (
 	defaultNameTemplate       = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}"
 	defaultBinaryNameTemplate = "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}"
+	defaultFatNameTemplate    = "{{ .ProjectName }}_{{ .Version }}"
+	defaultPlatformTemplate   = "{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}"
 )
 
 // ErrArchiveDifferentBinaryCount happens when an archive uses several builds which have different goos/goarch/etc sets,
@@ -59,15 +64,15 @@ func (Pipe) Default(ctx *context.Context) error {
 			archive.ID = "default"
 		}
 		if len(archive.Files) == 0 {
-			archive.Files = []string{
-				"licence*",
-				"LICENCE*",
-				"license*",
-				"LICENSE*",
-				"readme*",
-				"README*",
-				"changelog*",
-				"CHANGELOG*",
+			archive.Files = []config.File{
+				{Source: "licence*"},
+				{Source: "LICENCE*"},
+				{Source: "license*"},
+				{Source: "LICENSE*"},
+				{Source: "readme*"},
+				{Source: "README*"},
+				{Source: "changelog*"},
+				{Source: "CHANGELOG*"},
 			}
 		}
 		if archive.NameTemplate == "" {
@@ -75,28 +80,87 @@ func (Pipe) Default(ctx *context.Context) error {
 			if archive.Format == "binary" {
 				archive.NameTemplate = defaultBinaryNameTemplate
 			}
+			if archive.Fat {
+				archive.NameTemplate = defaultFatNameTemplate
+			}
+		}
+		if archive.Fat && archive.PlatformTemplate == "" {
+			archive.PlatformTemplate = defaultPlatformTemplate
 		}
 		if len(archive.Builds) == 0 {
 			for _, build := range ctx.Config.Builds {
 				archive.Builds = append(archive.Builds, build.ID)
 			}
 		}
+		if err := checkFormats(*archive); err != nil {
+			return err
+		}
+		if archive.Fat && archive.Format == "binary" {
+			return errors.New("fat archives can't have the binary format")
+		}
+		for _, f := range archive.TemplatedFiles {
+			if f.Source == "" || f.Destination == "" {
+				return errors.New("templated_files need a src and a dst")
+			}
+			if err := checkDestination(f.Destination); err != nil {
+				return err
+			}
+		}
 		ids.Inc(archive.ID)
 	}
 	return ids.Validate()
 }
 
+// checkFormats checks the format of the archive and its overrides are either
+// supported archive formats or binary, and that tar archives, which are not
+// compressed, don't get a compression level.
+func checkFormats(arch config.Archive) error {
+	var formats = []string{arch.Format}
+	for _, override := range arch.FormatOverrides {
+		formats = append(formats, override.Format)
+	}
+	for _, format := range formats {
+		if format == "binary" {
+			continue
+		}
+		var valid bool
+		for _, f := range archive.Formats {
+			if format == f {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid archive format: %s: should be one of %s, binary", format, strings.Join(archive.Formats, ", "))
+		}
+		if format == "tar" && arch.CompressionLevel != 0 {
+			return fmt.Errorf("invalid compression level: %d: tar archives are not compressed", arch.CompressionLevel)
+		}
+	}
+	return nil
+}
+
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
 	var g = semerrgroup.New(ctx.Parallelism)
 	for i, archive := range ctx.Config.Archives {
 		archive := archive
-		var artifacts = ctx.Artifacts.Filter(
+		var binaries = ctx.Artifacts.Filter(
 			artifact.And(
 				artifact.ByType(artifact.Binary),
 				artifact.ByIDs(archive.Builds...),
 			),
-		).GroupByPlatform()
+		)
+		if archive.Fat {
+			if len(binaries.List()) == 0 {
+				continue
+			}
+			g.Go(func() error {
+				return create(ctx, archive, binaries.List())
+			})
+			continue
+		}
+		var artifacts = binaries.GroupByPlatform()
 		if err := checkArtifacts(artifacts); err != nil {
 			return fmt.Errorf("invalid archive: %d: %w", i, ErrArchiveDifferentBinaryCount)
 		}
@@ -127,9 +191,15 @@ func checkArtifacts(artifacts map[string][]*artifact.Artifact) error {
 
 func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Artifact) error {
 	var format = packageFormat(arch, binaries[0].Goos)
-	folder, err := tmpl.New(ctx).
-		WithArtifact(binaries[0], arch.Replacements).
-		Apply(arch.NameTemplate)
+	var template = tmpl.New(ctx).
+		WithArtifact(binaries[0], arch.Replacements)
+	if arch.Fat {
+		// fat archives have the binaries of all platforms, so they have no
+		// platform of their own
+		format = arch.Format
+		template = tmpl.New(ctx)
+	}
+	folder, err := template.Apply(arch.NameTemplate)
 	if err != nil {
 		return err
 	}
@@ -154,48 +224,92 @@ func create(ctx *context.Context, arch config.Archive, binaries []*artifact.Arti
 	var log = log.WithField("archive", archivePath)
 	log.Info("creating")
 
-	template := tmpl.New(ctx).
-		WithArtifact(binaries[0], arch.Replacements)
 	wrap, err := template.Apply(wrapFolder(arch))
 	if err != nil {
 		return err
 	}
 
-	var a = NewEnhancedArchive(archive.New(archiveFile), wrap)
+	compressor, err := newArchive(ctx, arch, archiveFile)
+	if err != nil {
+		return fmt.Errorf("failed to create archive %s: %w", archivePath, err)
+	}
+	var a = NewEnhancedArchive(compressor, wrap)
 	defer a.Close()
 
 	files, err := findFiles(template, arch)
 	if err != nil {
 		return fmt.Errorf("failed to find files to archive: %s", err.Error())
 	}
-	for _, f := range files {
-		if err = a.Add(f, f); err != nil {
-			return fmt.Errorf("failed to add %s to the archive: %s", f, err.Error())
+	for _, binary := range binaries {
+		var dst = binary.Name
+		if arch.Fat {
+			dir, err := tmpl.New(ctx).
+				WithArtifact(binary, arch.Replacements).
+				Apply(arch.PlatformTemplate)
+			if err != nil {
+				return err
+			}
+			dst = filepath.Join(dir, binary.Name)
 		}
+		files = append(files, config.File{Source: binary.Path, Destination: dst})
 	}
-	for _, binary := range binaries {
-		if err := a.Add(binary.Name, binary.Path); err != nil {
-			return fmt.Errorf("failed to add %s -> %s to the archive: %s", binary.Path, binary.Name, err.Error())
+	if len(arch.TemplatedFiles) > 0 || arch.Manifest {
+		dir, err := ioutil.TempDir("", "goreleaser-archive")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		templated, err := templatedFiles(template, arch, files, dir)
+		if err != nil {
+			return fmt.Errorf("failed to render templated files: %w", err)
+		}
+		files = append(files, templated...)
+	}
+	if ctx.Config.Reproducible.Enabled {
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Destination < files[j].Destination
+		})
+	}
+	for _, f := range files {
+		if err := a.Add(f); err != nil {
+			return fmt.Errorf("failed to add %s -> %s to the archive: %s", f.Source, f.Destination, err.Error())
 		}
 	}
-	ctx.Artifacts.Add(&artifact.Artifact{
-		Type:   artifact.UploadableArchive,
-		Name:   folder + "." + format,
-		Path:   archivePath,
-		Goos:   binaries[0].Goos,
-		Goarch: binaries[0].Goarch,
-		Goarm:  binaries[0].Goarm,
-		Gomips: binaries[0].Gomips,
+	var art = &artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: folder + "." + format,
+		Path: archivePath,
 		Extra: map[string]interface{}{
 			"Builds":    binaries,
 			"ID":        arch.ID,
 			"Format":    arch.Format,
 			"WrappedIn": wrap,
 		},
-	})
+	}
+	if !arch.Fat {
+		art.Goos = binaries[0].Goos
+		art.Goarch = binaries[0].Goarch
+		art.Goarm = binaries[0].Goarm
+		art.Gomips = binaries[0].Gomips
+	}
+	ctx.Artifacts.Add(art)
 	return nil
 }
 
+// newArchive creates the archive for the given file, with the compression
+// level of its config. In reproducible mode, all its entries get the commit
+// date and root ownership.
+func newArchive(ctx *context.Context, arch config.Archive, file *os.File) (archive.Archive, error) {
+	var opts = archive.Options{Level: arch.CompressionLevel}
+	if ctx.Config.Reproducible.Enabled {
+		opts.Mtime = ctx.Git.CommitDate
+		if opts.Mtime.IsZero() {
+			opts.Mtime = time.Unix(0, 0)
+		}
+	}
+	return archive.NewWithOptions(file, opts)
+}
+
 func wrapFolder(a config.Archive) string {
 	switch a.WrapInDirectory {
 	case "true":
@@ -234,25 +348,69 @@ func skip(ctx *context.Context, archive config.Archive, binaries []*artifact.Art
 	return nil
 }
 
-func findFiles(template *tmpl.Template, archive config.Archive) (result []string, err error) {
-	for _, glob := range archive.Files {
-		replaced, err := template.Apply(glob)
+// findFiles gives the files to add to the archive, with their destination
+// inside it. Their globs, destinations and mtimes are templates.
+func findFiles(template *tmpl.Template, archive config.Archive) (result []config.File, err error) {
+	for _, f := range archive.Files {
+		replaced, err := template.Apply(f.Source)
 		if err != nil {
-			return result, fmt.Errorf("failed to apply template %s: %w", glob, err)
+			return result, fmt.Errorf("failed to apply template %s: %w", f.Source, err)
 		}
 		files, err := zglob.Glob(replaced)
 		if err != nil {
-			return result, fmt.Errorf("globbing failed for pattern %s: %w", glob, err)
+			return result, fmt.Errorf("globbing failed for pattern %s: %w", f.Source, err)
+		}
+		dst, err := template.Apply(f.Destination)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply template %s: %w", f.Destination, err)
+		}
+		info, err := fileInfo(template, f.Info)
+		if err != nil {
+			return result, err
+		}
+		for _, file := range files {
+			result = append(result, config.File{
+				Source:      file,
+				Destination: destination(file, dst, f.StripParent),
+				Info:        info,
+			})
 		}
-		result = append(result, files...)
 	}
 	// remove duplicates
 	unique.Slice(&result, func(i, j int) bool {
-		return strings.Compare(result[i], result[j]) < 0
+		if result[i].Destination != result[j].Destination {
+			return result[i].Destination < result[j].Destination
+		}
+		return result[i].Source < result[j].Source
 	})
 	return
 }
 
+// fileInfo parses the templated mtime of the given file info.
+func fileInfo(template *tmpl.Template, info config.FileInfo) (config.FileInfo, error) {
+	if info.MTime == "" {
+		return info, nil
+	}
+	mtime, err := template.Apply(info.MTime)
+	if err != nil {
+		return info, fmt.Errorf("failed to apply template %s: %w", info.MTime, err)
+	}
+	info.ParsedMTime, err = time.Parse(time.RFC3339Nano, mtime)
+	if err != nil {
+		return info, fmt.Errorf("failed to parse mtime %s: %w", mtime, err)
+	}
+	return info, nil
+}
+
+// destination of the given file inside the archive: its path, or its name
+// with strip parent, inside dst if set.
+func destination(file, dst string, stripParent bool) string {
+	if stripParent {
+		file = filepath.Base(file)
+	}
+	return filepath.Join(dst, file)
+}
+
 func packageFormat(archive config.Archive, platform string) string {
 	for _, override := range archive.FormatOverrides {
 		if strings.HasPrefix(platform, override.Goos) {
@@ -282,14 +440,15 @@ type EnhancedArchive struct {
 }
 
 // Add adds a file.
-func (d EnhancedArchive) Add(name, path string) error {
-	name = strings.ReplaceAll(filepath.Join(d.wrap, name), "\\", "/")
-	log.Debugf("adding file: %s as %s", path, name)
+func (d EnhancedArchive) Add(f config.File) error {
+	var name = strings.ReplaceAll(filepath.Join(d.wrap, f.Destination), "\\", "/")
+	log.Debugf("adding file: %s as %s", f.Source, name)
 	if _, ok := d.files[name]; ok {
 		return fmt.Errorf("file %s already exists in the archive", name)
 	}
-	d.files[name] = path
-	return d.a.Add(name, path)
+	d.files[name] = f.Source
+	f.Destination = name
+	return d.a.Add(f)
 }
 
 // Close closes the underlying archive.
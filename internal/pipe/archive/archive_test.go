@@ -4,15 +4,18 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pkg/archive"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -33,7 +36,7 @@ func createFakeBinary(t *testing.T, dist, arch, bin string) {
 func TestRunPipe(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
-	for _, format := range []string{"tar.gz", "zip"} {
+	for _, format := range []string{"tar.gz", "zip", "tar.zst", "tar.bz2", "tar"} {
 		t.Run("Archive format "+format, func(tt *testing.T) {
 			var dist = filepath.Join(folder, format+"_dist")
 			require.NoError(t, os.Mkdir(dist, 0755))
@@ -57,9 +60,9 @@ func TestRunPipe(t *testing.T) {
 							ID:           "myid",
 							Builds:       []string{"default"},
 							NameTemplate: defaultNameTemplate,
-							Files: []string{
-								"README.{{.Os}}.*",
-								"./foo/**/*",
+							Files: []config.File{
+								{Source: "README.{{.Os}}.*"},
+								{Source: "./foo/**/*"},
 							},
 							FormatOverrides: []config.FormatOverride{
 								{
@@ -398,8 +401,8 @@ func TestRunPipeInvalidGlob(t *testing.T) {
 					Builds:       []string{"default"},
 					NameTemplate: "foo",
 					Format:       "zip",
-					Files: []string{
-						"[x-]",
+					Files: []config.File{
+						{Source: "[x-]"},
 					},
 				},
 			},
@@ -471,8 +474,8 @@ func TestRunPipeInvalidFilesNameTemplate(t *testing.T) {
 					Builds:       []string{"default"},
 					NameTemplate: "foo",
 					Format:       "zip",
-					Files: []string{
-						"{{.asdsd}",
+					Files: []config.File{
+						{Source: "{{.asdsd}"},
 					},
 				},
 			},
@@ -551,8 +554,8 @@ func TestRunPipeWrap(t *testing.T) {
 					Replacements: map[string]string{
 						"darwin": "macOS",
 					},
-					Files: []string{
-						"README.*",
+					Files: []config.File{
+						{Source: "README.*"},
 					},
 				},
 			},
@@ -594,6 +597,425 @@ func TestRunPipeWrap(t *testing.T) {
 	}
 }
 
+func TestRunPipeReproducible(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "darwinamd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "darwinamd64", "mybin"), []byte("bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "zzz.txt"), []byte("zzz"), 0644))
+	var ctx = context.New(
+		config.Project{
+			Dist:         dist,
+			Reproducible: config.Reproducible{Enabled: true},
+			Archives: []config.Archive{
+				{
+					Builds:       []string{"default"},
+					NameTemplate: "foo",
+					Format:       "tar.gz",
+					Files:        []config.File{{Source: "zzz.txt"}},
+				},
+			},
+		},
+	)
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Git.CommitDate = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "darwinamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+			"ID":     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	f, err := os.Open(filepath.Join(dist, "foo.tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer gr.Close()
+	var r = tar.NewReader(gr)
+	var paths []string
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, ctx.Git.CommitDate.Equal(h.ModTime))
+		require.Equal(t, 0, h.Uid)
+		require.Empty(t, h.Uname)
+		paths = append(paths, h.Name)
+	}
+	require.Equal(t, []string{"mybin", "zzz.txt"}, paths)
+}
+
+func TestRunPipeFilesInfo(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "darwinamd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "darwinamd64", "mybin"), []byte("bin"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "man", "man1"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "man", "man1", "mybin.1"), []byte("man"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "mybin.conf"), []byte("conf"), 0600))
+	var ctx = context.New(
+		config.Project{
+			Dist: dist,
+			Archives: []config.Archive{
+				{
+					Builds:       []string{"default"},
+					NameTemplate: "foo",
+					Format:       "tar.gz",
+					Files: []config.File{
+						{
+							Source:      "man/man1/*.1",
+							Destination: "share/man/man1",
+							StripParent: true,
+							Info: config.FileInfo{
+								Mode:  0644,
+								MTime: "{{ .CommitDate }}",
+							},
+						},
+						{
+							Source:      "mybin.conf",
+							Destination: "etc/{{ .ProjectName }}",
+							Info: config.FileInfo{
+								Owner: "root",
+								Group: "wheel",
+							},
+						},
+					},
+				},
+			},
+		},
+	)
+	ctx.Config.ProjectName = "mybin"
+	ctx.Git.CurrentTag = "v0.0.1"
+	ctx.Git.CommitDate = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "darwinamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+			"ID":     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	f, err := os.Open(filepath.Join(dist, "foo.tar.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer gr.Close()
+	var r = tar.NewReader(gr)
+	var headers = map[string]*tar.Header{}
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		headers[h.Name] = h
+	}
+	require.Len(t, headers, 3)
+	require.Contains(t, headers, "mybin")
+
+	var man = headers["share/man/man1/mybin.1"]
+	require.NotNil(t, man)
+	require.Equal(t, int64(0644), man.Mode)
+	require.True(t, ctx.Git.CommitDate.Equal(man.ModTime))
+
+	var conf = headers["etc/mybin/mybin.conf"]
+	require.NotNil(t, conf)
+	require.Equal(t, int64(0600), conf.Mode)
+	require.Equal(t, "root", conf.Uname)
+	require.Equal(t, "wheel", conf.Gname)
+}
+
+func TestRunPipeInvalidFilesMTime(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "README.md"), []byte("readme"), 0644))
+	var template = tmpl.New(context.New(config.Project{}))
+	_, err := findFiles(template, config.Archive{
+		Files: []config.File{
+			{Source: "README.md", Info: config.FileInfo{MTime: "yesterday"}},
+		},
+	})
+	require.EqualError(t, err, `failed to parse mtime yesterday: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`)
+}
+
+func TestRunPipeTemplatedFiles(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	for _, dir := range []string{"server_linux_amd64", "cli_linux_amd64", "server_darwin_amd64", "cli_darwin_amd64"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dist, dir), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dist, dir, "bin"), []byte(dir), 0755))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "INSTALL.tmpl"), []byte("{{ .ProjectName }} {{ .Version }} for {{ .Os }}:\n{{ range .Files }}- {{ .Name }}\n{{ end }}"), 0644))
+	var ctx = context.New(
+		config.Project{
+			ProjectName: "myapp",
+			Dist:        dist,
+			Archives: []config.Archive{
+				{
+					Builds:       []string{"server", "cli"},
+					NameTemplate: "myapp_{{ .Os }}",
+					Format:       "tar.gz",
+					Files:        []config.File{{Source: "none*"}},
+					TemplatedFiles: []config.TemplatedFile{
+						{Source: "INSTALL.tmpl", Destination: "docs/INSTALL_{{ .Os }}.txt"},
+					},
+					Manifest: true,
+				},
+			},
+		},
+	)
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, goos := range []string{"linux", "darwin"} {
+		for _, id := range []string{"server", "cli"} {
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Goos:   goos,
+				Goarch: "amd64",
+				Name:   "myapp-" + id,
+				Path:   filepath.Join(dist, id+"_"+goos+"_amd64", "bin"),
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					"Binary": "myapp-" + id,
+					"ID":     id,
+				},
+			})
+		}
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var sha256sum = func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+	for _, goos := range []string{"linux", "darwin"} {
+		var contents = tarContents(t, filepath.Join(dist, "myapp_"+goos+".tar.gz"))
+		require.Len(t, contents, 4)
+		require.Equal(t, "myapp 1.0.0 for "+goos+":\n- myapp-cli\n- myapp-server\n", contents["docs/INSTALL_"+goos+".txt"])
+		require.Equal(t, "myapp-cli 1.0.0 sha256:"+sha256sum("cli_"+goos+"_amd64")+"\n"+
+			"myapp-server 1.0.0 sha256:"+sha256sum("server_"+goos+"_amd64")+"\n",
+			contents["MANIFEST"])
+	}
+}
+
+func TestRunPipeTemplatedFilesOutsideArchive(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "darwin_amd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "darwin_amd64", "bin"), []byte("bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "INSTALL.tmpl"), []byte("{{ .Version }}"), 0644))
+	var ctx = context.New(
+		config.Project{
+			Dist: dist,
+			Archives: []config.Archive{
+				{
+					Builds:       []string{"default"},
+					NameTemplate: "myapp",
+					Format:       "tar.gz",
+					Files:        []config.File{{Source: "none*"}},
+					TemplatedFiles: []config.TemplatedFile{
+						{Source: "INSTALL.tmpl", Destination: "{{ .Env.DST }}"},
+					},
+				},
+			},
+		},
+	)
+	ctx.Env = map[string]string{"DST": "../INSTALL"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "bin",
+		Path:   filepath.Join(dist, "darwin_amd64", "bin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "bin",
+			"ID":     "default",
+		},
+	})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "templated_files dst ../INSTALL is outside of the archive")
+	require.NoFileExists(t, filepath.Join(dist, "INSTALL"))
+}
+
+func tarContents(t *testing.T, path string) map[string]string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	defer gr.Close()
+	var r = tar.NewReader(gr)
+	var contents = map[string]string{}
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		bts, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		contents[next.Name] = string(bts)
+	}
+	return contents
+}
+
+func TestRunPipeFat(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(folder, "run.tmpl"), []byte("{{ range .Files }}{{ .Name }}\n{{ end }}"), 0644))
+	var ctx = context.New(
+		config.Project{
+			ProjectName: "myapp",
+			Dist:        dist,
+			Archives: []config.Archive{
+				{
+					Builds: []string{"default"},
+					Format: "tar.gz",
+					Fat:    true,
+					Files:  []config.File{{Source: "none*"}},
+					FormatOverrides: []config.FormatOverride{
+						{Goos: "windows", Format: "zip"},
+					},
+					Replacements: map[string]string{"darwin": "macos"},
+					TemplatedFiles: []config.TemplatedFile{
+						{
+							Source:      "run.tmpl",
+							Destination: "run.sh",
+							Info:        config.FileInfo{Mode: 0755},
+						},
+					},
+				},
+			},
+		},
+	)
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	for _, a := range []*artifact.Artifact{
+		{Goos: "linux", Goarch: "amd64", Name: "myapp"},
+		{Goos: "linux", Goarch: "arm", Goarm: "7", Name: "myapp"},
+		{Goos: "darwin", Goarch: "amd64", Name: "myapp"},
+		{Goos: "windows", Goarch: "amd64", Name: "myapp.exe"},
+	} {
+		a.Path = filepath.Join(dist, a.Goos+a.Goarch+a.Goarm, a.Name)
+		a.Type = artifact.Binary
+		a.Extra = map[string]interface{}{"Binary": "myapp", "ID": "default"}
+		require.NoError(t, os.MkdirAll(filepath.Dir(a.Path), 0755))
+		require.NoError(t, ioutil.WriteFile(a.Path, []byte(a.Goos), 0755))
+		ctx.Artifacts.Add(a)
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	require.Len(t, archives, 1)
+	require.Equal(t, "myapp_1.0.0.tar.gz", archives[0].Name)
+	require.Empty(t, archives[0].Goos)
+	require.Empty(t, archives[0].Goarch)
+	require.Len(t, archives[0].Extra["Builds"], 4)
+
+	var contents = tarContents(t, filepath.Join(dist, "myapp_1.0.0.tar.gz"))
+	var files = "linux_amd64/myapp\nlinux_armv7/myapp\nmacos_amd64/myapp\nwindows_amd64/myapp.exe\n"
+	require.Equal(t, map[string]string{
+		"linux_amd64/myapp":       "linux",
+		"linux_armv7/myapp":       "linux",
+		"macos_amd64/myapp":       "darwin",
+		"windows_amd64/myapp.exe": "windows",
+		"run.sh":                  files,
+	}, contents)
+}
+
+func TestRunPipeFatInvalidPlatformTemplate(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "linuxamd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "linuxamd64", "myapp"), []byte("bin"), 0755))
+	var ctx = context.New(
+		config.Project{
+			ProjectName: "myapp",
+			Dist:        dist,
+			Archives: []config.Archive{
+				{
+					Builds:           []string{"default"},
+					NameTemplate:     "myapp",
+					Format:           "tar.gz",
+					Fat:              true,
+					PlatformTemplate: "{{ .Os }",
+				},
+			},
+		},
+	)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "myapp",
+		Path:   filepath.Join(dist, "linuxamd64", "myapp"),
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"Binary": "myapp", "ID": "default"},
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestDefaultFat(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Archives: []config.Archive{{Fat: true}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, defaultFatNameTemplate, ctx.Config.Archives[0].NameTemplate)
+	require.Equal(t, defaultPlatformTemplate, ctx.Config.Archives[0].PlatformTemplate)
+
+	ctx = context.New(config.Project{
+		Archives: []config.Archive{{Fat: true, Format: "binary"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "fat archives can't have the binary format")
+}
+
+func TestDefaultInvalidTemplatedFiles(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Archives: []config.Archive{
+			{
+				TemplatedFiles: []config.TemplatedFile{{Source: "INSTALL.tmpl"}},
+			},
+		},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "templated_files need a src and a dst")
+}
+
+func TestDefaultTemplatedFilesOutsideArchive(t *testing.T) {
+	for _, dst := range []string{"../INSTALL", "docs/../../INSTALL", "/etc/INSTALL"} {
+		dst := dst
+		t.Run(dst, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Archives: []config.Archive{
+					{
+						TemplatedFiles: []config.TemplatedFile{{Source: "INSTALL.tmpl", Destination: dst}},
+					},
+				},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), "templated_files dst "+dst+" is outside of the archive")
+		})
+	}
+}
+
 func TestDefault(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -614,8 +1036,8 @@ func TestDefaultSet(t *testing.T) {
 					Builds:       []string{"default"},
 					NameTemplate: "foo",
 					Format:       "zip",
-					Files: []string{
-						"foo",
+					Files: []config.File{
+						{Source: "foo"},
 					},
 				},
 			},
@@ -624,7 +1046,7 @@ func TestDefaultSet(t *testing.T) {
 	require.NoError(t, Pipe{}.Default(ctx))
 	require.Equal(t, "foo", ctx.Config.Archives[0].NameTemplate)
 	require.Equal(t, "zip", ctx.Config.Archives[0].Format)
-	require.Equal(t, "foo", ctx.Config.Archives[0].Files[0])
+	require.Equal(t, "foo", ctx.Config.Archives[0].Files[0].Source)
 }
 
 func TestDefaultFormatBinary(t *testing.T) {
@@ -641,6 +1063,83 @@ func TestDefaultFormatBinary(t *testing.T) {
 	require.Equal(t, defaultBinaryNameTemplate, ctx.Config.Archives[0].NameTemplate)
 }
 
+func TestDefaultInvalidFormat(t *testing.T) {
+	for name, arch := range map[string]config.Archive{
+		"format": {Format: "tgz"},
+		"override": {
+			Format: "tar.gz",
+			FormatOverrides: []config.FormatOverride{
+				{Goos: "windows", Format: "7z"},
+			},
+		},
+	} {
+		arch := arch
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Archives: []config.Archive{arch},
+			})
+			var err = Pipe{}.Default(ctx)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid archive format: ")
+			require.Contains(t, err.Error(), ": should be one of tar.gz, tar.xz, tar.zst, tar.bz2, tar, zip, gz, binary")
+		})
+	}
+}
+
+func TestDefaultTarCompressionLevel(t *testing.T) {
+	for name, arch := range map[string]config.Archive{
+		"format": {Format: "tar", CompressionLevel: 1},
+		"override": {
+			Format:           "tar.gz",
+			CompressionLevel: 1,
+			FormatOverrides: []config.FormatOverride{
+				{Goos: "linux", Format: "tar"},
+			},
+		},
+	} {
+		arch := arch
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Archives: []config.Archive{arch},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), "invalid compression level: 1: tar archives are not compressed")
+		})
+	}
+}
+
+func TestRunPipeInvalidCompressionLevel(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.MkdirAll(filepath.Join(dist, "darwinamd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, "darwinamd64", "mybin"), []byte("bin"), 0755))
+	var ctx = context.New(
+		config.Project{
+			Dist: dist,
+			Archives: []config.Archive{
+				{
+					Builds:           []string{"default"},
+					NameTemplate:     "foo",
+					Format:           "tar.zst",
+					CompressionLevel: 30,
+				},
+			},
+		},
+	)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "darwinamd64", "mybin"),
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+			"ID":     "default",
+		},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "failed to create archive "+filepath.Join(dist, "foo.tar.zst")+": invalid zstd compression level: 30: should be between 1 and 22")
+}
+
 func TestFormatFor(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -685,8 +1184,8 @@ func TestBinaryOverride(t *testing.T) {
 						{
 							Builds:       []string{"default"},
 							NameTemplate: defaultNameTemplate,
-							Files: []string{
-								"README.*",
+							Files: []config.File{
+								{Source: "README.*"},
 							},
 							FormatOverrides: []config.FormatOverride{
 								{
@@ -759,9 +1258,9 @@ func TestRunPipeSameArchiveFilename(t *testing.T) {
 				{
 					Builds:       []string{"default"},
 					NameTemplate: "same-filename",
-					Files: []string{
-						"README.*",
-						"./foo/**/*",
+					Files: []config.File{
+						{Source: "README.*"},
+						{Source: "./foo/**/*"},
 					},
 					Format: "tar.gz",
 				},
@@ -799,7 +1298,7 @@ func TestRunPipeSameArchiveFilename(t *testing.T) {
 }
 
 func TestDuplicateFilesInsideArchive(t *testing.T) {
-	f, err := ioutil.TempFile("", "")
+	f, err := ioutil.TempFile("", "*.tar.gz")
 	require.NoError(t, err)
 	defer f.Close()
 	defer os.Remove(f.Name())
@@ -809,10 +1308,12 @@ func TestDuplicateFilesInsideArchive(t *testing.T) {
 	defer ff.Close()
 	defer os.Remove(ff.Name())
 
-	a := NewEnhancedArchive(archive.New(f), "")
+	compressor, err := archive.New(f)
+	require.NoError(t, err)
+	a := NewEnhancedArchive(compressor, "")
 	defer a.Close()
-	require.NoError(t, a.Add("foo", ff.Name()))
-	require.EqualError(t, a.Add("foo", ff.Name()), "file foo already exists in the archive")
+	require.NoError(t, a.Add(config.File{Source: ff.Name(), Destination: "foo"}))
+	require.EqualError(t, a.Add(config.File{Source: ff.Name(), Destination: "foo"}), "file foo already exists in the archive")
 }
 
 func TestWrapInDirectory(t *testing.T) {
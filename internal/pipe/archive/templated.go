@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+const (
+	manifestName     = "MANIFEST"
+	manifestTemplate = `{{ range .Files }}{{ .Name }} {{ $.Version }} sha256:{{ .Checksum }}
+{{ end }}`
+)
+
+// archivedFile is a file of an archive, as given to its templated files in
+// .Files.
+type archivedFile struct {
+	Name     string
+	Checksum string
+}
+
+// templatedFiles renders the templated files of the archive, and its manifest
+// if enabled, into dir. The given files of the archive are available to them
+// as .Files.
+func templatedFiles(template *tmpl.Template, arch config.Archive, files []config.File, dir string) ([]config.File, error) {
+	sums, err := checksums(files)
+	if err != nil {
+		return nil, err
+	}
+	template = template.WithExtraFields(tmpl.Fields{"Files": sums})
+
+	var templated = append([]config.TemplatedFile{}, arch.TemplatedFiles...)
+	if arch.Manifest {
+		templated = append(templated, config.TemplatedFile{Destination: manifestName})
+	}
+	var result = make([]config.File, 0, len(templated))
+	for _, f := range templated {
+		var content = manifestTemplate
+		if f.Source != "" {
+			bts, err := ioutil.ReadFile(f.Source)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", f.Source, err)
+			}
+			content = string(bts)
+		}
+		rendered, err := template.Apply(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply template %s: %w", f.Source, err)
+		}
+		dst, err := template.Apply(f.Destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply template %s: %w", f.Destination, err)
+		}
+		if err := checkDestination(dst); err != nil {
+			return nil, err
+		}
+		info, err := fileInfo(template, f.Info)
+		if err != nil {
+			return nil, err
+		}
+		var path = filepath.Join(dir, dst)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, []byte(rendered), 0644); err != nil {
+			return nil, err
+		}
+		result = append(result, config.File{
+			Source:      path,
+			Destination: dst,
+			Info:        info,
+		})
+	}
+	return result, nil
+}
+
+// checkDestination checks the given destination of a templated file is inside
+// the archive, as it's rendered in a folder of the dist before being archived.
+func checkDestination(dst string) error {
+	var clean = filepath.Clean(filepath.FromSlash(dst))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("templated_files dst %s is outside of the archive", dst)
+	}
+	return nil
+}
+
+// checksums gives the sha256 of all the given files which aren't folders,
+// sorted by name.
+func checksums(files []config.File) ([]archivedFile, error) {
+	var result = make([]archivedFile, 0, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f.Source)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		sum, err := artifact.Artifact{Path: f.Source}.Checksum("sha256")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, archivedFile{
+			Name:     filepath.ToSlash(f.Destination),
+			Checksum: sum,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
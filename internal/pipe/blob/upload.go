@@ -80,6 +80,9 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 		artifact.ByType(artifact.Checksum),
 		artifact.ByType(artifact.Signature),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.WindowsInstaller),
+		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.Attestation),
 	)
 	if len(conf.IDs) > 0 {
 		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
@@ -125,6 +128,12 @@ func doUpload(ctx *context.Context, conf config.Blob) error {
 }
 
 func uploadData(ctx *context.Context, conf config.Blob, up uploader, dataFile, uploadFile, bucketURL string) error {
+	var key = bucketURL + "/" + uploadFile
+	if ctx.Progress.IsUploaded("blob", key) {
+		log.WithField("file", uploadFile).Info("already uploaded to bucket, skipping")
+		return nil
+	}
+
 	data, err := getData(ctx, conf, dataFile)
 	if err != nil {
 		return err
@@ -134,7 +143,7 @@ func uploadData(ctx *context.Context, conf config.Blob, up uploader, dataFile, u
 	if err != nil {
 		return handleError(err, bucketURL)
 	}
-	return err
+	return ctx.Progress.Uploaded("blob", key)
 }
 
 func handleError(err error, url string) error {
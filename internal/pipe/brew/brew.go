@@ -145,6 +145,28 @@ func contains(ss []string, s string) bool {
 	return false
 }
 
+// preferUniversal drops the per-arch darwin archives if there is an archive
+// of a universal binary, which runs on all of them.
+func preferUniversal(archives []*artifact.Artifact) []*artifact.Artifact {
+	var universal bool
+	for _, a := range archives {
+		if a.Goos == "darwin" && a.Goarch == "all" {
+			universal = true
+		}
+	}
+	if !universal {
+		return archives
+	}
+	var result = make([]*artifact.Artifact, 0, len(archives))
+	for _, a := range archives {
+		if a.Goos == "darwin" && a.Goarch != "all" {
+			continue
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
 func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
 	if brew.Tap.Name == "" {
 		return pipe.Skip("brew section is not configured")
@@ -173,6 +195,7 @@ func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
 		artifact.Or(
 			artifact.ByGoarch("amd64"),
 			artifact.ByGoarch("arm64"),
+			artifact.ByGoarch("all"),
 			artifact.And(
 				artifact.ByGoarch("arm"),
 				artifact.ByGoarm(brew.Goarm),
@@ -184,7 +207,7 @@ func doRun(ctx *context.Context, brew config.Homebrew, cl client.Client) error {
 		filters = append(filters, artifact.ByIDs(brew.IDs...))
 	}
 
-	var archives = ctx.Artifacts.Filter(artifact.And(filters...)).List()
+	var archives = preferUniversal(ctx.Artifacts.Filter(artifact.And(filters...)).List())
 	if len(archives) == 0 {
 		return ErrNoArchivesFound
 	}
@@ -668,6 +668,15 @@ func TestRunPipeMultipleArchivesSameOsBuild(t *testing.T) {
 	}
 }
 
+func TestPreferUniversal(t *testing.T) {
+	var linux = &artifact.Artifact{Name: "linux", Goos: "linux", Goarch: "amd64"}
+	var amd64 = &artifact.Artifact{Name: "amd64", Goos: "darwin", Goarch: "amd64"}
+	var arm64 = &artifact.Artifact{Name: "arm64", Goos: "darwin", Goarch: "arm64"}
+	var all = &artifact.Artifact{Name: "all", Goos: "darwin", Goarch: "all"}
+	require.Equal(t, []*artifact.Artifact{linux, amd64}, preferUniversal([]*artifact.Artifact{linux, amd64}))
+	require.Equal(t, []*artifact.Artifact{linux, all}, preferUniversal([]*artifact.Artifact{linux, amd64, arm64, all}))
+}
+
 func TestRunPipeBrewNotSetup(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{},
@@ -6,15 +6,16 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/logext"
-	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
 	builders "github.com/goreleaser/goreleaser/pkg/build"
 	"github.com/goreleaser/goreleaser/pkg/config"
@@ -23,6 +24,8 @@ import (
 
 	// langs to init.
 	_ "github.com/goreleaser/goreleaser/internal/builders/golang"
+	_ "github.com/goreleaser/goreleaser/internal/builders/plugin"
+	_ "github.com/goreleaser/goreleaser/internal/builders/prebuilt"
 )
 
 // Pipe for build.
@@ -40,32 +43,45 @@ func (Pipe) Run(ctx *context.Context) error {
 			continue
 		}
 		log.WithField("build", build).Debug("building")
-		if err := runPipeOnBuild(ctx, build); err != nil {
-			return err
-		}
 	}
-	return nil
+	return schedule(ctx, ctx.Config.Builds)
 }
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
 	var ids = ids.New("builds")
 	for i, build := range ctx.Config.Builds {
-		ctx.Config.Builds[i] = buildWithDefaults(ctx, build)
+		build, err := buildWithDefaults(ctx, build)
+		if err != nil {
+			return err
+		}
+		ctx.Config.Builds[i] = build
 		ids.Inc(ctx.Config.Builds[i].ID)
 	}
 	if len(ctx.Config.Builds) == 0 {
-		ctx.Config.Builds = []config.Build{
-			buildWithDefaults(ctx, ctx.Config.SingleBuild),
+		build, err := buildWithDefaults(ctx, ctx.Config.SingleBuild)
+		if err != nil {
+			return err
 		}
+		ctx.Config.Builds = []config.Build{build}
+	}
+	if err := ids.Validate(); err != nil {
+		return err
 	}
-	return ids.Validate()
+	if err := checkDependencies(ctx.Config.Builds); err != nil {
+		return err
+	}
+	return selectBuilds(ctx)
 }
 
-func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
+func buildWithDefaults(ctx *context.Context, build config.Build) (config.Build, error) {
 	if build.Lang == "" {
 		build.Lang = "go"
 	}
+	var builder = builders.For(build.Lang)
+	if builder == nil {
+		return build, fmt.Errorf("invalid lang: %s: no builder registered for it", build.Lang)
+	}
 	if build.Binary == "" {
 		build.Binary = ctx.Config.ProjectName
 	}
@@ -75,36 +91,40 @@ func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
 	for k, v := range build.Env {
 		build.Env[k] = os.ExpandEnv(v)
 	}
-	return builders.For(build.Lang).WithDefaults(build)
+	build = builder.WithDefaults(build)
+	if len(build.Targets) == 0 && !build.Skip {
+		return build, fmt.Errorf("build %s has no targets", build.ID)
+	}
+	return build, nil
 }
 
 func runPipeOnBuild(ctx *context.Context, build config.Build) error {
-	var g = semerrgroup.New(ctx.Parallelism)
-	for _, target := range build.Targets {
-		target := target
-		build := build
-		g.Go(func() error {
-			opts, err := buildOptionsForTarget(ctx, build, target)
-			if err != nil {
-				return err
-			}
+	return schedule(ctx, []config.Build{build})
+}
 
-			if err := runHook(ctx, *opts, build.Env, build.Hooks.Pre); err != nil {
-				return fmt.Errorf("pre hook failed: %w", err)
-			}
-			if err := doBuild(ctx, build, *opts); err != nil {
-				return err
-			}
-			if !ctx.SkipPostBuildHooks {
-				if err := runHook(ctx, *opts, build.Env, build.Hooks.Post); err != nil {
-					return fmt.Errorf("post hook failed: %w", err)
-				}
-			}
-			return nil
-		})
+func buildTarget(ctx *context.Context, build config.Build, target string) error {
+	opts, err := buildOptionsForTarget(ctx, build, target)
+	if err != nil {
+		return err
 	}
 
-	return g.Wait()
+	if err := runHook(ctx, *opts, build.Env, build.Hooks.Pre); err != nil {
+		return fmt.Errorf("pre hook failed: %w", err)
+	}
+	if err := doBuild(ctx, build, *opts); err != nil {
+		return err
+	}
+	if ctx.Config.Reproducible.Enabled && ctx.Config.Reproducible.Verify {
+		if err := verify(ctx, build, *opts); err != nil {
+			return err
+		}
+	}
+	if !ctx.SkipPostBuildHooks {
+		if err := runHook(ctx, *opts, build.Env, build.Hooks.Post); err != nil {
+			return fmt.Errorf("post hook failed: %w", err)
+		}
+	}
+	return nil
 }
 
 func runHook(ctx *context.Context, opts builders.Options, buildEnv []string, hooks config.BuildHooks) error {
@@ -156,6 +176,43 @@ func doBuild(ctx *context.Context, build config.Build, opts builders.Options) er
 	return builders.For(build.Lang).Build(ctx, build, opts)
 }
 
+// verify builds the target a second time, without the build cache, and checks
+// the result is byte for byte the same as the binary built before.
+func verify(ctx *context.Context, build config.Build, opts builders.Options) error {
+	dir, err := ioutil.TempDir("", "goreleaser-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	var rebuild = *ctx
+	rebuild.Artifacts = artifact.New()
+	rebuild.BuildCache = false
+	var path = opts.Path
+	opts.Path = filepath.Join(dir, opts.Name)
+	log.WithField("binary", path).Info("verifying build is reproducible")
+	if err := doBuild(&rebuild, build, opts); err != nil {
+		return fmt.Errorf("failed to rebuild %s: %w", opts.Target, err)
+	}
+
+	expected, err := checksum(path)
+	if err != nil {
+		return err
+	}
+	actual, err := checksum(opts.Path)
+	if err != nil {
+		return err
+	}
+	if expected != actual {
+		return fmt.Errorf("build for %s is not reproducible: %s has sha256 %s, but it was %s when rebuilt", opts.Target, path, expected, actual)
+	}
+	return nil
+}
+
+func checksum(path string) (string, error) {
+	return artifact.Artifact{Path: path}.Checksum("sha256")
+}
+
 func buildOptionsForTarget(ctx *context.Context, build config.Build, target string) (*builders.Options, error) {
 	var ext = extFor(target, build.Flags)
 
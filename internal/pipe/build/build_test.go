@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
@@ -18,7 +19,8 @@ import (
 )
 
 type fakeBuilder struct {
-	fail bool
+	fail   bool
+	random bool
 }
 
 func (*fakeBuilder) WithDefaults(build config.Build) config.Build {
@@ -34,7 +36,11 @@ func (f *fakeBuilder) Build(ctx *context.Context, build config.Build, options ap
 	if err := os.MkdirAll(filepath.Dir(options.Path), 0755); err != nil {
 		return err
 	}
-	if err := ioutil.WriteFile(options.Path, []byte("foo"), 0755); err != nil {
+	var content = []byte("foo")
+	if f.random {
+		content = []byte(time.Now().String())
+	}
+	if err := ioutil.WriteFile(options.Path, content, 0755); err != nil {
 		return err
 	}
 	ctx.Artifacts.Add(&artifact.Artifact{
@@ -48,6 +54,9 @@ func init() {
 	api.Register("fakeFail", &fakeBuilder{
 		fail: true,
 	})
+	api.Register("fakeRandom", &fakeBuilder{
+		random: true,
+	})
 }
 
 func TestPipeDescription(t *testing.T) {
@@ -106,6 +115,47 @@ func TestRunPipe(t *testing.T) {
 	}})
 }
 
+func TestRunPipeVerify(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist:         folder,
+		Reproducible: config.Reproducible{Enabled: true, Verify: true},
+		Builds: []config.Build{
+			{
+				Lang:    "fake",
+				Binary:  "testing",
+				Targets: []string{"whatever"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "2.4.5"
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Equal(t, ctx.Artifacts.List(), []*artifact.Artifact{{
+		Name: "testing",
+	}})
+}
+
+func TestRunPipeVerifyNotReproducible(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist:         folder,
+		Reproducible: config.Reproducible{Enabled: true, Verify: true},
+		Builds: []config.Build{
+			{
+				Lang:    "fakeRandom",
+				Binary:  "testing",
+				Targets: []string{"whatever"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "2.4.5"
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "build for whatever is not reproducible")
+}
+
 func TestRunFullPipe(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
@@ -289,6 +339,58 @@ func TestSeveralBuildsWithTheSameID(t *testing.T) {
 	require.EqualError(t, Pipe{}.Default(ctx), "found 2 builds with the ID 'a', please fix your config")
 }
 
+func TestDefaultInvalidLang(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Builds: []config.Build{
+				{
+					ID:   "a",
+					Lang: "cobol",
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid lang: cobol: no builder registered for it")
+}
+
+func TestDefaultNoTargets(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Builds: []config.Build{
+				{
+					ID:   "a",
+					Lang: "prebuilt",
+				},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "build a has no targets")
+}
+
+func TestDefaultPrebuilt(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			ProjectName: "foo",
+			Builds: []config.Build{
+				{
+					ID:      "a",
+					Lang:    "prebuilt",
+					Targets: []string{"linux_amd64"},
+					Prebuilt: config.PrebuiltBuild{
+						Path: "target/{{ .Target }}/a",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+	var build = ctx.Config.Builds[0]
+	require.Equal(t, "foo", build.Binary)
+	require.Equal(t, ".", build.Dir)
+	require.Equal(t, []string{"linux_amd64"}, build.Targets)
+	require.Empty(t, build.Ldflags)
+}
+
 func TestDefaultPartialBuilds(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -0,0 +1,240 @@
+package build
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// scheduler builds the targets of several builds at once.
+//
+// Each build starts once all the builds it depends on are done, and runs at
+// most its parallelism targets at a time, while all builds together run at
+// most ctx.Parallelism targets at a time.
+// The first target to fail cancels the context, which kills the ones in
+// flight, and nothing else starts after it.
+type scheduler struct {
+	ctx    *context.Context
+	cancel stdctx.CancelFunc
+	sem    chan bool
+	done   map[string][]chan bool
+
+	lock      sync.Mutex
+	failures  []failure
+	cancelled int
+}
+
+type failure struct {
+	id, target string
+	err        error
+	cancelled  bool
+}
+
+// schedule builds all targets of the given builds, and returns the error of
+// the failed target, or a summary of all of them if several failed.
+func schedule(ctx *context.Context, builds []config.Build) error {
+	if err := checkDependencies(builds); err != nil {
+		return err
+	}
+
+	// go builds and hooks run with exec.CommandContext, so cancelling the
+	// context kills them.
+	var parent = ctx.Context
+	cctx, cancel := stdctx.WithCancel(parent)
+	ctx.Context = cctx
+	defer func() {
+		cancel()
+		ctx.Context = parent
+	}()
+
+	var s = &scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+		sem:    make(chan bool, parallelism(ctx.Parallelism)),
+		done:   map[string][]chan bool{},
+	}
+	var done = make([]chan bool, len(builds))
+	for i, build := range builds {
+		done[i] = make(chan bool)
+		s.done[build.ID] = append(s.done[build.ID], done[i])
+	}
+	var wg sync.WaitGroup
+	for i, build := range builds {
+		build := build
+		done := done[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done)
+			if build.Skip {
+				return
+			}
+			s.runBuild(build)
+		}()
+	}
+	wg.Wait()
+	return s.err()
+}
+
+func (s *scheduler) runBuild(build config.Build) {
+	for _, dep := range build.DependsOn {
+		for _, done := range s.done[dep] {
+			select {
+			case <-done:
+			case <-s.ctx.Done():
+				s.cancelTargets(len(build.Targets))
+				return
+			}
+		}
+	}
+
+	var sem = make(chan bool, parallelism(build.Parallelism, s.ctx.Parallelism))
+	var wg sync.WaitGroup
+	for _, target := range build.Targets {
+		target := target
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !s.acquire(sem) {
+				s.cancelTargets(1)
+				return
+			}
+			defer s.release(sem)
+			if err := buildTarget(s.ctx, build, target); err != nil {
+				s.fail(build.ID, target, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// acquire takes a slot of both the build and the global semaphores, and fails
+// if the context was cancelled while waiting for them.
+func (s *scheduler) acquire(sem chan bool) bool {
+	select {
+	case sem <- true:
+	case <-s.ctx.Done():
+		return false
+	}
+	select {
+	case s.sem <- true:
+	case <-s.ctx.Done():
+		<-sem
+		return false
+	}
+	if s.ctx.Err() != nil {
+		s.release(sem)
+		return false
+	}
+	return true
+}
+
+func (s *scheduler) release(sem chan bool) {
+	<-s.sem
+	<-sem
+}
+
+func (s *scheduler) fail(id, target string, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// targets failing after the first one may have been killed because of it
+	var cancelled = s.ctx.Err() != nil
+	if !cancelled {
+		log.WithError(err).WithField("id", id).WithField("target", target).Error("build failed")
+	}
+	s.failures = append(s.failures, failure{id: id, target: target, err: err, cancelled: cancelled})
+	s.cancel()
+}
+
+func (s *scheduler) cancelTargets(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cancelled += n
+}
+
+func (s *scheduler) err() error {
+	if len(s.failures) == 0 {
+		// the parent context was cancelled
+		return s.ctx.Err()
+	}
+	if s.cancelled > 0 {
+		log.Warnf("%d targets were not built because of the failure", s.cancelled)
+	}
+	if len(s.failures) == 1 {
+		return s.failures[0].err
+	}
+	// failures are in the order they happened, so the first one is the cause
+	var lines = make([]string, 0, len(s.failures))
+	for _, f := range s.failures {
+		var line = fmt.Sprintf("  %s %s: %s", f.id, f.target, f.err.Error())
+		if f.cancelled {
+			line += " (after cancellation)"
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Errorf("%d targets failed to build:\n%s", len(s.failures), strings.Join(lines, "\n"))
+}
+
+// checkDependencies checks all builds depend on builds that exist, and that
+// there are no cycles among them.
+func checkDependencies(builds []config.Build) error {
+	var deps = map[string][]string{}
+	for _, build := range builds {
+		deps[build.ID] = build.DependsOn
+	}
+	for _, build := range builds {
+		for _, dep := range build.DependsOn {
+			if _, ok := deps[dep]; !ok {
+				return fmt.Errorf("build %s depends on %s, which doesn't exist", build.ID, dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var state = map[string]int{}
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return errors.New("builds depend on each other: " + strings.Join(append(path, id), " -> "))
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range deps[id] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, build := range builds {
+		if err := visit(build.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parallelism is the first of the given limits which is set.
+func parallelism(limits ...int) int {
+	for _, limit := range limits {
+		if limit > 0 {
+			return limit
+		}
+	}
+	return 1
+}
@@ -0,0 +1,237 @@
+package build
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	api "github.com/goreleaser/goreleaser/pkg/build"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+// recordBuilder records the targets it builds, and how many of them it builds
+// at once. Targets named fail fail, and the ones named slow take 10 seconds.
+type recordBuilder struct {
+	lock    sync.Mutex
+	running int
+	max     int
+	events  []string
+}
+
+var recorder = &recordBuilder{}
+
+func init() {
+	api.Register("fakeRecord", recorder)
+}
+
+func (*recordBuilder) WithDefaults(build config.Build) config.Build {
+	return build
+}
+
+func (r *recordBuilder) Build(ctx *context.Context, build config.Build, options api.Options) error {
+	r.lock.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
+	}
+	r.events = append(r.events, "start "+build.ID+" "+options.Target)
+	r.lock.Unlock()
+
+	defer func() {
+		r.lock.Lock()
+		r.running--
+		r.events = append(r.events, "end "+build.ID+" "+options.Target)
+		r.lock.Unlock()
+	}()
+
+	var wait = 20 * time.Millisecond
+	if options.Target == "slow" {
+		wait = 10 * time.Second
+	}
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if options.Target == "fail" {
+		return errors.New("fake failure")
+	}
+	return nil
+}
+
+func (r *recordBuilder) reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.running = 0
+	r.max = 0
+	r.events = nil
+}
+
+func (r *recordBuilder) index(event string) int {
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestScheduleDependencies(t *testing.T) {
+	recorder.reset()
+	var ctx = context.New(config.Project{
+		Dist: t.TempDir(),
+		Builds: []config.Build{
+			{ID: "b", Lang: "fakeRecord", Binary: "b", Targets: []string{"t1"}, DependsOn: []string{"a"}},
+			{ID: "a", Lang: "fakeRecord", Binary: "a", Targets: []string{"t1", "t2"}},
+			{ID: "c", Lang: "fakeRecord", Binary: "c", Targets: []string{"t1"}, DependsOn: []string{"a", "b"}},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, recorder.events, 8)
+	for _, dep := range []string{"end a t1", "end a t2"} {
+		require.Less(t, recorder.index(dep), recorder.index("start b t1"))
+		require.Less(t, recorder.index(dep), recorder.index("start c t1"))
+	}
+	require.Less(t, recorder.index("end b t1"), recorder.index("start c t1"))
+}
+
+func TestScheduleParallelism(t *testing.T) {
+	var targets = []string{"t1", "t2", "t3", "t4"}
+	t.Run("build", func(t *testing.T) {
+		recorder.reset()
+		var ctx = context.New(config.Project{
+			Dist: t.TempDir(),
+			Builds: []config.Build{
+				{ID: "a", Lang: "fakeRecord", Binary: "a", Targets: targets, Parallelism: 1},
+			},
+		})
+		ctx.Parallelism = 4
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Equal(t, 1, recorder.max)
+	})
+
+	t.Run("global", func(t *testing.T) {
+		recorder.reset()
+		var ctx = context.New(config.Project{
+			Dist: t.TempDir(),
+			Builds: []config.Build{
+				{ID: "a", Lang: "fakeRecord", Binary: "a", Targets: targets},
+				{ID: "b", Lang: "fakeRecord", Binary: "b", Targets: targets, Parallelism: 4},
+			},
+		})
+		ctx.Parallelism = 2
+		require.NoError(t, Pipe{}.Run(ctx))
+		require.Equal(t, 2, recorder.max)
+		require.Len(t, recorder.events, 16)
+	})
+}
+
+func TestScheduleFailFast(t *testing.T) {
+	recorder.reset()
+	var ctx = context.New(config.Project{
+		Dist: t.TempDir(),
+		Builds: []config.Build{
+			{ID: "a", Lang: "fakeRecord", Binary: "a", Targets: []string{"fail"}},
+			{ID: "b", Lang: "fakeRecord", Binary: "b", Targets: []string{"slow"}},
+			{ID: "c", Lang: "fakeRecord", Binary: "c", Targets: []string{"t1"}, DependsOn: []string{"b"}},
+		},
+	})
+	var start = time.Now()
+	require.EqualError(t, Pipe{}.Run(ctx), "2 targets failed to build:\n  a fail: fake failure\n  b slow: context canceled (after cancellation)")
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	require.Equal(t, -1, recorder.index("start c t1"))
+	require.NoError(t, ctx.Err())
+}
+
+func TestScheduleFailFastKillsCommands(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Builds: []config.Build{
+			{
+				ID:      "a",
+				Lang:    "fakeFail",
+				Binary:  "a",
+				Targets: []string{"linux_amd64"},
+				Hooks: config.HookConfig{
+					Pre: []config.BuildHook{{Cmd: "sleep 1"}},
+				},
+			},
+			{
+				ID:      "b",
+				Lang:    "fake",
+				Binary:  "b",
+				Targets: []string{"linux_amd64"},
+				Hooks: config.HookConfig{
+					Pre: []config.BuildHook{{Cmd: "sleep 10"}},
+				},
+			},
+		},
+	})
+	var start = time.Now()
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "  a linux_amd64: fake builder failed\n")
+	require.Contains(t, err.Error(), "b linux_amd64: pre hook failed: ")
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestScheduleSeveralFailures(t *testing.T) {
+	recorder.reset()
+	var ctx = context.New(config.Project{
+		Dist: t.TempDir(),
+		Builds: []config.Build{
+			{ID: "a", Lang: "fakeRecord", Binary: "a", Targets: []string{"fail"}},
+			{ID: "b", Lang: "fakeRecord", Binary: "b", Targets: []string{"fail"}},
+		},
+	})
+	var err = Pipe{}.Run(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "2 targets failed to build:\n")
+	require.Contains(t, err.Error(), "  a fail: ")
+	require.Contains(t, err.Error(), "  b fail: ")
+}
+
+func TestDefaultDependencies(t *testing.T) {
+	for name, tt := range map[string]struct {
+		builds []config.Build
+		err    string
+	}{
+		"unknown": {
+			builds: []config.Build{
+				{ID: "a", DependsOn: []string{"nope"}},
+			},
+			err: "build a depends on nope, which doesn't exist",
+		},
+		"self": {
+			builds: []config.Build{
+				{ID: "a", DependsOn: []string{"a"}},
+			},
+			err: "builds depend on each other: a -> a",
+		},
+		"cycle": {
+			builds: []config.Build{
+				{ID: "a", DependsOn: []string{"b"}},
+				{ID: "b", DependsOn: []string{"c"}},
+				{ID: "c", DependsOn: []string{"a"}},
+			},
+			err: "builds depend on each other: a -> b -> c -> a",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			for i := range tt.builds {
+				tt.builds[i].Lang = "fake"
+				tt.builds[i].Targets = []string{"linux_amd64"}
+			}
+			var ctx = context.New(config.Project{Builds: tt.builds})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+			require.EqualError(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+}
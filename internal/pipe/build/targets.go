@@ -0,0 +1,96 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// selectBuilds skips the builds and targets which were not selected with
+// --id, --targets or --single-target.
+func selectBuilds(ctx *context.Context) error {
+	var builds = ctx.Config.Builds
+	if len(ctx.BuildIDs) > 0 {
+		var selected, err = withDependencies(builds, ctx.BuildIDs)
+		if err != nil {
+			return err
+		}
+		for i := range builds {
+			if !selected[builds[i].ID] {
+				builds[i].Skip = true
+			}
+		}
+	}
+
+	if len(ctx.Targets) == 0 {
+		return nil
+	}
+	var found bool
+	for i := range builds {
+		var build = &builds[i]
+		if build.Skip {
+			continue
+		}
+		var targets = selectTargets(build.Targets, ctx.Targets, ctx.SingleTarget)
+		if len(targets) == 0 {
+			log.WithField("id", build.ID).Info("no targets selected, skipping")
+			build.Skip = true
+			continue
+		}
+		build.Targets = targets
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no builds for the targets %s", strings.Join(ctx.Targets, ", "))
+	}
+	return nil
+}
+
+// withDependencies gives the given build ids, and the ones of all the builds
+// they depend on.
+func withDependencies(builds []config.Build, ids []string) (map[string]bool, error) {
+	var deps = map[string][]string{}
+	for _, build := range builds {
+		deps[build.ID] = append(deps[build.ID], build.DependsOn...)
+	}
+	var selected = map[string]bool{}
+	var visit func(id string)
+	visit = func(id string) {
+		if selected[id] {
+			return
+		}
+		selected[id] = true
+		for _, dep := range deps[id] {
+			visit(dep)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := deps[id]; !ok {
+			return nil, fmt.Errorf("no builds with id %s", id)
+		}
+		visit(id)
+	}
+	return selected, nil
+}
+
+// selectTargets gives the targets matching any of the given ones, either
+// exactly or ignoring their arm or mips version, e.g. linux_arm_7 matches
+// linux_arm. With single, it gives only the first one.
+func selectTargets(targets, selection []string, single bool) []string {
+	var result []string
+	for _, target := range targets {
+		for _, s := range selection {
+			if target == s || strings.HasPrefix(target, s+"_") {
+				result = append(result, target)
+				break
+			}
+		}
+		if single && len(result) > 0 {
+			break
+		}
+	}
+	return result
+}
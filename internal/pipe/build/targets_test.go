@@ -0,0 +1,84 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTargets(t *testing.T) {
+	var targets = []string{"linux_amd64", "linux_arm_6", "linux_arm_7", "darwin_arm64"}
+	require.Equal(t, []string{"linux_amd64", "darwin_arm64"}, selectTargets(targets, []string{"darwin_arm64", "linux_amd64"}, false))
+	require.Equal(t, []string{"linux_arm_6", "linux_arm_7"}, selectTargets(targets, []string{"linux_arm"}, false))
+	require.Equal(t, []string{"linux_arm_7"}, selectTargets(targets, []string{"linux_arm_7"}, false))
+	require.Equal(t, []string{"linux_arm_6"}, selectTargets(targets, []string{"linux_arm"}, true))
+	require.Empty(t, selectTargets(targets, []string{"windows_amd64"}, true))
+}
+
+func TestDefaultSelectBuilds(t *testing.T) {
+	var builds = func() []config.Build {
+		return []config.Build{
+			{ID: "plugins", Lang: "fake", Targets: []string{"linux_amd64", "linux_arm_7", "darwin_arm64"}},
+			{ID: "cli", Lang: "fake", Targets: []string{"linux_amd64", "linux_arm_7", "darwin_arm64"}, DependsOn: []string{"plugins"}},
+			{ID: "server", Lang: "fake", Targets: []string{"linux_amd64", "linux_arm_7"}},
+		}
+	}
+	var skipped = func(ctx *context.Context) []string {
+		var result []string
+		for _, build := range ctx.Config.Builds {
+			if build.Skip {
+				result = append(result, build.ID)
+			}
+		}
+		return result
+	}
+
+	t.Run("all", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Empty(t, skipped(ctx))
+		require.False(t, ctx.Partial())
+	})
+
+	t.Run("ids", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		ctx.BuildIDs = []string{"cli"}
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(t, []string{"server"}, skipped(ctx))
+		require.True(t, ctx.Partial())
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		ctx.BuildIDs = []string{"nope"}
+		require.EqualError(t, Pipe{}.Default(ctx), "no builds with id nope")
+	})
+
+	t.Run("targets", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		ctx.Targets = []string{"darwin_arm64", "linux_arm"}
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Empty(t, skipped(ctx))
+		require.Equal(t, []string{"linux_arm_7", "darwin_arm64"}, ctx.Config.Builds[0].Targets)
+		require.Equal(t, []string{"linux_arm_7"}, ctx.Config.Builds[2].Targets)
+	})
+
+	t.Run("single target", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		ctx.SingleTarget = true
+		ctx.Targets = []string{"darwin_arm64"}
+		require.NoError(t, Pipe{}.Default(ctx))
+		require.Equal(t, []string{"server"}, skipped(ctx))
+		require.Equal(t, []string{"darwin_arm64"}, ctx.Config.Builds[0].Targets)
+		require.Equal(t, []string{"darwin_arm64"}, ctx.Config.Builds[1].Targets)
+	})
+
+	t.Run("ids and targets", func(t *testing.T) {
+		var ctx = context.New(config.Project{Builds: builds()})
+		ctx.BuildIDs = []string{"server"}
+		ctx.Targets = []string{"darwin_arm64"}
+		require.EqualError(t, Pipe{}.Default(ctx), "no builds for the targets darwin_arm64")
+	})
+}
@@ -32,6 +32,9 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.Checksum.Algorithm == "" {
 		ctx.Config.Checksum.Algorithm = "sha256"
 	}
+	if ctx.Config.Checksum.DockerDigestsNameTemplate == "" {
+		ctx.Config.Checksum.DockerDigestsNameTemplate = "{{ .ProjectName }}_{{ .Version }}_docker_digests.txt"
+	}
 	return nil
 }
 
@@ -46,6 +49,8 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 			artifact.ByType(artifact.UploadableBinary),
 			artifact.ByType(artifact.UploadableSourceArchive),
 			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByType(artifact.WindowsInstaller),
+			artifact.ByType(artifact.SBOM),
 		),
 	).List()
 	if len(artifactList) == 0 {
@@ -104,5 +109,6 @@ func checksums(algorithm string, artifact *artifact.Artifact) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	artifact.SetExtra("Checksum", algorithm+":"+sha)
 	return fmt.Sprintf("%v  %v\n", sha, artifact.Name), nil
 }
@@ -55,6 +55,9 @@ func TestPipe(t *testing.T) {
 	require.NoError(t, err)
 	require.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary")
 	require.Contains(t, string(bts), "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary.tar.gz")
+	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List() {
+		require.Equal(t, "sha256:61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc", a.ExtraOr("Checksum", ""))
+	}
 }
 
 func TestPipeSkipTrue(t *testing.T) {
@@ -180,6 +183,11 @@ func TestDefault(t *testing.T) {
 		ctx.Config.Checksum.NameTemplate,
 	)
 	require.Equal(t, "sha256", ctx.Config.Checksum.Algorithm)
+	require.Equal(
+		t,
+		"{{ .ProjectName }}_{{ .Version }}_docker_digests.txt",
+		ctx.Config.Checksum.DockerDigestsNameTemplate,
+	)
 }
 
 func TestDefaultSet(t *testing.T) {
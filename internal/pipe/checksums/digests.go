@@ -0,0 +1,64 @@
+package checksums
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// DockerDigestsPipe is a publisher that writes the digests of the pushed
+// docker images and manifests to a checksums-like file, so it can be
+// released along with the other checksums.
+type DockerDigestsPipe struct{}
+
+func (DockerDigestsPipe) String() string {
+	return "docker digests"
+}
+
+// Publish writes the docker digests file.
+func (DockerDigestsPipe) Publish(ctx *context.Context) error {
+	if ctx.Config.Checksum.Disable {
+		return pipe.Skip("checksum.disable is set")
+	}
+	// nolint:prealloc
+	var lines []string
+	for _, a := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.DockerImage),
+		artifact.ByType(artifact.DockerManifest),
+	)).List() {
+		var digest = a.ExtraOr("Digest", "").(string)
+		if digest == "" {
+			log.WithField("image", a.Name).Warn("no digest, skipping")
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%v  %v\n", digest, a.Name))
+	}
+	if len(lines) == 0 {
+		return pipe.Skip("no docker images with digests")
+	}
+
+	filename, err := tmpl.New(ctx).Apply(ctx.Config.Checksum.DockerDigestsNameTemplate)
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, filename)
+	// sort to ensure the file is deterministic
+	sort.Strings(lines)
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "")), 0644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Checksum,
+		Path: path,
+		Name: filename,
+	})
+	return nil
+}
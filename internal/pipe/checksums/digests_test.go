@@ -0,0 +1,75 @@
+package checksums
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerDigests(t *testing.T) {
+	var folder = t.TempDir()
+	var ctx = context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+	})
+	ctx.Version = "1.2.3"
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "foo/bar:1.2.3-arm64",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": "sha256:def"},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "foo/bar:1.2.3-amd64",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": "sha256:abc"},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "foo/bar:latest-amd64",
+		Type: artifact.DockerImage,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "foo/bar:1.2.3",
+		Type:  artifact.DockerManifest,
+		Extra: map[string]interface{}{"Digest": "sha256:123"},
+	})
+	require.NoError(t, DockerDigestsPipe{}.Publish(ctx))
+
+	var checksums = ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+	require.Len(t, checksums, 1)
+	require.Equal(t, "foo_1.2.3_docker_digests.txt", checksums[0].Name)
+	require.Equal(t, filepath.Join(folder, "foo_1.2.3_docker_digests.txt"), checksums[0].Path)
+	bts, err := ioutil.ReadFile(checksums[0].Path)
+	require.NoError(t, err)
+	require.Equal(t, `sha256:123  foo/bar:1.2.3
+sha256:abc  foo/bar:1.2.3-amd64
+sha256:def  foo/bar:1.2.3-arm64
+`, string(bts))
+}
+
+func TestDockerDigestsSkip(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			Checksum: config.Checksum{Disable: true},
+		})
+		testlib.AssertSkipped(t, DockerDigestsPipe{}.Publish(ctx))
+	})
+	t.Run("no digests", func(t *testing.T) {
+		var ctx = context.New(config.Project{})
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: "foo/bar:latest",
+			Type: artifact.DockerImage,
+		})
+		testlib.AssertSkipped(t, DockerDigestsPipe{}.Publish(ctx))
+	})
+}
+
+func TestDockerDigestsDescription(t *testing.T) {
+	require.NotEmpty(t, DockerDigestsPipe{}.String())
+}
@@ -63,8 +63,8 @@ func TestFillPartial(t *testing.T) {
 			},
 			Archives: []config.Archive{
 				{
-					Files: []string{
-						"glob/*",
+					Files: []config.File{
+						{Source: "glob/*"},
 					},
 				},
 			},
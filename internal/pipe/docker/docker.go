@@ -8,10 +8,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
 	"github.com/goreleaser/goreleaser/internal/pipe"
 	"github.com/goreleaser/goreleaser/internal/semerrgroup"
 	"github.com/goreleaser/goreleaser/internal/tmpl"
@@ -31,8 +33,12 @@ func (Pipe) String() string {
 
 // Default sets the pipe defaults.
 func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("dockers")
 	for i := range ctx.Config.Dockers {
 		var docker = &ctx.Config.Dockers[i]
+		if docker.ID != "" {
+			ids.Inc(docker.ID)
+		}
 
 		if docker.Goos == "" {
 			docker.Goos = "linux"
@@ -40,12 +46,21 @@ func (Pipe) Default(ctx *context.Context) error {
 		if docker.Goarch == "" {
 			docker.Goarch = "amd64"
 		}
+		if docker.Use == "" {
+			docker.Use = UseDocker
+		}
+		if docker.Use != UseDocker && docker.Use != UseNative {
+			return fmt.Errorf("invalid docker.use: %s: should be either %s or %s", docker.Use, UseDocker, UseNative)
+		}
 		for _, f := range docker.Files {
 			if f == "." || strings.HasPrefix(f, ctx.Config.Dist) {
 				return fmt.Errorf("invalid docker.files: can't be . or inside dist folder: %s", f)
 			}
 		}
 	}
+	if err := ids.Validate(); err != nil {
+		return err
+	}
 	// only set defaults if there is exactly 1 docker setup in the config file.
 	if len(ctx.Config.Dockers) != 1 {
 		return nil
@@ -55,7 +70,7 @@ func (Pipe) Default(ctx *context.Context) error {
 			ctx.Config.Builds[0].Binary,
 		}
 	}
-	if ctx.Config.Dockers[0].Dockerfile == "" {
+	if ctx.Config.Dockers[0].Dockerfile == "" && ctx.Config.Dockers[0].Use == UseDocker {
 		ctx.Config.Dockers[0].Dockerfile = "Dockerfile"
 	}
 	return nil
@@ -66,9 +81,13 @@ func (Pipe) Run(ctx *context.Context) error {
 	if len(ctx.Config.Dockers) == 0 || len(ctx.Config.Dockers[0].ImageTemplates) == 0 {
 		return pipe.Skip("docker section is not configured")
 	}
-	_, err := exec.LookPath("docker")
-	if err != nil {
-		return ErrNoDocker
+	for _, docker := range ctx.Config.Dockers {
+		if docker.Use == UseNative {
+			continue
+		}
+		if _, err := exec.LookPath("docker"); err != nil {
+			return ErrNoDocker
+		}
 	}
 	return doRun(ctx)
 }
@@ -80,7 +99,7 @@ func (Pipe) Publish(ctx *context.Context) error {
 	}
 	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
 	for _, image := range images {
-		if err := dockerPush(ctx, image); err != nil {
+		if err := push(ctx, image); err != nil {
 			return err
 		}
 	}
@@ -137,40 +156,25 @@ func doRun(ctx *context.Context) error {
 }
 
 func process(ctx *context.Context, docker config.Docker, bins []*artifact.Artifact) error {
-	tmp, err := ioutil.TempDir(ctx.Config.Dist, "goreleaserdocker")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary dir: %w", err)
-	}
-	log.Debug("tempdir: " + tmp)
-
 	images, err := processImageTemplates(ctx, docker)
 	if err != nil {
 		return err
 	}
 
-	if err := os.Link(docker.Dockerfile, filepath.Join(tmp, "Dockerfile")); err != nil {
-		return fmt.Errorf("failed to link dockerfile: %w", err)
+	var extra = map[string]interface{}{}
+	if docker.ID != "" {
+		extra["ID"] = docker.ID
 	}
-	for _, file := range docker.Files {
-		if err := os.MkdirAll(filepath.Join(tmp, filepath.Dir(file)), 0755); err != nil {
-			return fmt.Errorf("failed to link extra file '%s': %w", file, err)
-		}
-		if err := link(file, filepath.Join(tmp, file)); err != nil {
-			return fmt.Errorf("failed to link extra file '%s': %w", file, err)
+	if docker.Use == UseNative {
+		layout, err := nativeBuild(ctx, docker, images, bins)
+		if err != nil {
+			return err
 		}
-	}
-	for _, bin := range bins {
-		if err := os.Link(bin.Path, filepath.Join(tmp, filepath.Base(bin.Path))); err != nil {
-			return fmt.Errorf("failed to link binary: %w", err)
+		extra["OCILayout"] = layout
+		if docker.Insecure {
+			extra["Insecure"] = true
 		}
-	}
-
-	buildFlags, err := processBuildFlagTemplates(ctx, docker)
-	if err != nil {
-		return err
-	}
-
-	if err := dockerBuild(ctx, tmp, images, buildFlags); err != nil {
+	} else if err := build(ctx, docker, images, bins); err != nil {
 		return err
 	}
 
@@ -194,11 +198,45 @@ func process(ctx *context.Context, docker config.Docker, bins []*artifact.Artifa
 			Goarch: docker.Goarch,
 			Goos:   docker.Goos,
 			Goarm:  docker.Goarm,
+			Extra:  extra,
 		})
 	}
 	return nil
 }
 
+// build builds the images with `docker build`.
+func build(ctx *context.Context, docker config.Docker, images []string, bins []*artifact.Artifact) error {
+	tmp, err := ioutil.TempDir(ctx.Config.Dist, "goreleaserdocker")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary dir: %w", err)
+	}
+	log.Debug("tempdir: " + tmp)
+
+	if err := os.Link(docker.Dockerfile, filepath.Join(tmp, "Dockerfile")); err != nil {
+		return fmt.Errorf("failed to link dockerfile: %w", err)
+	}
+	for _, file := range docker.Files {
+		if err := os.MkdirAll(filepath.Join(tmp, filepath.Dir(file)), 0755); err != nil {
+			return fmt.Errorf("failed to link extra file '%s': %w", file, err)
+		}
+		if err := link(file, filepath.Join(tmp, file)); err != nil {
+			return fmt.Errorf("failed to link extra file '%s': %w", file, err)
+		}
+	}
+	for _, bin := range bins {
+		if err := os.Link(bin.Path, filepath.Join(tmp, filepath.Base(bin.Path))); err != nil {
+			return fmt.Errorf("failed to link binary: %w", err)
+		}
+	}
+
+	buildFlags, err := processBuildFlagTemplates(ctx, docker)
+	if err != nil {
+		return err
+	}
+
+	return dockerBuild(ctx, tmp, images, buildFlags)
+}
+
 func processImageTemplates(ctx *context.Context, docker config.Docker) ([]string, error) {
 	// nolint:prealloc
 	var images []string
@@ -273,16 +311,46 @@ func buildCommand(images, flags []string) []string {
 	return base
 }
 
-func dockerPush(ctx *context.Context, image *artifact.Artifact) error {
-	log.WithField("image", image.Name).Info("pushing docker image")
-	/* #nosec */
-	var cmd = exec.CommandContext(ctx, "docker", "push", image.Name)
-	log.WithField("cmd", cmd.Args).Debug("running")
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to push docker image: \n%s: %w", string(out), err)
+// push pushes the image, with docker or natively depending on how it was
+// built, and records its digest. Images a resumed publish already pushed are
+// not pushed again, but still get their artifact with their digest, which
+// the manifests, digests and signs after them use.
+func push(ctx *context.Context, image *artifact.Artifact) error {
+	var _, native = image.Extra["OCILayout"]
+	var digest string
+	var err error
+	if ctx.Progress.IsUploaded("docker", image.Name) {
+		log.WithField("image", image.Name).Info("already pushed, skipping")
+		if native {
+			digest, err = nativeDigest(image)
+		} else {
+			digest, err = dockerDigest(ctx, image)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		if native {
+			digest, err = nativePush(ctx, image)
+		} else {
+			digest, err = dockerPush(ctx, image)
+		}
+		if err != nil {
+			return err
+		}
+		if err := ctx.Progress.Uploaded("docker", image.Name); err != nil {
+			return err
+		}
+	}
+	var extra = map[string]interface{}{}
+	if id, ok := image.Extra["ID"]; ok {
+		extra["ID"] = id
+	}
+	if digest != "" {
+		extra["Digest"] = digest
+	} else {
+		log.WithField("image", image.Name).Warn("could not find the digest of the pushed image")
 	}
-	log.Debugf("docker push output: \n%s", string(out))
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Type:   artifact.DockerImage,
 		Name:   image.Name,
@@ -290,6 +358,60 @@ func dockerPush(ctx *context.Context, image *artifact.Artifact) error {
 		Goarch: image.Goarch,
 		Goos:   image.Goos,
 		Goarm:  image.Goarm,
+		Extra:  extra,
 	})
 	return nil
 }
+
+var pushDigestRe = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+
+func dockerPush(ctx *context.Context, image *artifact.Artifact) (string, error) {
+	log.WithField("image", image.Name).Info("pushing docker image")
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "docker", "push", image.Name)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to push docker image: \n%s: %w", string(out), err)
+	}
+	log.Debugf("docker push output: \n%s", string(out))
+	return pushDigest(string(out)), nil
+}
+
+// pushDigest finds the digest of the pushed image in the output of
+// `docker push`.
+func pushDigest(out string) string {
+	var match = pushDigestRe.FindStringSubmatch(out)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// dockerDigest finds the digest of an image pushed by a previous run in the
+// repo digests of the local image.
+func dockerDigest(ctx *context.Context, image *artifact.Artifact) (string, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{ range .RepoDigests }}{{ println . }}{{ end }}", image.Name)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect docker image: \n%s: %w", string(out), err)
+	}
+	return repoDigest(image.Name, string(out)), nil
+}
+
+// repoDigest finds the digest of the given image in the given list of repo
+// digests, like `myuser/myimage@sha256:...`.
+func repoDigest(image, repoDigests string) string {
+	var repo = image
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	for _, line := range strings.Split(repoDigests, "\n") {
+		if strings.HasPrefix(line, repo+"@") {
+			return strings.TrimPrefix(strings.TrimSpace(line), repo+"@")
+		}
+	}
+	return ""
+}
@@ -666,6 +666,31 @@ func TestBuildCommand(t *testing.T) {
 	}
 }
 
+func TestPushDigest(t *testing.T) {
+	const digest = "sha256:4a5573037f358b6cdfa2f3e8a9c33a5cf11bcd1675ca72ca76fbe5bd77d0d682"
+	require.Equal(t, digest, pushDigest(`The push refers to repository [docker.io/goreleaser/test]
+5f70bf18a086: Layer already exists
+v1.0.0: digest: `+digest+` size: 528
+`))
+	require.Empty(t, pushDigest("nothing to see here"))
+}
+
+func TestRepoDigest(t *testing.T) {
+	var repoDigests = `goreleaser/other@sha256:1
+localhost:5000/goreleaser/test@sha256:2
+goreleaser/test@sha256:3
+`
+	for image, expected := range map[string]string{
+		"goreleaser/test:v1.0.0":                "sha256:3",
+		"goreleaser/test":                       "sha256:3",
+		"localhost:5000/goreleaser/test:latest": "sha256:2",
+		"localhost:5000/goreleaser/test":        "sha256:2",
+		"goreleaser/nope:latest":                "",
+	} {
+		require.Equal(t, expected, repoDigest(image, repoDigests), image)
+	}
+}
+
 func TestDescription(t *testing.T) {
 	require.NotEmpty(t, Pipe{}.String())
 }
@@ -758,6 +783,20 @@ func TestDefaultNoDockers(t *testing.T) {
 	require.Empty(t, ctx.Config.Dockers)
 }
 
+func TestDefaultDuplicatedIDs(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Dockers: []config.Docker{
+				{ID: "foo"},
+				{ID: "foo"},
+				{},
+				{},
+			},
+		},
+	}
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 dockers with the ID 'foo', please fix your config")
+}
+
 func TestDefaultFilesDot(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
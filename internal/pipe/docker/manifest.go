@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	oci "github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// ManifestPipe is a publisher that groups the published docker images into
+// manifest lists.
+type ManifestPipe struct{}
+
+func (ManifestPipe) String() string {
+	return "docker manifests"
+}
+
+// Default validates the docker manifests configuration.
+func (ManifestPipe) Default(ctx *context.Context) error {
+	var ids = ids.New("docker_manifests")
+	for _, manifest := range ctx.Config.DockerManifests {
+		if manifest.ID != "" {
+			ids.Inc(manifest.ID)
+		}
+		if manifest.NameTemplate == "" {
+			return fmt.Errorf("invalid docker_manifests: name_template cannot be empty")
+		}
+		if len(manifest.ImageTemplates) == 0 {
+			return fmt.Errorf("invalid docker_manifests: %s: image_templates cannot be empty", manifest.NameTemplate)
+		}
+	}
+	return ids.Validate()
+}
+
+// Publish the docker manifests.
+func (ManifestPipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.DockerManifests) == 0 {
+		return pipe.Skip("docker_manifests section is not configured")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+	for _, manifest := range ctx.Config.DockerManifests {
+		if strings.TrimSpace(manifest.SkipPush) == "true" {
+			log.WithField("manifest", manifest.NameTemplate).Info("docker_manifests.skip_push is set, skipping")
+			continue
+		}
+		if strings.TrimSpace(manifest.SkipPush) == "auto" && ctx.Semver.Prerelease != "" {
+			log.WithField("manifest", manifest.NameTemplate).Info("prerelease detected with 'auto' push, skipping")
+			continue
+		}
+		if err := pushManifest(ctx, manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pushManifest(ctx *context.Context, manifest config.DockerManifest) error {
+	name, err := tmpl.New(ctx).Apply(manifest.NameTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to execute manifest name template '%s': %w", manifest.NameTemplate, err)
+	}
+	images, err := processImageTemplates(ctx, config.Docker{ImageTemplates: manifest.ImageTemplates})
+	if err != nil {
+		return err
+	}
+	ref, err := oci.ParseReference(name)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(ref, manifest.Insecure)
+	if err != nil {
+		return err
+	}
+	index, err := manifestList(ctx, client, ref, images)
+	if err != nil {
+		return err
+	}
+	bts, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	if ctx.Progress.IsUploaded("docker_manifest", name) {
+		log.WithField("manifest", name).Info("already pushed, skipping")
+	} else {
+		log.WithField("manifest", name).WithField("images", images).Info("pushing docker manifest")
+		digest, err := client.PushManifest(ctx, ref.Repository, ref.Identifier(), index.MediaType, bts)
+		if err != nil {
+			return fmt.Errorf("failed to push docker manifest: %s: %w", name, err)
+		}
+		log.WithField("manifest", name).WithField("digest", digest).Debug("pushed docker manifest")
+		if err := ctx.Progress.Uploaded("docker_manifest", name); err != nil {
+			return err
+		}
+	}
+
+	var extra = map[string]interface{}{
+		"Images": images,
+		"Digest": oci.Digest(bts),
+	}
+	if manifest.ID != "" {
+		extra["ID"] = manifest.ID
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:  artifact.DockerManifest,
+		Name:  name,
+		Path:  name,
+		Extra: extra,
+	})
+	return nil
+}
+
+// manifestList creates the manifest list of the given images, which should
+// be pushed to the same repository. Their digests and platforms are taken
+// from the registry, so a resumed publish can create it without the images
+// pushed by the previous run.
+func manifestList(ctx *context.Context, client *oci.Client, ref oci.Reference, images []string) (oci.Index, error) {
+	var index = oci.Index{SchemaVersion: 2, MediaType: oci.MediaTypeDockerManifestList}
+	for _, image := range images {
+		imageRef, err := oci.ParseReference(image)
+		if err != nil {
+			return index, err
+		}
+		if imageRef.Registry != ref.Registry || imageRef.Repository != ref.Repository {
+			return index, fmt.Errorf("failed to create docker manifest %s: %s should be in the same repository", ref, image)
+		}
+		bts, mediaType, err := client.Manifest(ctx, imageRef.Repository, imageRef.Identifier(),
+			oci.MediaTypeImageManifest, oci.MediaTypeDockerManifest,
+		)
+		if err != nil {
+			return index, fmt.Errorf("failed to create docker manifest %s: %w", ref, err)
+		}
+		if mediaType != oci.MediaTypeDockerManifest {
+			index.MediaType = oci.MediaTypeImageIndex
+		}
+		var digest = oci.Digest(bts)
+		pushed, err := client.Image(ctx, imageRef.Repository, digest, oci.Platform{})
+		if err != nil {
+			return index, fmt.Errorf("failed to create docker manifest %s: %w", ref, err)
+		}
+		index.Manifests = append(index.Manifests, oci.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest,
+			Size:      int64(len(bts)),
+			Platform: &oci.Platform{
+				OS:           pushed.Config.OS,
+				Architecture: pushed.Config.Architecture,
+				Variant:      pushed.Config.Variant,
+			},
+		})
+	}
+	return index, nil
+}
@@ -0,0 +1,191 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	oci "github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestDescription(t *testing.T) {
+	require.NotEmpty(t, ManifestPipe{}.String())
+}
+
+func TestManifestDefault(t *testing.T) {
+	require.NoError(t, ManifestPipe{}.Default(context.New(config.Project{
+		DockerManifests: []config.DockerManifest{
+			{NameTemplate: "foo", ImageTemplates: []string{"foo:amd64"}},
+		},
+	})))
+	require.EqualError(t, ManifestPipe{}.Default(context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{}},
+	})), "invalid docker_manifests: name_template cannot be empty")
+	require.EqualError(t, ManifestPipe{}.Default(context.New(config.Project{
+		DockerManifests: []config.DockerManifest{{NameTemplate: "foo"}},
+	})), "invalid docker_manifests: foo: image_templates cannot be empty")
+	require.EqualError(t, ManifestPipe{}.Default(context.New(config.Project{
+		DockerManifests: []config.DockerManifest{
+			{ID: "foo", NameTemplate: "foo", ImageTemplates: []string{"foo:amd64"}},
+			{ID: "foo", NameTemplate: "bar", ImageTemplates: []string{"bar:amd64"}},
+		},
+	})), "found 2 docker_manifests with the ID 'foo', please fix your config")
+}
+
+func TestManifestSkip(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		testlib.AssertSkipped(t, ManifestPipe{}.Publish(context.New(config.Project{})))
+	})
+	t.Run("skip publish", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			DockerManifests: []config.DockerManifest{{NameTemplate: "foo"}},
+		})
+		ctx.SkipPublish = true
+		require.Equal(t, pipe.ErrSkipPublishEnabled, ManifestPipe{}.Publish(ctx))
+	})
+	t.Run("skip push", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			DockerManifests: []config.DockerManifest{
+				{NameTemplate: "foo", SkipPush: "true"},
+				{NameTemplate: "bar", SkipPush: "auto"},
+			},
+		})
+		ctx.Semver.Prerelease = "rc1"
+		require.NoError(t, ManifestPipe{}.Publish(ctx))
+		require.Empty(t, ctx.Artifacts.List())
+	})
+}
+
+func manifestContext(t *testing.T, reg *testlib.Registry) *context.Context {
+	var ctx = nativeContext(t, config.Docker{
+		ID:             "amd",
+		Binaries:       []string{"mybin"},
+		ImageTemplates: []string{reg.Host + "/mybin:{{ .Version }}-amd64"},
+	})
+	ctx.Config.Dockers = append(ctx.Config.Dockers, config.Docker{
+		Use:            UseNative,
+		Insecure:       true,
+		Binaries:       []string{"mybin"},
+		Goarch:         "arm64",
+		ImageTemplates: []string{reg.Host + "/mybin:{{ .Version }}-arm64"},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	return ctx
+}
+
+func TestManifestPublish(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = manifestContext(t, reg)
+	ctx.Config.DockerManifests = []config.DockerManifest{
+		{
+			ID:           "multi",
+			NameTemplate: reg.Host + "/mybin:{{ .Version }}",
+			ImageTemplates: []string{
+				reg.Host + "/mybin:{{ .Version }}-amd64",
+				reg.Host + "/mybin:{{ .Version }}-arm64",
+			},
+			Insecure: true,
+		},
+	}
+	require.NoError(t, ManifestPipe{}.Publish(ctx))
+
+	bts, mediaType, ok := reg.Manifest("mybin", "1.0.0")
+	require.True(t, ok)
+	require.Equal(t, oci.MediaTypeImageIndex, mediaType)
+	var index oci.Index
+	require.NoError(t, json.Unmarshal(bts, &index))
+	require.Len(t, index.Manifests, 2)
+	for i, arch := range []string{"amd64", "arm64"} {
+		image, _, ok := reg.Manifest("mybin", "1.0.0-"+arch)
+		require.True(t, ok)
+		require.Equal(t, oci.Descriptor{
+			MediaType: oci.MediaTypeImageManifest,
+			Digest:    oci.Digest(image),
+			Size:      int64(len(image)),
+			Platform:  &oci.Platform{OS: "linux", Architecture: arch},
+		}, index.Manifests[i])
+	}
+
+	var manifests = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List()
+	require.Len(t, manifests, 1)
+	require.Equal(t, reg.Host+"/mybin:1.0.0", manifests[0].Name)
+	require.Equal(t, oci.Digest(bts), manifests[0].Extra["Digest"])
+	require.Equal(t, "multi", manifests[0].Extra["ID"])
+	require.True(t, ctx.Progress.IsUploaded("docker_manifest", reg.Host+"/mybin:1.0.0"))
+
+	var images = ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.DockerImage),
+		artifact.ByIDs("amd"),
+	)).List()
+	require.Len(t, images, 1)
+	require.Equal(t, reg.Host+"/mybin:1.0.0-amd64", images[0].Name)
+}
+
+func TestManifestPublishNotPushed(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = manifestContext(t, reg)
+	ctx.Config.DockerManifests = []config.DockerManifest{
+		{
+			NameTemplate:   reg.Host + "/mybin:latest",
+			ImageTemplates: []string{reg.Host + "/mybin:1.0.0-386"},
+			Insecure:       true,
+		},
+	}
+	var err = ManifestPipe{}.Publish(ctx)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, oci.ErrNotFound), err.Error())
+}
+
+func TestManifestPublishOtherRepository(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = manifestContext(t, reg)
+	ctx.Config.DockerManifests = []config.DockerManifest{
+		{
+			NameTemplate:   reg.Host + "/other:latest",
+			ImageTemplates: []string{reg.Host + "/mybin:1.0.0-amd64"},
+			Insecure:       true,
+		},
+	}
+	require.EqualError(t, ManifestPipe{}.Publish(ctx), "failed to create docker manifest "+reg.Host+"/other:latest: "+reg.Host+"/mybin:1.0.0-amd64 should be in the same repository")
+}
+
+func TestManifestPublishResume(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = manifestContext(t, reg)
+	ctx.Config.DockerManifests = []config.DockerManifest{
+		{
+			NameTemplate:   reg.Host + "/mybin:latest",
+			ImageTemplates: []string{reg.Host + "/mybin:1.0.0-amd64"},
+			Insecure:       true,
+		},
+	}
+
+	t.Run("already pushed", func(t *testing.T) {
+		require.NoError(t, ctx.Progress.Uploaded("docker_manifest", reg.Host+"/mybin:latest"))
+		require.NoError(t, ManifestPipe{}.Publish(ctx))
+		_, _, ok := reg.Manifest("mybin", "latest")
+		require.False(t, ok)
+		require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List(), 1)
+	})
+
+	t.Run("images pushed by a previous run", func(t *testing.T) {
+		// the artifacts of the pushed images are gone with the previous run
+		ctx.Artifacts = artifact.New()
+		ctx.Progress = nil
+		require.NoError(t, ManifestPipe{}.Publish(ctx))
+		bts, _, ok := reg.Manifest("mybin", "latest")
+		require.True(t, ok)
+		var index oci.Index
+		require.NoError(t, json.Unmarshal(bts, &index))
+		require.Len(t, index.Manifests, 1)
+		require.Equal(t, &oci.Platform{OS: "linux", Architecture: "amd64"}, index.Manifests[0].Platform)
+	})
+}
@@ -0,0 +1,360 @@
+package docker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	oci "github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Ways of building docker images.
+const (
+	// UseDocker builds images with `docker build`.
+	UseDocker = "docker"
+	// UseNative builds images without docker, assembling the image layers
+	// into an OCI layout in the dist folder.
+	UseNative = "native"
+)
+
+const dockerLayerMediaType = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+// nativeBuild assembles an image made of the base image, a layer with the
+// binaries and a layer with the extra files, and writes it as an OCI layout
+// in the dist folder, returning its path.
+func nativeBuild(ctx *context.Context, docker config.Docker, images []string, bins []*artifact.Artifact) (string, error) {
+	log.WithField("image", images[0]).Info("building docker image natively")
+	var dir = filepath.Join(ctx.Config.Dist, "oci", strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(images[0]))
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	layout, err := oci.NewLayout(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var platform = oci.Platform{OS: docker.Goos, Architecture: docker.Goarch}
+	if docker.Goarm != "" {
+		platform.Variant = "v" + docker.Goarm
+	}
+	base, err := pullBase(ctx, docker, layout, platform)
+	if err != nil {
+		return "", err
+	}
+
+	var created = ctx.Git.CommitDate.UTC()
+	if ctx.Git.CommitDate.IsZero() {
+		created = time.Unix(0, 0).UTC()
+	}
+	var cfg = base.Config
+	cfg.Created = &created
+	cfg.OS = platform.OS
+	cfg.Architecture = platform.Architecture
+	cfg.Variant = platform.Variant
+	if cfg.RootFS.Type == "" {
+		cfg.RootFS.Type = "layers"
+	}
+	var manifest = oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		Layers:        base.Manifest.Layers,
+	}
+
+	var files = map[string]string{}
+	for _, bin := range bins {
+		files[filepath.Base(bin.Path)] = bin.Path
+	}
+	var extra = map[string]string{}
+	for _, file := range docker.Files {
+		extra[file] = file
+	}
+	for _, layer := range []struct {
+		name  string
+		files map[string]string
+	}{
+		{"binaries", files},
+		{"extra files", extra},
+	} {
+		if len(layer.files) == 0 {
+			continue
+		}
+		desc, diffID, err := writeLayer(layout, layer.files, created)
+		if err != nil {
+			return "", fmt.Errorf("failed to create %s layer: %w", layer.name, err)
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		cfg.RootFS.DiffIDs = append(cfg.RootFS.DiffIDs, diffID)
+		cfg.History = append(cfg.History, oci.History{
+			Created:   &created,
+			CreatedBy: "goreleaser",
+			Comment:   layer.name,
+		})
+	}
+
+	if err := applyContainerConfig(ctx, docker, &cfg.Config, bins); err != nil {
+		return "", err
+	}
+	bts, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	if manifest.Config, err = layout.WriteBlob(oci.MediaTypeImageConfig, bts); err != nil {
+		return "", err
+	}
+	bts, err = json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	desc, err := layout.AddManifest(oci.MediaTypeImageManifest, bts, images...)
+	if err != nil {
+		return "", err
+	}
+	log.WithField("image", images[0]).WithField("digest", desc.Digest).WithField("layout", dir).Debug("built docker image")
+	return dir, nil
+}
+
+// pullBase downloads the base image layers into the layout.
+func pullBase(ctx *context.Context, docker config.Docker, layout oci.Layout, platform oci.Platform) (oci.Image, error) {
+	if docker.BaseImage == "" || docker.BaseImage == "scratch" {
+		return oci.Image{}, nil
+	}
+	ref, err := oci.ParseReference(docker.BaseImage)
+	if err != nil {
+		return oci.Image{}, err
+	}
+	client, err := newClient(ref, docker.Insecure)
+	if err != nil {
+		return oci.Image{}, err
+	}
+	log.WithField("image", ref.String()).Info("pulling base image")
+	image, err := client.Image(ctx, ref.Repository, ref.Identifier(), platform)
+	if err != nil {
+		return image, fmt.Errorf("failed to pull base image %s: %w", docker.BaseImage, err)
+	}
+	for i, layer := range image.Manifest.Layers {
+		if err := layout.CopyBlob(ctx, client, ref.Repository, layer); err != nil {
+			return image, fmt.Errorf("failed to pull base image %s: %w", docker.BaseImage, err)
+		}
+		if layer.MediaType == dockerLayerMediaType {
+			image.Manifest.Layers[i].MediaType = oci.MediaTypeImageLayer
+		}
+	}
+	return image, nil
+}
+
+func applyContainerConfig(ctx *context.Context, docker config.Docker, cfg *oci.ContainerConfig, bins []*artifact.Artifact) error {
+	var template = tmpl.New(ctx)
+	for _, e := range docker.Env {
+		env, err := template.Apply(e)
+		if err != nil {
+			return fmt.Errorf("failed to execute env template '%s': %w", e, err)
+		}
+		cfg.Env = append(cfg.Env, env)
+	}
+	for k, v := range docker.Labels {
+		label, err := template.Apply(v)
+		if err != nil {
+			return fmt.Errorf("failed to execute label template '%s': %w", v, err)
+		}
+		if cfg.Labels == nil {
+			cfg.Labels = map[string]string{}
+		}
+		cfg.Labels[k] = label
+	}
+	if docker.User != "" {
+		cfg.User = docker.User
+	}
+	switch {
+	case len(docker.Entrypoint) > 0:
+		cfg.Entrypoint = docker.Entrypoint
+		cfg.Cmd = nil
+	case len(bins) == 1:
+		cfg.Entrypoint = []string{"/" + filepath.Base(bins[0].Path)}
+		cfg.Cmd = nil
+	}
+	if len(docker.Cmd) > 0 {
+		cfg.Cmd = docker.Cmd
+	}
+	return nil
+}
+
+// writeLayer writes a gzipped tar layer with the given files, keyed by
+// their path inside the image, returning its descriptor and diff id.
+func writeLayer(layout oci.Layout, files map[string]string, mtime time.Time) (oci.Descriptor, string, error) {
+	var desc = oci.Descriptor{MediaType: oci.MediaTypeImageLayer}
+	f, err := ioutil.TempFile(filepath.Join(layout.Path, "blobs", "sha256"), "tmp")
+	if err != nil {
+		return desc, "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var digest = sha256.New()
+	var diffID = sha256.New()
+	var counter = &countWriter{}
+	var gw = gzip.NewWriter(io.MultiWriter(f, digest, counter))
+	var tw = tar.NewWriter(io.MultiWriter(gw, diffID))
+	var dirs = map[string]bool{}
+	for _, name := range sortedKeys(files) {
+		if err := addToLayer(tw, dirs, name, files[name], mtime); err != nil {
+			return desc, "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return desc, "", err
+	}
+	if err := gw.Close(); err != nil {
+		return desc, "", err
+	}
+	if err := f.Close(); err != nil {
+		return desc, "", err
+	}
+	desc.Digest = "sha256:" + hex.EncodeToString(digest.Sum(nil))
+	desc.Size = counter.n
+	if err := os.Rename(f.Name(), layout.BlobPath(desc.Digest)); err != nil {
+		return desc, "", err
+	}
+	return desc, "sha256:" + hex.EncodeToString(diffID.Sum(nil)), nil
+}
+
+// addToLayer adds the file or directory at src to the layer as dst, along
+// with its parent directories.
+func addToLayer(tw *tar.Writer, dirs map[string]bool, dst, src string, mtime time.Time) error {
+	return filepath.Walk(src, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+		var name = path.Clean(path.Join(filepath.ToSlash(dst), filepath.ToSlash(rel)))
+		name = strings.TrimPrefix(name, "/")
+		if err := addParents(tw, dirs, path.Dir(name), mtime); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return addParents(tw, dirs, name, mtime)
+		}
+		log.WithField("file", file).WithField("dst", name).Debug("adding file to layer")
+		var header = &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     int64(info.Mode().Perm()),
+			Size:     info.Size(),
+			ModTime:  mtime,
+			Format:   tar.FormatPAX,
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(file)
+			if err != nil {
+				return err
+			}
+			header.Typeflag = tar.TypeSymlink
+			header.Linkname = filepath.ToSlash(link)
+			header.Size = 0
+			return tw.WriteHeader(header)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := os.Open(file) // #nosec
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+func addParents(tw *tar.Writer, dirs map[string]bool, dir string, mtime time.Time) error {
+	if dir == "." || dir == "" || dirs[dir] {
+		return nil
+	}
+	if err := addParents(tw, dirs, path.Dir(dir), mtime); err != nil {
+		return err
+	}
+	dirs[dir] = true
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+		ModTime:  mtime,
+		Format:   tar.FormatPAX,
+	})
+}
+
+func nativePush(ctx *context.Context, image *artifact.Artifact) (string, error) {
+	ref, err := oci.ParseReference(image.Name)
+	if err != nil {
+		return "", err
+	}
+	client, err := newClient(ref, image.ExtraOr("Insecure", false).(bool))
+	if err != nil {
+		return "", err
+	}
+	log.WithField("image", image.Name).Info("pushing docker image")
+	digest, err := nativeLayout(image).Push(ctx, client, image.Name, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to push docker image: %s: %w", image.Name, err)
+	}
+	log.WithField("image", image.Name).WithField("digest", digest).Debug("pushed docker image")
+	return digest, nil
+}
+
+// nativeDigest returns the digest of a natively built image.
+func nativeDigest(image *artifact.Artifact) (string, error) {
+	desc, err := nativeLayout(image).Descriptor(image.Name)
+	return desc.Digest, err
+}
+
+func nativeLayout(image *artifact.Artifact) oci.Layout {
+	return oci.Layout{Path: image.ExtraOr("OCILayout", "").(string)}
+}
+
+func newClient(ref oci.Reference, insecure bool) (*oci.Client, error) {
+	username, password, err := oci.DockerCredentials(ref.Registry)
+	if err != nil {
+		return nil, err
+	}
+	return oci.New(ref.Host(), oci.Options{
+		Username: username,
+		Password: password,
+		Insecure: insecure,
+	}), nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
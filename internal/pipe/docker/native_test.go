@@ -0,0 +1,307 @@
+package docker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/progress"
+	oci "github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func nativeContext(t *testing.T, docker config.Docker) *context.Context {
+	_, back := testlib.Mktmp(t)
+	t.Cleanup(back)
+	require.NoError(t, os.Mkdir("dist", 0755))
+	require.NoError(t, os.MkdirAll("static/css", 0755))
+	require.NoError(t, ioutil.WriteFile("static/css/main.css", []byte("body{}"), 0644))
+	require.NoError(t, ioutil.WriteFile("config.yml", []byte("foo: bar"), 0644))
+
+	docker.Use = UseNative
+	docker.Insecure = true
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        "dist",
+		Builds:      []config.Build{{Binary: "mybin"}},
+		Dockers:     []config.Docker{docker},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CommitDate = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	ctx.Progress = progress.New("dist")
+	require.NoError(t, Pipe{}.Default(ctx))
+
+	for _, arch := range []string{"amd64", "arm64"} {
+		var path = filepath.Join("dist", "mybin_linux_"+arch, "mybin")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("binary "+arch), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "mybin",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: arch,
+			Type:   artifact.Binary,
+			Extra: map[string]interface{}{
+				"Binary": "mybin",
+				"ID":     "mybin",
+			},
+		})
+	}
+	return ctx
+}
+
+func readImage(t *testing.T, layout oci.Layout, name string) (oci.Manifest, oci.ImageConfig) {
+	index, err := layout.Index()
+	require.NoError(t, err)
+	for _, desc := range index.Manifests {
+		if desc.Annotations[oci.AnnotationRefName] != name {
+			continue
+		}
+		var manifest oci.Manifest
+		var cfg oci.ImageConfig
+		bts, err := ioutil.ReadFile(layout.BlobPath(desc.Digest))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bts, &manifest))
+		bts, err = ioutil.ReadFile(layout.BlobPath(manifest.Config.Digest))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(bts, &cfg))
+		return manifest, cfg
+	}
+	t.Fatalf("%s not found in layout", name)
+	return oci.Manifest{}, oci.ImageConfig{}
+}
+
+func readLayer(t *testing.T, layout oci.Layout, desc oci.Descriptor) map[string]string {
+	f, err := os.Open(layout.BlobPath(desc.Digest))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	var files = map[string]string{}
+	var tr = tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		bts, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC), h.ModTime.UTC())
+		if h.Typeflag == tar.TypeSymlink {
+			files[h.Name] = "-> " + h.Linkname
+			continue
+		}
+		files[h.Name] = string(bts)
+	}
+	return files
+}
+
+func TestNativeBuild(t *testing.T) {
+	var ctx = nativeContext(t, config.Docker{
+		Goarch:         "arm64",
+		ImageTemplates: []string{"ghcr.io/goreleaser/mybin:{{ .Version }}", "ghcr.io/goreleaser/mybin:latest"},
+		Files:          []string{"static", "config.yml"},
+		Env:            []string{"MYBIN_VERSION={{ .Version }}"},
+		Labels: map[string]string{
+			"org.opencontainers.image.version": "{{ .Version }}",
+		},
+		Cmd:  []string{"serve"},
+		User: "nobody",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var images = ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()
+	require.Len(t, images, 2)
+	var layout = oci.Layout{Path: images[0].ExtraOr("OCILayout", "").(string)}
+	require.Equal(t, filepath.Join("dist", "oci", "ghcr.io_goreleaser_mybin_1.0.0"), layout.Path)
+	require.Equal(t, true, images[0].Extra["Insecure"])
+
+	manifest, cfg := readImage(t, layout, "ghcr.io/goreleaser/mybin:1.0.0")
+	latest, _ := readImage(t, layout, "ghcr.io/goreleaser/mybin:latest")
+	require.Equal(t, manifest, latest)
+	require.Equal(t, "linux", cfg.OS)
+	require.Equal(t, "arm64", cfg.Architecture)
+	require.Equal(t, []string{"/mybin"}, cfg.Config.Entrypoint)
+	require.Equal(t, []string{"serve"}, cfg.Config.Cmd)
+	require.Equal(t, []string{"MYBIN_VERSION=1.0.0"}, cfg.Config.Env)
+	require.Equal(t, "nobody", cfg.Config.User)
+	require.Equal(t, map[string]string{"org.opencontainers.image.version": "1.0.0"}, cfg.Config.Labels)
+	require.Len(t, cfg.RootFS.DiffIDs, 2)
+
+	require.Len(t, manifest.Layers, 2)
+	require.Equal(t, map[string]string{
+		"mybin": "binary arm64",
+	}, readLayer(t, layout, manifest.Layers[0]))
+	require.Equal(t, map[string]string{
+		"config.yml":          "foo: bar",
+		"static/":             "",
+		"static/css/":         "",
+		"static/css/main.css": "body{}",
+	}, readLayer(t, layout, manifest.Layers[1]))
+}
+
+func TestNativeBuildSymlinks(t *testing.T) {
+	var ctx = nativeContext(t, config.Docker{
+		ImageTemplates: []string{"mybin"},
+		Files:          []string{"static"},
+	})
+	require.NoError(t, os.Symlink("main.css", filepath.Join("static", "css", "style.css")))
+	require.NoError(t, os.Symlink("css", filepath.Join("static", "styles")))
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var layout = oci.Layout{Path: filepath.Join("dist", "oci", "mybin")}
+	manifest, _ := readImage(t, layout, "mybin")
+	require.Len(t, manifest.Layers, 2)
+	require.Equal(t, map[string]string{
+		"static/":              "",
+		"static/css/":          "",
+		"static/css/main.css":  "body{}",
+		"static/css/style.css": "-> main.css",
+		"static/styles":        "-> css",
+	}, readLayer(t, layout, manifest.Layers[1]))
+}
+
+func TestNativeBuildIsReproducible(t *testing.T) {
+	var ctx = nativeContext(t, config.Docker{
+		ImageTemplates: []string{"mybin"},
+		Files:          []string{"static"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	var layout = oci.Layout{Path: filepath.Join("dist", "oci", "mybin")}
+	first, _ := readImage(t, layout, "mybin")
+
+	ctx.Artifacts = artifact.New()
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "mybin_linux_amd64", "mybin"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "mybin",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	second, _ := readImage(t, layout, "mybin")
+	require.Equal(t, first, second)
+}
+
+func TestNativeBuildWithBaseImage(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var c = oci.New(reg.Host, oci.Options{Insecure: true})
+	var bg = context.New(config.Project{})
+
+	// push a multi-platform base image
+	var base = oci.Index{SchemaVersion: 2, MediaType: oci.MediaTypeImageIndex}
+	for _, arch := range []string{"amd64", "arm64"} {
+		layer, err := c.PushBlob(bg, "base", dockerLayerMediaType, []byte("layer "+arch))
+		require.NoError(t, err)
+		bts, err := json.Marshal(oci.ImageConfig{
+			OS:           "linux",
+			Architecture: arch,
+			Config: oci.ContainerConfig{
+				Env:        []string{"PATH=/bin"},
+				Entrypoint: []string{"/bin/sh"},
+				Cmd:        []string{"-c"},
+			},
+			RootFS: oci.RootFS{Type: "layers", DiffIDs: []string{"sha256:base" + arch}},
+		})
+		require.NoError(t, err)
+		cfg, err := c.PushBlob(bg, "base", oci.MediaTypeImageConfig, bts)
+		require.NoError(t, err)
+		bts, err = json.Marshal(oci.Manifest{
+			SchemaVersion: 2,
+			MediaType:     oci.MediaTypeDockerManifest,
+			Config:        cfg,
+			Layers:        []oci.Descriptor{layer},
+		})
+		require.NoError(t, err)
+		digest, err := c.PushManifest(bg, "base", arch, oci.MediaTypeDockerManifest, bts)
+		require.NoError(t, err)
+		base.Manifests = append(base.Manifests, oci.Descriptor{
+			MediaType: oci.MediaTypeDockerManifest,
+			Digest:    digest,
+			Size:      int64(len(bts)),
+			Platform:  &oci.Platform{OS: "linux", Architecture: arch},
+		})
+	}
+	bts, err := json.Marshal(base)
+	require.NoError(t, err)
+	_, err = c.PushManifest(bg, "base", "latest", oci.MediaTypeImageIndex, bts)
+	require.NoError(t, err)
+
+	var nctx = nativeContext(t, config.Docker{
+		Goarch:         "arm64",
+		BaseImage:      reg.Host + "/base",
+		ImageTemplates: []string{reg.Host + "/mybin:{{ .Version }}"},
+	})
+	require.NoError(t, Pipe{}.Run(nctx))
+
+	var image = nctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()[0]
+	var layout = oci.Layout{Path: image.ExtraOr("OCILayout", "").(string)}
+	manifest, cfg := readImage(t, layout, reg.Host+"/mybin:1.0.0")
+	require.Len(t, manifest.Layers, 2)
+	require.Equal(t, oci.MediaTypeImageLayer, manifest.Layers[0].MediaType)
+	base0, err := ioutil.ReadFile(layout.BlobPath(manifest.Layers[0].Digest))
+	require.NoError(t, err)
+	require.Equal(t, "layer arm64", string(base0))
+	require.Equal(t, "sha256:basearm64", cfg.RootFS.DiffIDs[0])
+	require.Equal(t, []string{"PATH=/bin"}, cfg.Config.Env)
+	require.Equal(t, []string{"/mybin"}, cfg.Config.Entrypoint)
+	require.Empty(t, cfg.Config.Cmd)
+
+	// and push it
+	require.NoError(t, Pipe{}.Publish(nctx))
+	pushed, _, ok := reg.Manifest("mybin", "1.0.0")
+	require.True(t, ok)
+	var pushedManifest oci.Manifest
+	require.NoError(t, json.Unmarshal(pushed, &pushedManifest))
+	require.Equal(t, manifest, pushedManifest)
+	for _, layer := range append(manifest.Layers, manifest.Config) {
+		_, ok := reg.Blob("mybin", layer.Digest)
+		require.True(t, ok, layer.Digest)
+	}
+	var published = nctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, published, 1)
+	require.Equal(t, oci.Digest(pushed), published[0].Extra["Digest"])
+	require.True(t, nctx.Progress.IsUploaded("docker", reg.Host+"/mybin:1.0.0"))
+}
+
+func TestNativeBuildBaseImageNotFound(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = nativeContext(t, config.Docker{
+		BaseImage:      reg.Host + "/nope:1.0",
+		ImageTemplates: []string{"mybin"},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "failed to pull base image "+reg.Host+"/nope:1.0: manifest nope:1.0: not found")
+}
+
+func TestNativeDoesNotNeedDocker(t *testing.T) {
+	var ctx = nativeContext(t, config.Docker{
+		ImageTemplates: []string{"mybin"},
+	})
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	require.NoError(t, Pipe{}.Run(ctx))
+}
+
+func TestDefaultInvalidUse(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{{Use: "podman"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid docker.use: podman: should be either docker or native")
+}
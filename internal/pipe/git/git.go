@@ -32,6 +32,11 @@ func (Pipe) Run(ctx *context.Context) error {
 		return err
 	}
 	ctx.Git = info
+	if ctx.Config.Reproducible.Enabled && !info.CommitDate.IsZero() {
+		// the build date ends up in binaries and archives, so use the one
+		// of the commit to get the same output no matter when we build.
+		ctx.Date = info.CommitDate
+	}
 	log.Infof("releasing %s, commit %s", info.CurrentTag, info.Commit)
 	ctx.Version = strings.TrimPrefix(ctx.Git.CurrentTag, "v")
 	return validate(ctx)
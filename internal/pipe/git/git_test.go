@@ -136,6 +136,22 @@ func TestValidState(t *testing.T) {
 	require.Equal(t, "git@github.com:foo/bar.git", ctx.Git.URL)
 }
 
+func TestReproducibleDate(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	testlib.GitRemoteAdd(t, "git@github.com:foo/bar.git")
+	testlib.GitCommit(t, "commit1")
+	testlib.GitTag(t, "v0.0.1")
+	var ctx = context.New(config.Project{
+		Reproducible: config.Reproducible{Enabled: true},
+	})
+	ctx.Date = time.Now().Add(time.Hour)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.False(t, ctx.Git.CommitDate.IsZero())
+	require.Equal(t, ctx.Git.CommitDate, ctx.Date)
+}
+
 func TestSnapshotNoTags(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
@@ -0,0 +1,92 @@
+// Package metadata provides a pipe that writes machine-readable information
+// about the release and its artifacts to the dist folder.
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	// ArtifactsFilename is the name of the file listing all artifacts.
+	ArtifactsFilename = "artifacts.json"
+	// MetadataFilename is the name of the file containing the release info.
+	MetadataFilename = "metadata.json"
+)
+
+// Pipe that writes the artifacts and metadata files to dist.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "storing release metadata"
+}
+
+// Metadata is the release information written to metadata.json.
+type Metadata struct {
+	ProjectName string    `json:"project_name"`
+	Tag         string    `json:"tag"`
+	Version     string    `json:"version"`
+	Commit      string    `json:"commit"`
+	Date        time.Time `json:"date"`
+	Snapshot    bool      `json:"snapshot"`
+	Semver      Semver    `json:"semver"`
+}
+
+// Semver is the semantic version of the release.
+type Semver struct {
+	Major      uint64 `json:"major"`
+	Minor      uint64 `json:"minor"`
+	Patch      uint64 `json:"patch"`
+	RawVersion string `json:"raw_version"`
+	Prerelease string `json:"prerelease,omitempty"`
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if err := writeArtifacts(ctx); err != nil {
+		return err
+	}
+	return writeMetadata(ctx)
+}
+
+func writeArtifacts(ctx *context.Context) error {
+	return writeJSON(ctx, ArtifactsFilename, ctx.Artifacts.List())
+}
+
+// New returns the metadata of the release being made.
+func New(ctx *context.Context) Metadata {
+	return Metadata{
+		ProjectName: ctx.Config.ProjectName,
+		Tag:         ctx.Git.CurrentTag,
+		Version:     ctx.Version,
+		Commit:      ctx.Git.FullCommit,
+		Date:        ctx.Date.UTC(),
+		Snapshot:    ctx.Snapshot,
+		Semver: Semver{
+			Major:      ctx.Semver.Major,
+			Minor:      ctx.Semver.Minor,
+			Patch:      ctx.Semver.Patch,
+			RawVersion: ctx.Semver.RawVersion,
+			Prerelease: ctx.Semver.Prerelease,
+		},
+	}
+}
+
+func writeMetadata(ctx *context.Context) error {
+	return writeJSON(ctx, MetadataFilename, New(ctx))
+}
+
+func writeJSON(ctx *context.Context, name string, v interface{}) error {
+	var path = filepath.Join(ctx.Config.Dist, name)
+	bts, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	log.WithField("file", path).Info("writing")
+	return ioutil.WriteFile(path, bts, 0644) //nolint: gosec
+}
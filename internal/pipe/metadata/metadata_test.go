@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	require.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        dist,
+	})
+	ctx.Version = "1.2.3-rc1"
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.2.3-rc1",
+		FullCommit: "a1b2c3",
+	}
+	ctx.Semver = context.Semver{
+		Major:      1,
+		Minor:      2,
+		Patch:      3,
+		RawVersion: "1.2.3-rc1",
+		Prerelease: "rc1",
+	}
+	ctx.Date = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	var bin = &artifact.Artifact{
+		Name:   "foo",
+		Path:   "dist/foo_linux_amd64/foo",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "foo",
+		},
+	}
+	ctx.Artifacts.Add(bin)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo_1.2.3-rc1_linux_amd64.tar.gz",
+		Path:   "dist/foo_1.2.3-rc1_linux_amd64.tar.gz",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			"ID":       "default",
+			"Builds":   []*artifact.Artifact{bin},
+			"Checksum": "sha256:deadbeef",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(dist, ArtifactsFilename))
+	require.NoError(t, err)
+	var artifacts []map[string]interface{}
+	require.NoError(t, json.Unmarshal(bts, &artifacts))
+	require.Len(t, artifacts, 2)
+	require.Equal(t, "foo", artifacts[0]["name"])
+	require.Equal(t, "Binary", artifacts[0]["type"])
+	require.Equal(t, "linux", artifacts[0]["goos"])
+	require.Equal(t, "Archive", artifacts[1]["type"])
+	require.Equal(t, float64(artifact.UploadableArchive), artifacts[1]["internal_type"])
+	require.Equal(t, "sha256:deadbeef", artifacts[1]["extra"].(map[string]interface{})["Checksum"])
+
+	bts, err = ioutil.ReadFile(filepath.Join(dist, MetadataFilename))
+	require.NoError(t, err)
+	var metadata Metadata
+	require.NoError(t, json.Unmarshal(bts, &metadata))
+	require.Equal(t, Metadata{
+		ProjectName: "foo",
+		Tag:         "v1.2.3-rc1",
+		Version:     "1.2.3-rc1",
+		Commit:      "a1b2c3",
+		Date:        ctx.Date,
+		Semver: Semver{
+			Major:      1,
+			Minor:      2,
+			Patch:      3,
+			RawVersion: "1.2.3-rc1",
+			Prerelease: "rc1",
+		},
+	}, metadata)
+}
+
+func TestRunInvalidDist(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Dist: "/nope/does/not/exist",
+	})
+	require.Error(t, Pipe{}.Run(ctx))
+}
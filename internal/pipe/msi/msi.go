@@ -0,0 +1,297 @@
+// Package msi implements the Pipe interface creating windows installers with
+// the WiX toolset or msitools.
+package msi
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
+	"github.com/apex/log"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	defaultNameTemplate = "{{ .ProjectName }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}"
+	defaultVersion      = "{{ .Major }}.{{ .Minor }}.{{ .Patch }}"
+)
+
+// nolint: gochecknoglobals
+var guid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// wixArchs are the WiX architectures of the supported goarchs.
+// nolint: gochecknoglobals
+var wixArchs = map[string]string{
+	"386":   "x86",
+	"amd64": "x64",
+	"arm64": "arm64",
+}
+
+// Pipe for msi packaging.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "windows installers"
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("msis")
+	for i := range ctx.Config.MSIs {
+		var msi = &ctx.Config.MSIs[i]
+		if msi.ID == "" {
+			msi.ID = "default"
+		}
+		if msi.FileNameTemplate == "" {
+			msi.FileNameTemplate = defaultNameTemplate
+		}
+		if msi.Product == "" {
+			msi.Product = ctx.Config.ProjectName
+		}
+		if msi.Manufacturer == "" {
+			msi.Manufacturer = msi.Product
+		}
+		if msi.Version == "" {
+			msi.Version = defaultVersion
+		}
+		if msi.InstallDir == "" {
+			msi.InstallDir = msi.Product
+		}
+		if msi.Toolchain == "" {
+			msi.Toolchain = "wixl"
+		}
+		if len(msi.Builds) == 0 {
+			for _, b := range ctx.Config.Builds {
+				msi.Builds = append(msi.Builds, b.ID)
+			}
+		}
+		for j := range msi.Shortcuts {
+			var shortcut = &msi.Shortcuts[j]
+			if shortcut.Location == "" {
+				shortcut.Location = "start_menu"
+			}
+			if shortcut.Location != "start_menu" && shortcut.Location != "desktop" {
+				return fmt.Errorf("invalid msi shortcut location: %s: should be start_menu or desktop", shortcut.Location)
+			}
+			if shortcut.Name == "" {
+				return errors.New("msi shortcuts need a name")
+			}
+		}
+		if !guid.MatchString(msi.UpgradeCode) {
+			return fmt.Errorf("invalid msi upgrade_code: %q: should be a GUID, e.g. 0b8d5c3a-6b1f-4e5e-9a4d-1c2b3d4e5f60", msi.UpgradeCode)
+		}
+		if msi.Toolchain != "wixl" && msi.Toolchain != "wix" {
+			return fmt.Errorf("invalid msi toolchain: %s: should be wixl or wix", msi.Toolchain)
+		}
+		ids.Inc(msi.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.MSIs) == 0 {
+		return pipe.Skip("msi section is not configured")
+	}
+	// check everything before creating any msi, so no wixl or candle is
+	// left running when an error is returned.
+	var platforms = make([]map[string][]*artifact.Artifact, len(ctx.Config.MSIs))
+	for i, msi := range ctx.Config.MSIs {
+		for _, cmd := range commands(msi.Toolchain) {
+			if _, err := exec.LookPath(cmd); err != nil {
+				return fmt.Errorf("%s not present in $PATH", cmd)
+			}
+		}
+		platforms[i] = ctx.Artifacts.Filter(artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByGoos("windows"),
+			artifact.ByIDs(msi.Builds...),
+		)).GroupByPlatform()
+		if len(platforms[i]) == 0 {
+			return fmt.Errorf("no windows binaries found for builds %v", msi.Builds)
+		}
+	}
+
+	var g = semerrgroup.New(ctx.Parallelism)
+	for i, msi := range ctx.Config.MSIs {
+		msi := msi
+		for _, binaries := range platforms[i] {
+			binaries := binaries
+			var arch = binaries[0].Goarch
+			if _, ok := wixArchs[arch]; !ok {
+				log.WithField("arch", arch).Warn("msi doesn't support this arch, skipping")
+				continue
+			}
+			if msi.Toolchain == "wixl" && arch == "arm64" {
+				log.WithField("arch", arch).Warn("wixl doesn't support this arch, use the wix toolchain, skipping")
+				continue
+			}
+			g.Go(func() error {
+				return create(ctx, msi, binaries)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func create(ctx *context.Context, msi config.MSI, binaries []*artifact.Artifact) error {
+	var template = tmpl.New(ctx).WithArtifact(binaries[0], msi.Replacements)
+	name, err := template.Apply(msi.FileNameTemplate)
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, name+".msi")
+	var log = log.WithField("msi", path)
+	log.Info("creating")
+
+	wxs, err := render(template, msi, binaries)
+	if err != nil {
+		return fmt.Errorf("failed to render %s: %w", name+".wxs", err)
+	}
+	dir, err := ioutil.TempDir("", "goreleaser-msi")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	var wxsPath = filepath.Join(dir, name+".wxs")
+	if err := ioutil.WriteFile(wxsPath, []byte(wxs), 0644); err != nil {
+		return err
+	}
+	log.WithField("wxs", wxsPath).Debug("wrote wix source")
+
+	var wixArch = wixArchs[binaries[0].Goarch]
+	for _, args := range toolchainArgs(msi.Toolchain, wixArch, wxsPath, path) {
+		log.WithField("cmd", args).Debug("running")
+		/* #nosec */
+		var cmd = exec.CommandContext(ctx, args[0], args[1:]...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to create %s with %s: %w: %s", name+".msi", args[0], err, string(out))
+		}
+	}
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.WindowsInstaller,
+		Name:   name + ".msi",
+		Path:   path,
+		Goos:   binaries[0].Goos,
+		Goarch: binaries[0].Goarch,
+		Extra: map[string]interface{}{
+			"Builds": binaries,
+			"ID":     msi.ID,
+			"Format": "msi",
+		},
+	})
+	return nil
+}
+
+// render gives the WiX source of the msi, from the wxs template of the config
+// if set, or from the default one.
+func render(template *tmpl.Template, msi config.MSI, binaries []*artifact.Artifact) (string, error) {
+	var wxs = wxsTemplate
+	if msi.WXS != "" {
+		bts, err := ioutil.ReadFile(msi.WXS)
+		if err != nil {
+			return "", err
+		}
+		wxs = string(bts)
+	}
+	var fields = tmpl.Fields{
+		"WixArch":   wixArchs[binaries[0].Goarch],
+		"AddToPath": msi.Path,
+	}
+	for key, value := range map[string]string{
+		"Product":        msi.Product,
+		"Manufacturer":   msi.Manufacturer,
+		"ProductVersion": msi.Version,
+		"UpgradeCode":    msi.UpgradeCode,
+		"InstallDir":     msi.InstallDir,
+	} {
+		applied, err := template.Apply(value)
+		if err != nil {
+			return "", err
+		}
+		fields[key] = applied
+	}
+	wxsBinaries, err := wxsBinaries(msi, binaries)
+	if err != nil {
+		return "", err
+	}
+	fields["Binaries"] = wxsBinaries
+	return template.WithExtraFields(fields).Apply(wxs)
+}
+
+// wxsBinaries gives the binaries of the msi with their shortcuts. Shortcuts
+// without a binary go to the first one.
+func wxsBinaries(msi config.MSI, binaries []*artifact.Artifact) ([]wxsBinary, error) {
+	var result = make([]wxsBinary, 0, len(binaries))
+	var index = map[string]int{}
+	for i, binary := range binaries {
+		index[binary.Name] = i
+		index[binary.ExtraOr("Binary", "").(string)] = i
+		path, err := filepath.Abs(binary.Path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, wxsBinary{
+			ID:   fmt.Sprintf("Binary%d", i),
+			Name: binary.Name,
+			Path: path,
+		})
+	}
+	for i, shortcut := range msi.Shortcuts {
+		var target = 0
+		if shortcut.Binary != "" {
+			var ok bool
+			if target, ok = index[shortcut.Binary]; !ok {
+				return nil, fmt.Errorf("shortcut %s is for %s, which is not in the msi", shortcut.Name, shortcut.Binary)
+			}
+		}
+		var directory = "ProgramMenuDir"
+		if shortcut.Location == "desktop" {
+			directory = "DesktopFolder"
+		} else {
+			result[target].StartMenu = true
+		}
+		result[target].Shortcuts = append(result[target].Shortcuts, wxsShortcut{
+			ID:        fmt.Sprintf("Shortcut%d", i),
+			Name:      shortcut.Name,
+			Arguments: shortcut.Arguments,
+			Directory: directory,
+		})
+	}
+	return result, nil
+}
+
+// commands are the commands of the given toolchain.
+func commands(toolchain string) []string {
+	if toolchain == "wix" {
+		return []string{"candle", "light"}
+	}
+	return []string{"wixl"}
+}
+
+// toolchainArgs gives the commands to run to create the msi at path from the
+// WiX source at wxs.
+func toolchainArgs(toolchain, arch, wxs, path string) [][]string {
+	if toolchain == "wix" {
+		var obj = wxs + "obj"
+		return [][]string{
+			{"candle", "-nologo", "-arch", arch, "-out", obj, wxs},
+			{"light", "-nologo", "-out", path, obj},
+		}
+	}
+	return [][]string{
+		{"wixl", "--arch", arch, "--output", path, wxs},
+	}
+}
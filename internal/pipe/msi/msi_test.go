@@ -0,0 +1,294 @@
+package msi
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+const upgradeCode = "0b8d5c3a-6b1f-4e5e-9a4d-1c2b3d4e5f60"
+
+// fakeTool copies the last argument, the wix source or object, to the
+// -out or --output one, so the msi has the rendered wix source.
+const fakeTool = `#!/bin/sh
+for last; do :; done
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -out|--output) out="$2"; shift ;;
+  esac
+  shift
+done
+cp "$last" "$out"
+`
+
+// fakeToolchain puts the given commands, running script, first in $PATH.
+func fakeToolchain(t *testing.T, script string, cmds ...string) func() {
+	var dir = t.TempDir()
+	for _, cmd := range cmds {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, cmd), []byte(script), 0755))
+	}
+	var path = os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	return func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}
+}
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "myapp",
+		Builds:      []config.Build{{ID: "foo"}, {ID: "bar"}},
+		MSIs: []config.MSI{
+			{
+				UpgradeCode: upgradeCode,
+				Shortcuts:   []config.MSIShortcut{{Name: "My App"}},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.MSI{
+		ID:               "default",
+		Builds:           []string{"foo", "bar"},
+		FileNameTemplate: defaultNameTemplate,
+		Product:          "myapp",
+		Manufacturer:     "myapp",
+		Version:          defaultVersion,
+		UpgradeCode:      upgradeCode,
+		InstallDir:       "myapp",
+		Shortcuts:        []config.MSIShortcut{{Name: "My App", Location: "start_menu"}},
+		Toolchain:        "wixl",
+	}, ctx.Config.MSIs[0])
+}
+
+func TestDefaultInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		msi config.MSI
+		err string
+	}{
+		"upgrade code": {
+			msi: config.MSI{UpgradeCode: "nope"},
+			err: `invalid msi upgrade_code: "nope": should be a GUID, e.g. 0b8d5c3a-6b1f-4e5e-9a4d-1c2b3d4e5f60`,
+		},
+		"toolchain": {
+			msi: config.MSI{UpgradeCode: upgradeCode, Toolchain: "nope"},
+			err: "invalid msi toolchain: nope: should be wixl or wix",
+		},
+		"shortcut location": {
+			msi: config.MSI{UpgradeCode: upgradeCode, Shortcuts: []config.MSIShortcut{{Name: "a", Location: "nope"}}},
+			err: "invalid msi shortcut location: nope: should be start_menu or desktop",
+		},
+		"shortcut name": {
+			msi: config.MSI{UpgradeCode: upgradeCode, Shortcuts: []config.MSIShortcut{{}}},
+			err: "msi shortcuts need a name",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{MSIs: []config.MSI{tt.msi}})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func newContext(t *testing.T, msi config.MSI) *context.Context {
+	var dist = t.TempDir()
+	var ctx = context.New(config.Project{
+		ProjectName: "myapp",
+		Dist:        dist,
+		MSIs:        []config.MSI{msi},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Version = "1.2.3"
+	ctx.Git.CurrentTag = "v1.2.3"
+	ctx.Semver = context.Semver{Major: 1, Minor: 2, Patch: 3}
+	for _, goarch := range []string{"amd64", "386", "arm64"} {
+		for _, id := range []string{"myapp", "myapp-cli"} {
+			var path = filepath.Join(dist, id+"_windows_"+goarch, id+".exe")
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, ioutil.WriteFile(path, []byte("exe"), 0755))
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Goos:   "windows",
+				Goarch: goarch,
+				Name:   id + ".exe",
+				Path:   path,
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					"Binary": id,
+					"ID":     id,
+				},
+			})
+		}
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Goos:   "linux",
+		Goarch: "amd64",
+		Name:   "myapp",
+		Path:   filepath.Join(dist, "linux_amd64", "myapp"),
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"Binary": "myapp", "ID": "myapp"},
+	})
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	defer fakeToolchain(t, fakeTool, "wixl")()
+	var ctx = newContext(t, config.MSI{
+		Builds:       []string{"myapp", "myapp-cli"},
+		Product:      "My App",
+		Manufacturer: "ACME & Co",
+		UpgradeCode:  upgradeCode,
+		InstallDir:   "{{ .ProjectName }}",
+		Path:         true,
+		Shortcuts: []config.MSIShortcut{
+			{Name: "My App"},
+			{Name: "My App CLI", Binary: "myapp-cli", Arguments: "--help", Location: "desktop"},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var msis = ctx.Artifacts.Filter(artifact.ByType(artifact.WindowsInstaller)).List()
+	require.Len(t, msis, 2, "wixl can't build arm64 installers")
+	for _, msi := range msis {
+		require.Equal(t, "windows", msi.Goos)
+		require.Equal(t, "myapp_1.2.3_windows_"+msi.Goarch+".msi", msi.Name)
+		require.Len(t, msi.Extra["Builds"], 2)
+
+		bts, err := ioutil.ReadFile(msi.Path)
+		require.NoError(t, err)
+		var wxs = string(bts)
+		require.NoError(t, xml.Unmarshal(bts, new(interface{})), wxs)
+		require.Contains(t, wxs, `Name="My App" Manufacturer="ACME &amp; Co" Version="1.2.3" UpgradeCode="`+upgradeCode+`"`)
+		require.Contains(t, wxs, `<Directory Id="INSTALLDIR" Name="myapp" />`)
+		require.Contains(t, wxs, `<Environment Id="PATH" Name="PATH" Value="[INSTALLDIR]"`)
+		require.Contains(t, wxs, `<Shortcut Id="Shortcut0" Directory="ProgramMenuDir" Name="My App" Arguments=""`)
+		require.Contains(t, wxs, `<Shortcut Id="Shortcut1" Directory="DesktopFolder" Name="My App CLI" Arguments="--help"`)
+		require.Equal(t, 1, strings.Count(wxs, "<Environment "))
+		require.Equal(t, 1, strings.Count(wxs, "<RemoveFolder "), "only the start menu shortcut has a folder to remove")
+		require.Equal(t, 2, strings.Count(wxs, "<ComponentRef "))
+		if msi.Goarch == "386" {
+			require.Contains(t, wxs, `Platform="x86"`)
+			require.Contains(t, wxs, `ProgramFilesFolder`)
+		} else {
+			require.Contains(t, wxs, `Platform="x64"`)
+			require.Contains(t, wxs, `ProgramFiles64Folder`)
+		}
+	}
+}
+
+func TestRunPipeWix(t *testing.T) {
+	defer fakeToolchain(t, fakeTool, "candle", "light")()
+	var ctx = newContext(t, config.MSI{
+		Builds:      []string{"myapp"},
+		UpgradeCode: upgradeCode,
+		Toolchain:   "wix",
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	var msis = ctx.Artifacts.Filter(artifact.ByType(artifact.WindowsInstaller)).List()
+	require.Len(t, msis, 3)
+	for _, msi := range msis {
+		require.FileExists(t, msi.Path)
+		require.Len(t, msi.Extra["Builds"], 1)
+	}
+}
+
+func TestRunPipeCustomWXS(t *testing.T) {
+	defer fakeToolchain(t, fakeTool, "wixl")()
+	var wxs = filepath.Join(t.TempDir(), "custom.wxs")
+	require.NoError(t, ioutil.WriteFile(wxs, []byte("{{ .Product }} {{ .WixArch }}{{ range .Binaries }} {{ .Name }}{{ end }}"), 0644))
+	var ctx = newContext(t, config.MSI{
+		Builds:      []string{"myapp"},
+		UpgradeCode: upgradeCode,
+		WXS:         wxs,
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(ctx.Config.Dist, "myapp_1.2.3_windows_amd64.msi"))
+	require.NoError(t, err)
+	require.Equal(t, "myapp x64 myapp.exe", string(bts))
+}
+
+func TestRunPipeErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		msi    config.MSI
+		script string
+		err    string
+	}{
+		"no windows binaries": {
+			msi: config.MSI{Builds: []string{"nope"}},
+			err: "no windows binaries found for builds [nope]",
+		},
+		"shortcut binary": {
+			msi: config.MSI{Builds: []string{"myapp"}, Shortcuts: []config.MSIShortcut{{Name: "nope", Binary: "nope"}}},
+			err: "failed to render myapp_1.2.3_windows_amd64.wxs: shortcut nope is for nope, which is not in the msi",
+		},
+		"template": {
+			msi: config.MSI{Builds: []string{"myapp"}, Product: "{{ .Nope }"},
+			err: `failed to render myapp_1.2.3_windows_amd64.wxs: template: tmpl:1: unexpected "}" in operand`,
+		},
+		"toolchain": {
+			msi:    config.MSI{Builds: []string{"myapp"}},
+			script: "#!/bin/sh\necho invalid wxs\nexit 1\n",
+			err:    "failed to create myapp_1.2.3_windows_amd64.msi with wixl: exit status 1: invalid wxs\n",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var script = tt.script
+			if script == "" {
+				script = fakeTool
+			}
+			defer fakeToolchain(t, script, "wixl")()
+			tt.msi.UpgradeCode = upgradeCode
+			var ctx = newContext(t, tt.msi)
+			ctx.Parallelism = 1
+			// only amd64, so errors don't depend on which platform fails first
+			ctx.Artifacts = artifact.New()
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Goos:   "windows",
+				Goarch: "amd64",
+				Name:   "myapp.exe",
+				Path:   filepath.Join(ctx.Config.Dist, "myapp.exe"),
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"Binary": "myapp", "ID": "myapp"},
+			})
+			require.EqualError(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+}
+
+func TestRunPipeInvalidLaterMSI(t *testing.T) {
+	defer fakeToolchain(t, fakeTool, "wixl")()
+	var ctx = newContext(t, config.MSI{Builds: []string{"myapp"}, UpgradeCode: upgradeCode})
+	ctx.Config.MSIs = append(ctx.Config.MSIs, config.MSI{ID: "nope", Builds: []string{"nope"}, Toolchain: "wixl"})
+	require.EqualError(t, Pipe{}.Run(ctx), "no windows binaries found for builds [nope]")
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.WindowsInstaller)).List())
+	matches, err := filepath.Glob(filepath.Join(ctx.Config.Dist, "*.msi"))
+	require.NoError(t, err)
+	require.Empty(t, matches, "no msi should be created when the config is invalid")
+}
+
+func TestRunPipeNoToolchain(t *testing.T) {
+	var path = os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", path))
+	}()
+	require.NoError(t, os.Setenv("PATH", ""))
+	var ctx = newContext(t, config.MSI{UpgradeCode: upgradeCode, Toolchain: "wix"})
+	require.EqualError(t, Pipe{}.Run(ctx), "candle not present in $PATH")
+}
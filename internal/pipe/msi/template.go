@@ -0,0 +1,64 @@
+package msi
+
+// wxsBinary is a binary installed by the msi, as given to the wxs template.
+type wxsBinary struct {
+	ID        string
+	Name      string
+	Path      string
+	Shortcuts []wxsShortcut
+	// StartMenu is whether any of the shortcuts is in the start menu, whose
+	// folder is removed on uninstall.
+	StartMenu bool
+}
+
+// wxsShortcut is a shortcut to a binary, as given to the wxs template.
+type wxsShortcut struct {
+	ID        string
+	Name      string
+	Arguments string
+	Directory string
+}
+
+// wxsTemplate is the default WiX source of the msi. Besides the usual
+// template fields, it gets Product, Manufacturer, ProductVersion, UpgradeCode,
+// InstallDir, WixArch, AddToPath and Binaries.
+const wxsTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<Wix xmlns="http://schemas.microsoft.com/wix/2006/wi">
+  <Product Id="*" Name="{{ html .Product }}" Manufacturer="{{ html .Manufacturer }}" Version="{{ html .ProductVersion }}" UpgradeCode="{{ .UpgradeCode }}" Language="1033">
+    <Package InstallerVersion="500" Compressed="yes" InstallScope="perMachine" Platform="{{ .WixArch }}" />
+    <MajorUpgrade DowngradeErrorMessage="A newer version of [ProductName] is already installed." />
+    <Media Id="1" Cabinet="product.cab" EmbedCab="yes" />
+    <Directory Id="TARGETDIR" Name="SourceDir">
+      <Directory Id="{{ if eq .WixArch "x86" }}ProgramFilesFolder{{ else }}ProgramFiles64Folder{{ end }}">
+        <Directory Id="INSTALLDIR" Name="{{ html .InstallDir }}" />
+      </Directory>
+      <Directory Id="ProgramMenuFolder">
+        <Directory Id="ProgramMenuDir" Name="{{ html .Product }}" />
+      </Directory>
+      <Directory Id="DesktopFolder" />
+    </Directory>
+    <DirectoryRef Id="INSTALLDIR">
+{{- range $i, $binary := .Binaries }}
+      <Component Id="{{ $binary.ID }}" Guid="*"{{ if ne $.WixArch "x86" }} Win64="yes"{{ end }}>
+        <File Id="{{ $binary.ID }}" Name="{{ html $binary.Name }}" Source="{{ html $binary.Path }}" KeyPath="yes">
+{{- range $binary.Shortcuts }}
+          <Shortcut Id="{{ .ID }}" Directory="{{ .Directory }}" Name="{{ html .Name }}" Arguments="{{ html .Arguments }}" WorkingDirectory="INSTALLDIR" Advertise="yes" />
+{{- end }}
+        </File>
+{{- if $binary.StartMenu }}
+        <RemoveFolder Id="Remove{{ $binary.ID }}" Directory="ProgramMenuDir" On="uninstall" />
+{{- end }}
+{{- if and $.AddToPath (eq $i 0) }}
+        <Environment Id="PATH" Name="PATH" Value="[INSTALLDIR]" Permanent="no" Part="last" Action="set" System="yes" />
+{{- end }}
+      </Component>
+{{- end }}
+    </DirectoryRef>
+    <Feature Id="Complete" Level="1">
+{{- range .Binaries }}
+      <ComponentRef Id="{{ .ID }}" />
+{{- end }}
+    </Feature>
+  </Product>
+</Wix>
+`
@@ -0,0 +1,215 @@
+// Package oci provides a Pipe that pushes artifacts to OCI registries, as
+// ORAS-like artifacts.
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Annotations set on the pushed manifests and layers.
+const (
+	AnnotationTitle   = "org.opencontainers.image.title"
+	AnnotationVersion = "org.opencontainers.image.version"
+	AnnotationOS      = "io.goreleaser.artifact.os"
+	AnnotationArch    = "io.goreleaser.artifact.arch"
+	AnnotationArm     = "io.goreleaser.artifact.arm"
+
+	// DefaultArtifactType is the config media type used when none is set.
+	DefaultArtifactType = "application/vnd.goreleaser.artifact.config.v1+json"
+
+	// LayerMediaType is the media type of each pushed artifact.
+	LayerMediaType = "application/vnd.goreleaser.artifact.layer.v1"
+)
+
+// Pipe for OCI artifacts.
+type Pipe struct{}
+
+// String returns the description of the pipe.
+func (Pipe) String() string {
+	return "oci artifacts"
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.OCIArtifacts {
+		oci := &ctx.Config.OCIArtifacts[i]
+		if oci.Registry == "" {
+			return fmt.Errorf("oci artifacts: registry cannot be empty")
+		}
+		if oci.Name == "" {
+			oci.Name = oci.Registry
+		}
+		if oci.Repository == "" {
+			oci.Repository = "{{ .ProjectName }}"
+		}
+		if oci.Tag == "" {
+			oci.Tag = "{{ .Version }}"
+		}
+		if oci.ArtifactType == "" {
+			oci.ArtifactType = DefaultArtifactType
+		}
+	}
+	return nil
+}
+
+// Publish artifacts.
+func (Pipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.OCIArtifacts) == 0 {
+		return pipe.Skip("oci_artifacts section is not configured")
+	}
+	if ctx.SkipPublish {
+		return pipe.ErrSkipPublishEnabled
+	}
+
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, conf := range ctx.Config.OCIArtifacts {
+		conf := conf
+		g.Go(func() error {
+			return push(ctx, conf)
+		})
+	}
+	return g.Wait()
+}
+
+func push(ctx *context.Context, conf config.OCIArtifact) error {
+	var template = tmpl.New(ctx)
+	repo, err := template.Apply(conf.Repository)
+	if err != nil {
+		return err
+	}
+	tag, err := template.Apply(conf.Tag)
+	if err != nil {
+		return err
+	}
+	username, err := template.Apply(conf.Username)
+	if err != nil {
+		return err
+	}
+	password, err := template.ApplySingleEnvOnly(conf.Password)
+	if err != nil {
+		return err
+	}
+	var annotations = map[string]string{
+		AnnotationVersion: ctx.Version,
+	}
+	for k, v := range conf.Annotations {
+		if annotations[k], err = template.Apply(v); err != nil {
+			return err
+		}
+	}
+
+	var ref = conf.Registry + "/" + repo + ":" + tag
+	var log = log.WithField("name", conf.Name).WithField("ref", ref)
+	if ctx.Progress.IsUploaded("oci", ref) {
+		log.Info("already pushed, skipping")
+		return nil
+	}
+
+	var artifacts = ctx.Artifacts.Filter(filter(conf)).List()
+	if len(artifacts) == 0 {
+		log.Warn("no artifacts to push")
+		return nil
+	}
+
+	var client = registry.New(conf.Registry, registry.Options{
+		Username: username,
+		Password: password,
+		Insecure: conf.Insecure,
+	})
+	configBlob, err := client.PushBlob(ctx, repo, conf.ArtifactType, []byte("{}"))
+	if err != nil {
+		return fmt.Errorf("oci artifacts: %s: %w", conf.Name, err)
+	}
+
+	var layers = make([]registry.Descriptor, len(artifacts))
+	var g = semerrgroup.New(ctx.Parallelism)
+	for i, a := range artifacts {
+		i, a := i, a
+		g.Go(func() error {
+			log.WithField("artifact", a.Name).Info("pushing")
+			layer, err := client.PushFile(ctx, repo, LayerMediaType, a.Path)
+			if err != nil {
+				return fmt.Errorf("oci artifacts: %s: failed to push %s: %w", conf.Name, a.Name, err)
+			}
+			layer.Annotations = layerAnnotations(a)
+			layers[i] = layer
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(registry.Manifest{
+		SchemaVersion: 2,
+		MediaType:     registry.MediaTypeImageManifest,
+		Config:        configBlob,
+		Layers:        layers,
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return err
+	}
+	digest, err := client.PushManifest(ctx, repo, tag, registry.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return fmt.Errorf("oci artifacts: %s: %w", conf.Name, err)
+	}
+	log.WithField("digest", digest).Info("pushed")
+
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Published,
+		Name: ref,
+		Path: conf.Registry + "/" + repo + "@" + digest,
+		Extra: map[string]interface{}{
+			"Publisher": "oci",
+			"Digest":    digest,
+		},
+	})
+	return ctx.Progress.Uploaded("oci", ref)
+}
+
+func filter(conf config.OCIArtifact) artifact.Filter {
+	var filters = []artifact.Filter{
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableBinary),
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.WindowsInstaller),
+	}
+	if conf.Checksum {
+		filters = append(filters, artifact.ByType(artifact.Checksum))
+	}
+	if conf.Signature {
+		filters = append(filters, artifact.ByType(artifact.Signature))
+	}
+	var filter = artifact.Or(filters...)
+	if len(conf.IDs) > 0 {
+		filter = artifact.And(filter, artifact.ByIDs(conf.IDs...))
+	}
+	return filter
+}
+
+func layerAnnotations(a *artifact.Artifact) map[string]string {
+	var annotations = map[string]string{
+		AnnotationTitle: a.Name,
+	}
+	if a.Goos != "" {
+		annotations[AnnotationOS] = a.Goos
+	}
+	if a.Goarch != "" {
+		annotations[AnnotationArch] = a.Goarch
+	}
+	if a.Goarm != "" {
+		annotations[AnnotationArm] = a.Goarm
+	}
+	return annotations
+}
@@ -0,0 +1,205 @@
+package oci
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/progress"
+	"github.com/goreleaser/goreleaser/internal/registry"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		OCIArtifacts: []config.OCIArtifact{
+			{Registry: "ghcr.io"},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.OCIArtifact{
+		Name:         "ghcr.io",
+		Registry:     "ghcr.io",
+		Repository:   "{{ .ProjectName }}",
+		Tag:          "{{ .Version }}",
+		ArtifactType: DefaultArtifactType,
+	}, ctx.Config.OCIArtifacts[0])
+}
+
+func TestDefaultNoRegistry(t *testing.T) {
+	var ctx = context.New(config.Project{
+		OCIArtifacts: []config.OCIArtifact{{}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "oci artifacts: registry cannot be empty")
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		testlib.AssertSkipped(t, Pipe{}.Publish(context.New(config.Project{})))
+	})
+	t.Run("skip publish", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			OCIArtifacts: []config.OCIArtifact{{Registry: "ghcr.io"}},
+		})
+		ctx.SkipPublish = true
+		require.Equal(t, pipe.ErrSkipPublishEnabled, Pipe{}.Publish(ctx))
+	})
+}
+
+func setup(t *testing.T, reg *testlib.Registry, conf config.OCIArtifact) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName:  "foo",
+		OCIArtifacts: []config.OCIArtifact{conf},
+	})
+	ctx.Version = "1.2.3"
+	ctx.Env["OCI_PASSWORD"] = "pass"
+	ctx.Progress = progress.New(t.TempDir())
+	ctx.Config.OCIArtifacts[0].Registry = reg.Host
+	require.NoError(t, Pipe{}.Default(ctx))
+
+	var folder = t.TempDir()
+	for _, a := range []struct {
+		name   string
+		goos   string
+		goarch string
+		typ    artifact.Type
+		id     string
+	}{
+		{"foo_linux_amd64.tar.gz", "linux", "amd64", artifact.UploadableArchive, "default"},
+		{"foo_darwin_arm64.tar.gz", "darwin", "arm64", artifact.UploadableArchive, "default"},
+		{"foo_amd64.deb", "linux", "amd64", artifact.LinuxPackage, "pkgs"},
+		{"checksums.txt", "", "", artifact.Checksum, ""},
+		{"foo", "linux", "amd64", artifact.Binary, "default"},
+	} {
+		var path = filepath.Join(folder, a.name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(a.name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   a.name,
+			Path:   path,
+			Goos:   a.goos,
+			Goarch: a.goarch,
+			Type:   a.typ,
+			Extra: map[string]interface{}{
+				"ID": a.id,
+			},
+		})
+	}
+	return ctx
+}
+
+func TestPublish(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	reg.Username = "user"
+	reg.Password = "pass"
+	var ctx = setup(t, reg, config.OCIArtifact{
+		IDs:      []string{"default"},
+		Insecure: true,
+		Username: "user",
+		Password: "{{ .Env.OCI_PASSWORD }}",
+		Annotations: map[string]string{
+			"org.opencontainers.image.source": "https://github.com/goreleaser/{{ .ProjectName }}",
+		},
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, mediaType, ok := reg.Manifest("foo", "1.2.3")
+	require.True(t, ok)
+	require.Equal(t, registry.MediaTypeImageManifest, mediaType)
+	var manifest registry.Manifest
+	require.NoError(t, json.Unmarshal(bts, &manifest))
+	require.Equal(t, DefaultArtifactType, manifest.Config.MediaType)
+	require.Equal(t, map[string]string{
+		AnnotationVersion:                 "1.2.3",
+		"org.opencontainers.image.source": "https://github.com/goreleaser/foo",
+	}, manifest.Annotations)
+	require.Len(t, manifest.Layers, 2)
+	for _, layer := range manifest.Layers {
+		require.Equal(t, LayerMediaType, layer.MediaType)
+		content, ok := reg.Blob("foo", layer.Digest)
+		require.True(t, ok)
+		require.Equal(t, layer.Annotations[AnnotationTitle], string(content))
+		switch layer.Annotations[AnnotationTitle] {
+		case "foo_linux_amd64.tar.gz":
+			require.Equal(t, "linux", layer.Annotations[AnnotationOS])
+			require.Equal(t, "amd64", layer.Annotations[AnnotationArch])
+		case "foo_darwin_arm64.tar.gz":
+			require.Equal(t, "darwin", layer.Annotations[AnnotationOS])
+			require.Equal(t, "arm64", layer.Annotations[AnnotationArch])
+		default:
+			t.Fatalf("unexpected layer: %v", layer.Annotations)
+		}
+	}
+
+	var published = ctx.Artifacts.Filter(artifact.ByType(artifact.Published)).List()
+	require.Len(t, published, 1)
+	require.Equal(t, reg.Host+"/foo:1.2.3", published[0].Name)
+	require.Equal(t, reg.Host+"/foo@"+registry.Digest(bts), published[0].Path)
+	require.Equal(t, registry.Digest(bts), published[0].Extra["Digest"])
+	require.True(t, ctx.Progress.IsUploaded("oci", reg.Host+"/foo:1.2.3"))
+}
+
+func TestPublishChecksumsAndPackages(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = setup(t, reg, config.OCIArtifact{
+		Repository: "{{ .ProjectName }}/artifacts",
+		Tag:        "v{{ .Version }}",
+		Insecure:   true,
+		Checksum:   true,
+	})
+	require.NoError(t, Pipe{}.Publish(ctx))
+
+	bts, _, ok := reg.Manifest("foo/artifacts", "v1.2.3")
+	require.True(t, ok)
+	var manifest registry.Manifest
+	require.NoError(t, json.Unmarshal(bts, &manifest))
+	var titles []string
+	for _, layer := range manifest.Layers {
+		titles = append(titles, layer.Annotations[AnnotationTitle])
+	}
+	require.ElementsMatch(t, []string{
+		"foo_linux_amd64.tar.gz",
+		"foo_darwin_arm64.tar.gz",
+		"foo_amd64.deb",
+		"checksums.txt",
+	}, titles)
+}
+
+func TestPublishResume(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = setup(t, reg, config.OCIArtifact{Insecure: true})
+	require.NoError(t, ctx.Progress.Uploaded("oci", reg.Host+"/foo:1.2.3"))
+	require.NoError(t, Pipe{}.Publish(ctx))
+	_, _, ok := reg.Manifest("foo", "1.2.3")
+	require.False(t, ok)
+}
+
+func TestPublishAuthError(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	reg.Username = "user"
+	reg.Password = "pass"
+	var ctx = setup(t, reg, config.OCIArtifact{
+		Name:     "private",
+		Insecure: true,
+	})
+	require.EqualError(t, Pipe{}.Publish(ctx), "oci artifacts: private: registry "+reg.Host+" requires authentication")
+	require.False(t, ctx.Progress.IsUploaded("oci", reg.Host+"/foo:1.2.3"))
+}
+
+func TestPublishInvalidPassword(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var ctx = setup(t, reg, config.OCIArtifact{
+		Insecure: true,
+		Password: "plain-text",
+	})
+	require.EqualError(t, Pipe{}.Publish(ctx), "expected {{ .Env.VAR_NAME }} only (no plain-text or other interpolation)")
+}
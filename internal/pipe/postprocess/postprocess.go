@@ -0,0 +1,194 @@
+// Package postprocess provides a pipe that runs the post processing steps of
+// the builds, like upx and strip, on their binaries.
+package postprocess
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/mattn/go-shellwords"
+)
+
+// Pipe for post processing binaries.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "post processing binaries"
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	for i := range ctx.Config.Builds {
+		for j := range ctx.Config.Builds[i].PostProcess {
+			var step = &ctx.Config.Builds[i].PostProcess[j]
+			if step.Type == "" {
+				step.Type = "command"
+			}
+			switch step.Type {
+			case "upx", "strip":
+				if step.Cmd == "" {
+					step.Cmd = step.Type
+				}
+			case "command":
+				if step.Cmd == "" {
+					return errors.New("post_process steps of type command need a cmd")
+				}
+			default:
+				return fmt.Errorf("invalid post_process type: %s: should be upx, strip or command", step.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	var g = semerrgroup.New(ctx.Parallelism)
+	var found bool
+	for _, build := range ctx.Config.Builds {
+		if build.Skip || len(build.PostProcess) == 0 {
+			continue
+		}
+		found = true
+		var filter = artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(build.ID),
+		)
+		for _, binary := range ctx.Artifacts.Filter(filter).List() {
+			build := build
+			binary := binary
+			g.Go(func() error {
+				return process(ctx, build, binary)
+			})
+		}
+	}
+	if !found {
+		return pipe.Skip("no builds with post_process")
+	}
+	return g.Wait()
+}
+
+// process runs the steps matching the target of the binary on it, keeping its
+// modification time.
+func process(ctx *context.Context, build config.Build, binary *artifact.Artifact) error {
+	info, err := os.Stat(binary.Path)
+	if err != nil {
+		return err
+	}
+	var target = targetOf(binary)
+	var changed bool
+	for _, step := range build.PostProcess {
+		ok, err := shouldRun(step, target)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.WithField("binary", binary.Path).WithField("type", step.Type).Debug("skipping post process step")
+			continue
+		}
+		if err := run(ctx, build, step, binary, target); err != nil {
+			return fmt.Errorf("failed to post process %s for %s: %w", binary.Path, target, err)
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return os.Chtimes(binary.Path, info.ModTime(), info.ModTime())
+}
+
+func run(ctx *context.Context, build config.Build, step config.PostProcess, binary *artifact.Artifact, target string) error {
+	var env = append(ctx.Env.Strings(), build.Env...)
+	var template = tmpl.New(ctx).
+		WithArtifact(binary, map[string]string{}).
+		WithExtraFields(tmpl.Fields{"Target": target})
+	for _, e := range step.Env {
+		e, err := template.WithEnvS(env).Apply(e)
+		if err != nil {
+			return err
+		}
+		env = append(env, e)
+	}
+	template = template.WithEnvS(env)
+
+	sh, err := template.Apply(step.Cmd)
+	if err != nil {
+		return err
+	}
+	command, err := shellwords.Parse(sh)
+	if err != nil {
+		return err
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("post_process %s step has an empty cmd", step.Type)
+	}
+	for _, arg := range step.Args {
+		arg, err := template.Apply(arg)
+		if err != nil {
+			return err
+		}
+		command = append(command, arg)
+	}
+	if step.Type != "command" {
+		command = append(command, binary.Path)
+	}
+
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, command[0], command[1:]...)
+	var log = log.WithField("binary", binary.Path).WithField("cmd", command)
+	cmd.Env = env
+	log.Info("post processing")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.WithError(err).Debug("failed")
+		return fmt.Errorf("%w: %s", err, string(out))
+	}
+	return nil
+}
+
+// shouldRun tells whether the step applies to the target: it must match one of
+// its targets, if any, and none of its excludes.
+func shouldRun(step config.PostProcess, target string) (bool, error) {
+	if len(step.Targets) > 0 {
+		ok, err := matchAny(step.Targets, target)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	excluded, err := matchAny(step.Exclude, target)
+	return !excluded, err
+}
+
+func matchAny(patterns []string, target string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := filepath.Match(pattern, target)
+		if err != nil {
+			return false, fmt.Errorf("invalid post_process target %s: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// targetOf gives the build target of the binary, e.g. linux_arm_6.
+func targetOf(binary *artifact.Artifact) string {
+	var target = binary.Goos + "_" + binary.Goarch
+	if binary.Goarm != "" {
+		target += "_" + binary.Goarm
+	}
+	if binary.Gomips != "" {
+		target += "_" + binary.Gomips
+	}
+	return target
+}
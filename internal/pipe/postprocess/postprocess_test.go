@@ -0,0 +1,166 @@
+package postprocess
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Builds: []config.Build{
+			{
+				PostProcess: []config.PostProcess{
+					{Type: "upx"},
+					{Type: "strip", Cmd: "x86_64-w64-mingw32-strip"},
+					{Cmd: "echo"},
+				},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, []config.PostProcess{
+		{Type: "upx", Cmd: "upx"},
+		{Type: "strip", Cmd: "x86_64-w64-mingw32-strip"},
+		{Type: "command", Cmd: "echo"},
+	}, ctx.Config.Builds[0].PostProcess)
+}
+
+func TestDefaultInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		step config.PostProcess
+		err  string
+	}{
+		"type":    {config.PostProcess{Type: "gzip"}, "invalid post_process type: gzip: should be upx, strip or command"},
+		"command": {config.PostProcess{}, "post_process steps of type command need a cmd"},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Builds: []config.Build{{PostProcess: []config.PostProcess{tt.step}}},
+			})
+			require.EqualError(t, Pipe{}.Default(ctx), tt.err)
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{
+		Builds: []config.Build{{ID: "foo"}},
+	})))
+}
+
+func TestRun(t *testing.T) {
+	var dist = t.TempDir()
+	var modTime = time.Now().AddDate(-1, 0, 0).Round(time.Second)
+	var ctx = context.New(config.Project{
+		Builds: []config.Build{
+			{
+				ID: "foo",
+				PostProcess: []config.PostProcess{
+					{
+						Type:    "strip",
+						Cmd:     "touch",
+						Args:    []string{"{{ .ArtifactPath }}.stripped"},
+						Exclude: []string{"darwin_*"},
+					},
+					{
+						Cmd:     "sh -c 'echo $FOO >> {{ .ArtifactPath }}'",
+						Env:     []string{"FOO={{ .Target }}"},
+						Targets: []string{"linux_arm_*"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	for _, a := range []*artifact.Artifact{
+		{Goos: "linux", Goarch: "amd64"},
+		{Goos: "linux", Goarch: "arm", Goarm: "6"},
+		{Goos: "darwin", Goarch: "amd64"},
+	} {
+		a.Name = "foo"
+		a.Path = filepath.Join(dist, targetOf(a), "foo")
+		a.Type = artifact.Binary
+		a.Extra = map[string]interface{}{"ID": "foo"}
+		require.NoError(t, os.MkdirAll(filepath.Dir(a.Path), 0755))
+		require.NoError(t, ioutil.WriteFile(a.Path, []byte("foo\n"), 0755))
+		require.NoError(t, os.Chtimes(a.Path, modTime, modTime))
+		ctx.Artifacts.Add(a)
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "bar",
+		Path:   filepath.Join(dist, "bar"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"ID": "bar"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	require.FileExists(t, filepath.Join(dist, "linux_amd64", "foo.stripped"))
+	require.FileExists(t, filepath.Join(dist, "linux_arm_6", "foo.stripped"))
+	require.NoFileExists(t, filepath.Join(dist, "darwin_amd64", "foo.stripped"))
+
+	for target, content := range map[string]string{
+		"linux_amd64":  "foo\n",
+		"linux_arm_6":  "foo\nlinux_arm_6\n",
+		"darwin_amd64": "foo\n",
+	} {
+		var path = filepath.Join(dist, target, "foo")
+		bts, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, content, string(bts))
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.True(t, modTime.Equal(info.ModTime()), target)
+	}
+}
+
+func TestRunFail(t *testing.T) {
+	var dist = t.TempDir()
+	var path = filepath.Join(dist, "foo")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0755))
+	for name, tt := range map[string]struct {
+		step config.PostProcess
+		err  string
+	}{
+		"command":       {config.PostProcess{Cmd: "sh -c 'echo nope; exit 1'"}, "failed to post process " + path + " for linux_amd64: exit status 1: nope\n"},
+		"empty command": {config.PostProcess{Cmd: "{{ if false }}upx{{ end }}"}, "failed to post process " + path + " for linux_amd64: post_process command step has an empty cmd"},
+		"cmd template":  {config.PostProcess{Cmd: "{{ .Foo }"}, `failed to post process ` + path + ` for linux_amd64: template: tmpl:1: unexpected "}" in operand`},
+		"args template": {config.PostProcess{Type: "upx", Args: []string{"{{ .Foo }"}}, `failed to post process ` + path + ` for linux_amd64: template: tmpl:1: unexpected "}" in operand`},
+		"env template":  {config.PostProcess{Cmd: "true", Env: []string{"{{ .Foo }"}}, `failed to post process ` + path + ` for linux_amd64: template: tmpl:1: unexpected "}" in operand`},
+		"pattern":       {config.PostProcess{Cmd: "true", Targets: []string{"linux_["}}, "invalid post_process target linux_[: syntax error in pattern"},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Builds: []config.Build{
+					{ID: "foo", PostProcess: []config.PostProcess{tt.step}},
+				},
+			})
+			require.NoError(t, Pipe{}.Default(ctx))
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "foo",
+				Path:   path,
+				Goos:   "linux",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"ID": "foo"},
+			})
+			require.EqualError(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+}
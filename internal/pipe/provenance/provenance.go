@@ -0,0 +1,261 @@
+// Package provenance generates an in-toto attestation with the SLSA
+// provenance of the release artifacts.
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// statementType is the type of in-toto statements.
+	statementType = "https://in-toto.io/Statement/v0.1"
+	// predicateType is the type of SLSA provenance predicates.
+	predicateType = "https://slsa.dev/provenance/v0.1"
+	// recipeType is the type of the recipe of the provenance, a goreleaser
+	// release.
+	recipeType = "https://goreleaser.com/release@v1"
+)
+
+// statement is an in-toto statement, see
+// https://github.com/in-toto/attestation/blob/main/spec/README.md#statement.
+type statement struct {
+	Type          string    `json:"_type"`
+	Subject       []subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     predicate `json:"predicate"`
+}
+
+// subject is an artifact the statement is about.
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// predicate is the SLSA provenance, see https://slsa.dev/provenance/v0.1.
+type predicate struct {
+	Builder   builder    `json:"builder"`
+	Recipe    recipe     `json:"recipe"`
+	Metadata  metadata   `json:"metadata"`
+	Materials []material `json:"materials,omitempty"`
+}
+
+// builder identifies what built the artifacts.
+type builder struct {
+	ID string `json:"id"`
+}
+
+// recipe describes how the artifacts were built: the effective config and
+// the build environment.
+type recipe struct {
+	Type              string                 `json:"type"`
+	DefinedInMaterial int                    `json:"definedInMaterial"`
+	Arguments         interface{}            `json:"arguments"`
+	Environment       map[string]interface{} `json:"environment"`
+}
+
+// metadata of the build.
+type metadata struct {
+	BuildStartedOn  string       `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn string       `json:"buildFinishedOn,omitempty"`
+	Completeness    completeness `json:"completeness"`
+	Reproducible    bool         `json:"reproducible"`
+}
+
+// completeness tells which parts of the provenance are complete.
+type completeness struct {
+	Arguments   bool `json:"arguments"`
+	Environment bool `json:"environment"`
+	Materials   bool `json:"materials"`
+}
+
+// material is a source the artifacts were built from.
+type material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Pipe for provenance.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "provenance attestation"
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	var cfg = &ctx.Config.Provenance
+	if cfg.NameTemplate == "" {
+		cfg.NameTemplate = "{{ .ProjectName }}_{{ .Version }}_provenance.intoto.json"
+	}
+	if cfg.BuilderID == "" {
+		cfg.BuilderID = "https://goreleaser.com"
+	}
+	return nil
+}
+
+// Run the pipe.
+// It runs before the publishers, so the docker images and manifest lists,
+// which only get a digest once pushed, are not subjects.
+func (Pipe) Run(ctx *context.Context) error {
+	if !ctx.Config.Provenance.Enabled {
+		return pipe.Skip("provenance is disabled")
+	}
+	var artifacts = ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.UploadableArchive),
+		artifact.ByType(artifact.UploadableBinary),
+		artifact.ByType(artifact.UploadableSourceArchive),
+		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.WindowsInstaller),
+		artifact.ByType(artifact.Checksum),
+		artifact.ByType(artifact.SBOM),
+	)).List()
+	if len(artifacts) == 0 {
+		return pipe.Skip("no artifacts to attest")
+	}
+
+	var g = semerrgroup.New(ctx.Parallelism)
+	var subjects = make([]subject, len(artifacts))
+	for i, a := range artifacts {
+		i := i
+		a := a
+		g.Go(func() error {
+			sum, err := a.Checksum("sha256")
+			if err != nil {
+				return err
+			}
+			subjects[i] = subject{Name: a.Name, Digest: map[string]string{"sha256": sum}}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjects[i].Name < subjects[j].Name
+	})
+
+	statement, err := newStatement(ctx, subjects)
+	if err != nil {
+		return err
+	}
+	bts, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename, err := tmpl.New(ctx).Apply(ctx.Config.Provenance.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, filename)
+	log.WithField("file", filename).Info("writing")
+	if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.Attestation,
+		Name: filename,
+		Path: path,
+	})
+	return nil
+}
+
+func newStatement(ctx *context.Context, subjects []subject) (statement, error) {
+	id, err := tmpl.New(ctx).Apply(ctx.Config.Provenance.BuilderID)
+	if err != nil {
+		return statement{}, fmt.Errorf("failed to execute provenance builder_id template: %w", err)
+	}
+	config, err := effectiveConfig(ctx)
+	if err != nil {
+		return statement{}, err
+	}
+	var result = statement{
+		Type:          statementType,
+		Subject:       subjects,
+		PredicateType: predicateType,
+		Predicate: predicate{
+			Builder: builder{ID: id},
+			Recipe: recipe{
+				Type:      recipeType,
+				Arguments: config,
+				Environment: map[string]interface{}{
+					"os":       runtime.GOOS,
+					"arch":     runtime.GOARCH,
+					"snapshot": ctx.Snapshot,
+				},
+			},
+			Metadata: metadata{
+				BuildFinishedOn: finishedOn(ctx).UTC().Format(time.RFC3339),
+				Completeness:    completeness{Arguments: true},
+				Reproducible:    ctx.Config.Reproducible.Enabled,
+			},
+		},
+	}
+	if !ctx.Date.IsZero() {
+		result.Predicate.Metadata.BuildStartedOn = ctx.Date.UTC().Format(time.RFC3339)
+	}
+	if ctx.Git.FullCommit != "" {
+		result.Predicate.Materials = []material{{
+			URI:    "git+" + ctx.Git.URL,
+			Digest: map[string]string{"sha1": ctx.Git.FullCommit},
+		}}
+	}
+	return result, nil
+}
+
+// finishedOn returns the date the build finished. Reproducible builds use the
+// commit date, as they do for the build date, so the attestation is the same
+// no matter when the release is built.
+func finishedOn(ctx *context.Context) time.Time {
+	if ctx.Config.Reproducible.Enabled && !ctx.Git.CommitDate.IsZero() {
+		return ctx.Git.CommitDate
+	}
+	return time.Now()
+}
+
+// effectiveConfig returns the effective config as JSON-friendly values, the
+// same way it is written to dist/config.yaml.
+func effectiveConfig(ctx *context.Context) (interface{}, error) {
+	bts, err := yaml.Marshal(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	var config interface{}
+	if err := yaml.Unmarshal(bts, &config); err != nil {
+		return nil, err
+	}
+	return jsonify(config), nil
+}
+
+// jsonify converts the maps decoded by yaml, which have interface{} keys,
+// to maps that can be encoded as JSON.
+func jsonify(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		var result = make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[fmt.Sprint(key)] = jsonify(value)
+		}
+		return result
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonify(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,162 @@
+package provenance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "{{ .ProjectName }}_{{ .Version }}_provenance.intoto.json", ctx.Config.Provenance.NameTemplate)
+	require.Equal(t, "https://goreleaser.com", ctx.Config.Provenance.BuilderID)
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+	})
+	t.Run("no artifacts", func(t *testing.T) {
+		var ctx = context.New(config.Project{
+			Provenance: config.Provenance{Enabled: true},
+		})
+		testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+	})
+}
+
+func TestRun(t *testing.T) {
+	var folder = t.TempDir()
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		Dist:        folder,
+		Env:         []string{"FOO=bar"},
+		Provenance: config.Provenance{
+			Enabled:   true,
+			BuilderID: "https://ci.example.com/{{ .Env.FOO }}",
+		},
+	})
+	ctx.Env = map[string]string{"FOO": "bar"}
+	ctx.Version = "1.0.0"
+	ctx.Date = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		FullCommit: "a1b2c3",
+		URL:        "https://github.com/goreleaser/proj.git",
+	}
+	require.NoError(t, Pipe{}.Default(ctx))
+
+	for name, typ := range map[string]artifact.Type{
+		"proj_linux_amd64.tar.gz": artifact.UploadableArchive,
+		"proj_checksums.txt":      artifact.Checksum,
+		"proj_1.0.0_amd64.deb":    artifact.LinuxPackage,
+		"proj":                    artifact.Binary,
+	} {
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{Name: name, Path: path, Type: typ})
+	}
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var attestations = ctx.Artifacts.Filter(artifact.ByType(artifact.Attestation)).List()
+	require.Len(t, attestations, 1)
+	require.Equal(t, "proj_1.0.0_provenance.intoto.json", attestations[0].Name)
+	require.Equal(t, filepath.Join(folder, "proj_1.0.0_provenance.intoto.json"), attestations[0].Path)
+
+	bts, err := ioutil.ReadFile(attestations[0].Path)
+	require.NoError(t, err)
+	var s statement
+	require.NoError(t, json.Unmarshal(bts, &s))
+	require.Equal(t, "https://in-toto.io/Statement/v0.1", s.Type)
+	require.Equal(t, "https://slsa.dev/provenance/v0.1", s.PredicateType)
+	require.Equal(t, []subject{
+		{
+			Name:   "proj_1.0.0_amd64.deb",
+			Digest: map[string]string{"sha256": "bd17d571e71d56033db1c2734ca350185790790d7b7148ffb1af650d010acc06"},
+		},
+		{
+			Name:   "proj_checksums.txt",
+			Digest: map[string]string{"sha256": "e893f034a7c8ecbc005c68b1c628d85972bb0a3703f07e8aa4a12442d74720bb"},
+		},
+		{
+			Name:   "proj_linux_amd64.tar.gz",
+			Digest: map[string]string{"sha256": "1db29b752b34b6cae2b62ba9bac1b53b7a3595ca8636082593d9db8807dd3d2a"},
+		},
+	}, s.Subject)
+
+	var predicate = s.Predicate
+	require.Equal(t, "https://ci.example.com/bar", predicate.Builder.ID)
+	require.Equal(t, "https://goreleaser.com/release@v1", predicate.Recipe.Type)
+	require.Equal(t, map[string]interface{}{
+		"os":       runtime.GOOS,
+		"arch":     runtime.GOARCH,
+		"snapshot": false,
+	}, predicate.Recipe.Environment)
+	var arguments = predicate.Recipe.Arguments.(map[string]interface{})
+	require.Equal(t, "proj", arguments["project_name"])
+	require.Equal(t, true, arguments["provenance"].(map[string]interface{})["enabled"])
+	require.Equal(t, "2020-10-01T12:00:00Z", predicate.Metadata.BuildStartedOn)
+	require.NotEmpty(t, predicate.Metadata.BuildFinishedOn)
+	require.Equal(t, []material{{
+		URI:    "git+https://github.com/goreleaser/proj.git",
+		Digest: map[string]string{"sha1": "a1b2c3"},
+	}}, predicate.Materials)
+}
+
+func TestRunInvalidNameTemplate(t *testing.T) {
+	var folder = t.TempDir()
+	var path = filepath.Join(folder, "checksums.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0644))
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Provenance: config.Provenance{
+			Enabled:      true,
+			NameTemplate: "{{ .Foo }",
+		},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{Name: "checksums.txt", Path: path, Type: artifact.Checksum})
+	require.EqualError(t, Pipe{}.Run(ctx), `template: tmpl:1: unexpected "}" in operand`)
+}
+
+func TestRunReproducible(t *testing.T) {
+	var folder = t.TempDir()
+	var path = filepath.Join(folder, "checksums.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo"), 0644))
+	var ctx = context.New(config.Project{
+		Dist:         folder,
+		Provenance:   config.Provenance{Enabled: true},
+		Reproducible: config.Reproducible{Enabled: true},
+	})
+	ctx.Git = context.GitInfo{CommitDate: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)}
+	ctx.Date = ctx.Git.CommitDate
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(&artifact.Artifact{Name: "checksums.txt", Path: path, Type: artifact.Checksum})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var attestations = ctx.Artifacts.Filter(artifact.ByType(artifact.Attestation)).List()
+	require.Len(t, attestations, 1)
+	bts, err := ioutil.ReadFile(attestations[0].Path)
+	require.NoError(t, err)
+	var s statement
+	require.NoError(t, json.Unmarshal(bts, &s))
+	require.Equal(t, metadata{
+		BuildStartedOn:  "2020-10-01T12:00:00Z",
+		BuildFinishedOn: "2020-10-01T12:00:00Z",
+		Completeness:    completeness{Arguments: true},
+		Reproducible:    true,
+	}, s.Predicate.Metadata)
+}
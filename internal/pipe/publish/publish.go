@@ -4,17 +4,22 @@ package publish
 import (
 	"fmt"
 
+	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/internal/middleware"
 	"github.com/goreleaser/goreleaser/internal/pipe/artifactory"
 	"github.com/goreleaser/goreleaser/internal/pipe/blob"
 	"github.com/goreleaser/goreleaser/internal/pipe/brew"
+	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
 	"github.com/goreleaser/goreleaser/internal/pipe/custompublishers"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/oci"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
+	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/upload"
+	"github.com/goreleaser/goreleaser/internal/progress"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -33,25 +38,45 @@ type Publisher interface {
 	Publish(ctx *context.Context) error
 }
 
+// publisher of the list, which is resumable if it records what it publishes
+// item by item, so a resumed publish can run it again: it then skips what was
+// already published, but still adds its artifacts, e.g. the pushed docker
+// images with their digests, for the publishers after it.
+type publisher struct {
+	Publisher
+	resumable bool
+}
+
 // nolint: gochecknoglobals
-var publishers = []Publisher{
-	blob.Pipe{},
-	upload.Pipe{},
-	custompublishers.Pipe{},
-	artifactory.Pipe{},
-	docker.Pipe{},
-	snapcraft.Pipe{},
+var publishers = []publisher{
+	{blob.Pipe{}, true},
+	{upload.Pipe{}, true},
+	{custompublishers.Pipe{}, true},
+	{artifactory.Pipe{}, true},
+	{oci.Pipe{}, true},
+	{docker.Pipe{}, true},
+	{docker.ManifestPipe{}, true},
+	{checksums.DockerDigestsPipe{}, true},
+	{sign.DockerPipe{}, true},
+	{snapcraft.Pipe{}, false},
 	// This should be one of the last steps
-	release.Pipe{},
+	{release.Pipe{}, true},
 	// brew and scoop use the release URL, so, they should be last
-	brew.Pipe{},
-	scoop.Pipe{},
-	milestone.Pipe{},
+	{brew.Pipe{}, false},
+	{scoop.Pipe{}, false},
+	{milestone.Pipe{}, false},
 }
 
 // Run the pipe.
 func (Pipe) Run(ctx *context.Context) error {
+	if err := loadProgress(ctx); err != nil {
+		return err
+	}
 	for _, publisher := range publishers {
+		if !publisher.resumable && ctx.Progress.IsPublished(publisher.String()) {
+			log.WithField("publisher", publisher.String()).Info("already published, skipping")
+			continue
+		}
 		if err := middleware.Logging(
 			publisher.String(),
 			middleware.ErrHandler(publisher.Publish),
@@ -59,6 +84,24 @@ func (Pipe) Run(ctx *context.Context) error {
 		)(ctx); err != nil {
 			return fmt.Errorf("%s: failed to publish artifacts: %w", publisher.String(), err)
 		}
+		if err := ctx.Progress.Published(publisher.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadProgress sets up the publish progress tracking, resuming the previous
+// one if requested.
+func loadProgress(ctx *context.Context) error {
+	if !ctx.Resume {
+		ctx.Progress = progress.New(ctx.Config.Dist)
+		return nil
+	}
+	p, err := progress.Load(ctx.Config.Dist)
+	if err != nil {
+		return err
 	}
+	ctx.Progress = p
 	return nil
 }
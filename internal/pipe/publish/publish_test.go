@@ -1,8 +1,16 @@
 package publish
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/pipe/release"
+	"github.com/goreleaser/goreleaser/internal/progress"
+	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
 	"github.com/stretchr/testify/require"
@@ -13,7 +21,7 @@ func TestDescription(t *testing.T) {
 }
 
 func TestPublish(t *testing.T) {
-	var ctx = context.New(config.Project{})
+	var ctx = context.New(config.Project{Dist: t.TempDir()})
 	ctx.Config.Release.Disable = true
 	ctx.TokenType = context.TokenTypeGitHub
 	for i := range ctx.Config.Dockers {
@@ -21,3 +29,106 @@ func TestPublish(t *testing.T) {
 	}
 	require.NoError(t, Pipe{}.Run(ctx))
 }
+
+func TestPublishResume(t *testing.T) {
+	var dist = t.TempDir()
+	var prev = progress.New(dist)
+	for _, publisher := range publishers {
+		require.NoError(t, prev.Published(publisher.String()))
+	}
+	var ctx = context.New(config.Project{Dist: dist})
+	ctx.Config.Release.Disable = true
+	ctx.Config.Checksum.DockerDigestsNameTemplate = "digests.txt"
+	ctx.TokenType = context.TokenTypeGitHub
+	ctx.Resume = true
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:  "myuser/myimage:1.0.0",
+		Type:  artifact.DockerImage,
+		Extra: map[string]interface{}{"Digest": "sha256:foo"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.True(t, ctx.Progress.IsPublished(release.Pipe{}.String()))
+
+	// the docker digests publisher, which is resumable, ran again
+	bts, err := ioutil.ReadFile(filepath.Join(dist, "digests.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "sha256:foo  myuser/myimage:1.0.0\n", string(bts))
+}
+
+func TestPublishResumeDockerManifests(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	_, back := testlib.Mktmp(t)
+	t.Cleanup(back)
+	var newCtx = func() *context.Context {
+		var ctx = context.New(config.Project{
+			ProjectName: "mybin",
+			Dist:        "dist",
+			Dockers: []config.Docker{
+				{
+					Use:            docker.UseNative,
+					Insecure:       true,
+					Binaries:       []string{"mybin"},
+					ImageTemplates: []string{reg.Host + "/mybin:{{ .Version }}-amd64"},
+				},
+			},
+			DockerManifests: []config.DockerManifest{
+				{
+					NameTemplate:   reg.Host + "/mybin:{{ .Version }}",
+					ImageTemplates: []string{reg.Host + "/mybin:{{ .Version }}-amd64"},
+					Insecure:       true,
+				},
+			},
+		})
+		ctx.Version = "1.0.0"
+		ctx.Config.Release.Disable = true
+		ctx.Config.Checksum.DockerDigestsNameTemplate = "digests.txt"
+		ctx.TokenType = context.TokenTypeGitHub
+		return ctx
+	}
+
+	// a previous run pushed the images, and failed on the manifests
+	var ctx = newCtx()
+	require.NoError(t, os.MkdirAll(filepath.Join("dist", "mybin_linux_amd64"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join("dist", "mybin_linux_amd64", "mybin"), []byte("binary"), 0755))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "mybin",
+		Path:   filepath.Join("dist", "mybin_linux_amd64", "mybin"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"Binary": "mybin"},
+	})
+	require.NoError(t, docker.Pipe{}.Default(ctx))
+	require.NoError(t, docker.Pipe{}.Run(ctx))
+	ctx.Progress = progress.New("dist")
+	require.NoError(t, docker.Pipe{}.Publish(ctx))
+	require.NoError(t, ctx.Progress.Published(docker.Pipe{}.String()))
+	var pushed = ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, pushed, 1)
+
+	// the resumed run only has the artifacts of the saved state
+	var resumed = newCtx()
+	resumed.Resume = true
+	for _, a := range ctx.Artifacts.List() {
+		if a.Type != artifact.DockerImage {
+			resumed.Artifacts.Add(a)
+		}
+	}
+	require.NoError(t, Pipe{}.Run(resumed))
+
+	var images = resumed.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List()
+	require.Len(t, images, 1)
+	require.Equal(t, pushed[0].Extra["Digest"], images[0].Extra["Digest"])
+	_, _, ok := reg.Manifest("mybin", "1.0.0")
+	require.True(t, ok)
+	require.Len(t, resumed.Artifacts.Filter(artifact.ByType(artifact.DockerManifest)).List(), 1)
+	require.True(t, resumed.Progress.IsUploaded("docker_manifest", reg.Host+"/mybin:1.0.0"))
+}
+
+func TestPublishResumeInvalidProgress(t *testing.T) {
+	var dist = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, progress.Filename), []byte("nope"), 0644))
+	var ctx = context.New(config.Project{Dist: dist})
+	ctx.Resume = true
+	require.Error(t, Pipe{}.Run(ctx))
+}
@@ -14,22 +14,34 @@ const bodyTemplateText = `{{ .ReleaseNotes }}
 
 ## Docker images
 {{ range $element := . }}
-- ` + "`docker pull {{ . -}}`" + `
+- ` + "`docker pull {{ .Name -}}`" + `
+{{- with .Digest }} (` + "`{{ . }}`" + `){{ end -}}
 {{- end -}}
 {{- end }}
 `
 
+type dockerImage struct {
+	Name   string
+	Digest string
+}
+
 func describeBody(ctx *context.Context) (bytes.Buffer, error) {
 	var out bytes.Buffer
 	// nolint:prealloc
-	var dockers []string
-	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List() {
-		dockers = append(dockers, a.Name)
+	var dockers []dockerImage
+	for _, a := range ctx.Artifacts.Filter(artifact.Or(
+		artifact.ByType(artifact.DockerManifest),
+		artifact.ByType(artifact.DockerImage),
+	)).List() {
+		dockers = append(dockers, dockerImage{
+			Name:   a.Name,
+			Digest: a.ExtraOr("Digest", "").(string),
+		})
 	}
 	var bodyTemplate = template.Must(template.New("release").Parse(bodyTemplateText))
 	err := bodyTemplate.Execute(&out, struct {
 		ReleaseNotes string
-		DockerImages []string
+		DockerImages []dockerImage
 	}{
 		ReleaseNotes: ctx.ReleaseNotes,
 		DockerImages: dockers,
@@ -39,6 +39,39 @@ func TestDescribeBody(t *testing.T) {
 	require.Equal(t, string(bts), out.String())
 }
 
+func TestDescribeBodyWithDigests(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.ReleaseNotes = "feature1: description"
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/goreleaser:0.40.0-amd64",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": "sha256:a1b2c3",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/goreleaser:0.40.0-arm64",
+		Type: artifact.DockerImage,
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "goreleaser/goreleaser:0.40.0",
+		Type: artifact.DockerManifest,
+		Extra: map[string]interface{}{
+			"Digest": "sha256:d4e5f6",
+		},
+	})
+	out, err := describeBody(ctx)
+	require.NoError(t, err)
+
+	var golden = "testdata/release3.golden"
+	if *update {
+		_ = ioutil.WriteFile(golden, out.Bytes(), 0655)
+	}
+	bts, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	require.Equal(t, string(bts), out.String())
+}
+
 func TestDescribeBodyNoDockerImagesNoBrews(t *testing.T) {
 	var changelog = "feature1: description\nfeature2: other description"
 	var ctx = &context.Context{
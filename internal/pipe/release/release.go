@@ -150,6 +150,9 @@ func doPublish(ctx *context.Context, client client.Client) error {
 		artifact.ByType(artifact.Checksum),
 		artifact.ByType(artifact.Signature),
 		artifact.ByType(artifact.LinuxPackage),
+		artifact.ByType(artifact.WindowsInstaller),
+		artifact.ByType(artifact.SBOM),
+		artifact.ByType(artifact.Attestation),
 	)
 
 	if len(ctx.Config.Release.IDs) > 0 {
@@ -161,8 +164,15 @@ func doPublish(ctx *context.Context, client client.Client) error {
 	var g = semerrgroup.New(ctx.Parallelism)
 	for _, artifact := range ctx.Artifacts.Filter(filters).List() {
 		artifact := artifact
+		if ctx.Progress.IsUploaded("release", artifact.Name) {
+			log.WithField("name", artifact.Name).Info("already uploaded to release, skipping")
+			continue
+		}
 		g.Go(func() error {
-			return upload(ctx, client, releaseID, artifact)
+			if err := upload(ctx, client, releaseID, artifact); err != nil {
+				return err
+			}
+			return ctx.Progress.Uploaded("release", artifact.Name)
 		})
 	}
 	return g.Wait()
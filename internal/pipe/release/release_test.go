@@ -12,6 +12,7 @@ import (
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/progress"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/goreleaser/goreleaser/pkg/context"
@@ -170,6 +171,43 @@ func TestRunPipeWithIDsThenFilters(t *testing.T) {
 	require.NotContains(t, client.UploadedFileNames, "filtered.tar.gz")
 }
 
+func TestRunPipeResume(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	require.NoError(t, err)
+	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
+	require.NoError(t, err)
+	debfile, err := os.Create(filepath.Join(folder, "bin.deb"))
+	require.NoError(t, err)
+	var config = config.Project{
+		Dist: folder,
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+	}
+	var ctx = context.New(config)
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Progress = progress.New(folder)
+	require.NoError(t, ctx.Progress.Uploaded("release", "bin.tar.gz"))
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type: artifact.LinuxPackage,
+		Name: "bin.deb",
+		Path: debfile.Name(),
+	})
+	client := &DummyClient{}
+	require.NoError(t, doPublish(ctx, client))
+	require.True(t, client.CreatedRelease)
+	require.Equal(t, []string{"bin.deb"}, client.UploadedFileNames)
+	require.True(t, ctx.Progress.IsUploaded("release", "bin.deb"))
+}
+
 func TestRunPipeReleaseCreationFailed(t *testing.T) {
 	var config = config.Project{
 		Release: config.Release{
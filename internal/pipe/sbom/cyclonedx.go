@@ -0,0 +1,83 @@
+package sbom
+
+import "encoding/json"
+
+// cyclonedx JSON document, see https://cyclonedx.org/docs/1.2/json/.
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp,omitempty"`
+	Tools     []cdxTool    `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTool struct {
+	Vendor string `json:"vendor"`
+	Name   string `json:"name"`
+}
+
+type cdxComponent struct {
+	Type    string    `json:"type"`
+	BOMRef  string    `json:"bom-ref"`
+	Name    string    `json:"name"`
+	Version string    `json:"version,omitempty"`
+	Hashes  []cdxHash `json:"hashes,omitempty"`
+	PURL    string    `json:"purl,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cyclonedx(s subject) ([]byte, error) {
+	var ref = purl(s.Info.Main)
+	if ref == "" {
+		ref = s.Name
+	}
+	var root = cdxComponent{
+		Type:    s.Kind,
+		BOMRef:  ref,
+		Name:    s.Name,
+		Version: s.Version,
+		Hashes:  []cdxHash{{Alg: "SHA-256", Content: s.Checksum}},
+		PURL:    purl(s.Info.Main),
+	}
+	var doc = cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.2",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: s.Created,
+			Tools:     []cdxTool{{Vendor: "GoReleaser", Name: "goreleaser"}},
+			Component: root,
+		},
+		Components: []cdxComponent{},
+	}
+	var deps = cdxDependency{Ref: root.BOMRef, DependsOn: []string{}}
+	for _, dep := range s.Info.Deps {
+		var ref = purl(dep)
+		doc.Components = append(doc.Components, cdxComponent{
+			Type:    "library",
+			BOMRef:  ref,
+			Name:    dep.Path,
+			Version: dep.Version,
+			PURL:    ref,
+		})
+		deps.DependsOn = append(deps.DependsOn, ref)
+	}
+	doc.Dependencies = []cdxDependency{deps}
+	return json.MarshalIndent(doc, "", "  ")
+}
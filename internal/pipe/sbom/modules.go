@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// module is a go module, as listed in the build info of a binary.
+type module struct {
+	Path    string
+	Version string
+}
+
+// buildInfo is the module information of a binary.
+type buildInfo struct {
+	Main module
+	Deps []module
+}
+
+// readBuildInfo reads the module build info embedded in the given binary.
+// Binaries without any, e.g. prebuilt or not written in go, get an empty one,
+// so their SBOMs don't list any module.
+func readBuildInfo(ctx *context.Context, gobin, path string) (buildInfo, error) {
+	/* #nosec */
+	var cmd = exec.CommandContext(ctx, gobin, "version", "-m", path)
+	log.WithField("cmd", cmd.Args).Debug("running")
+	out, err := cmd.CombinedOutput()
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return buildInfo{}, fmt.Errorf("failed to read build info: %w", err)
+	}
+	var info = parseBuildInfo(string(out))
+	if info.Main.Path == "" {
+		log.WithField("binary", path).Warn("no module build info, the sbom won't list any module")
+	}
+	return info, nil
+}
+
+// parseBuildInfo parses the output of `go version -m`.
+func parseBuildInfo(out string) buildInfo {
+	var info buildInfo
+	for _, line := range strings.Split(out, "\n") {
+		var fields = strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		switch fields[0] {
+		case "mod":
+			info.Main = module{Path: fields[1], Version: fields[2]}
+		case "dep":
+			info.Deps = append(info.Deps, module{Path: fields[1], Version: fields[2]})
+		case "=>":
+			// replaces the previous dependency, unless it is replaced by a
+			// local directory
+			if len(info.Deps) > 0 && !isLocal(fields[1]) {
+				info.Deps[len(info.Deps)-1] = module{Path: fields[1], Version: fields[2]}
+			}
+		}
+	}
+	return info
+}
+
+// merge merges the given build infos into one, keeping the main module of
+// the first one and listing the others as dependencies. Empty build infos
+// are left out.
+func merge(infos []buildInfo) buildInfo {
+	var result buildInfo
+	var seen = map[module]bool{}
+	for _, info := range infos {
+		if info.Main.Path == "" {
+			continue
+		}
+		var mods = info.Deps
+		if result.Main.Path == "" {
+			result.Main = info.Main
+			seen[info.Main] = true
+		} else {
+			mods = append([]module{info.Main}, mods...)
+		}
+		for _, mod := range mods {
+			if seen[mod] {
+				continue
+			}
+			seen[mod] = true
+			result.Deps = append(result.Deps, mod)
+		}
+	}
+	sort.Slice(result.Deps, func(i, j int) bool {
+		if result.Deps[i].Path == result.Deps[j].Path {
+			return result.Deps[i].Version < result.Deps[j].Version
+		}
+		return result.Deps[i].Path < result.Deps[j].Path
+	})
+	return result
+}
+
+func isLocal(path string) bool {
+	return strings.HasPrefix(path, ".") || strings.HasPrefix(path, "/")
+}
+
+// purl is the package url of the given module, if any.
+func purl(mod module) string {
+	if mod.Path == "" {
+		return ""
+	}
+	return "pkg:golang/" + mod.Path + "@" + mod.Version
+}
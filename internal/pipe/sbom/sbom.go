@@ -0,0 +1,192 @@
+// Package sbom generates software bills of materials of the built binaries
+// and archives, from the go modules they embed.
+package sbom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+const (
+	formatSPDX      = "spdx"
+	formatCycloneDX = "cyclonedx"
+)
+
+// nolint: gochecknoglobals
+var extensions = map[string]string{
+	formatSPDX:      ".spdx.json",
+	formatCycloneDX: ".cdx.json",
+}
+
+// subject is what an SBOM document describes.
+type subject struct {
+	Name     string
+	Version  string
+	Kind     string
+	Checksum string
+	Created  string
+	Info     buildInfo
+}
+
+// Pipe for SBOM generation.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "software bill of materials"
+}
+
+// Default sets the Pipes defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	var cfg = &ctx.Config.SBOM
+	if cfg.Artifacts == "" {
+		cfg.Artifacts = "none"
+	}
+	if len(cfg.Formats) == 0 {
+		cfg.Formats = []string{formatSPDX, formatCycloneDX}
+	}
+	for _, format := range cfg.Formats {
+		if _, ok := extensions[format]; !ok {
+			return fmt.Errorf("invalid sbom format: %s: should be either %s or %s", format, formatSPDX, formatCycloneDX)
+		}
+	}
+	return nil
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	var filters []artifact.Filter
+	switch ctx.Config.SBOM.Artifacts {
+	case "binary":
+		filters = append(filters, artifact.ByType(artifact.Binary))
+	case "archive":
+		filters = append(filters, artifact.ByType(artifact.UploadableArchive))
+	case "all":
+		filters = append(filters, artifact.Or(
+			artifact.ByType(artifact.Binary),
+			artifact.ByType(artifact.UploadableArchive),
+		))
+	case "none", "":
+		return pipe.Skip("sbom generation is disabled")
+	default:
+		return fmt.Errorf("invalid list of artifacts to generate sboms for: %s", ctx.Config.SBOM.Artifacts)
+	}
+	if len(ctx.Config.SBOM.IDs) > 0 {
+		filters = append(filters, artifact.ByIDs(ctx.Config.SBOM.IDs...))
+	}
+
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, a := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+		a := a
+		g.Go(func() error {
+			return generate(ctx, a)
+		})
+	}
+	return g.Wait()
+}
+
+func generate(ctx *context.Context, a *artifact.Artifact) error {
+	var binaries = []*artifact.Artifact{a}
+	var kind = "application"
+	var name = binaryName(a)
+	if a.Type == artifact.UploadableArchive {
+		binaries = a.ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact)
+		kind = "file"
+		name = a.Name
+	}
+
+	var infos = make([]buildInfo, 0, len(binaries))
+	for _, binary := range binaries {
+		info, err := readBuildInfo(ctx, goBinary(ctx, binary), binary.Path)
+		if err != nil {
+			return fmt.Errorf("failed to generate sbom of %s: %w", a.Name, err)
+		}
+		infos = append(infos, info)
+	}
+	var info = merge(infos)
+	if info.Main.Version == "(devel)" || info.Main.Version == "" {
+		info.Main.Version = ctx.Git.CurrentTag
+	}
+
+	sum, err := a.Checksum("sha256")
+	if err != nil {
+		return err
+	}
+	var s = subject{
+		Name:     name,
+		Version:  ctx.Version,
+		Kind:     kind,
+		Checksum: sum,
+		Created:  ctx.Git.CommitDate.UTC().Format(time.RFC3339),
+		Info:     info,
+	}
+
+	for _, format := range ctx.Config.SBOM.Formats {
+		var bts []byte
+		switch format {
+		case formatSPDX:
+			bts, err = spdx(s)
+		case formatCycloneDX:
+			bts, err = cyclonedx(s)
+		}
+		if err != nil {
+			return err
+		}
+		var filename = name + extensions[format]
+		var path = filepath.Join(ctx.Config.Dist, filename)
+		log.WithField("artifact", a.Name).WithField("sbom", filename).Info("writing")
+		if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+			return fmt.Errorf("failed to write sbom: %w", err)
+		}
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Type:   artifact.SBOM,
+			Name:   filename,
+			Path:   path,
+			Goos:   a.Goos,
+			Goarch: a.Goarch,
+			Goarm:  a.Goarm,
+			Gomips: a.Gomips,
+			Extra: map[string]interface{}{
+				"ID":           a.ExtraOr("ID", ""),
+				"Format":       format,
+				"Artifact":     a.Name,
+				"ArtifactType": a.Type.String(),
+			},
+		})
+	}
+	return nil
+}
+
+// binaryName returns an unique name for the given binary, as the binaries
+// of all the platforms, and of builds of the same binary, share the same name.
+func binaryName(a *artifact.Artifact) string {
+	var name = fmt.Sprint(a.ExtraOr("Binary", a.Name))
+	if id := a.ExtraOr("ID", "").(string); id != "" {
+		name += "_" + id
+	}
+	name += "_" + a.Goos + "_" + a.Goarch
+	if a.Goarm != "" {
+		name += "v" + a.Goarm
+	}
+	if a.Gomips != "" {
+		name += "_" + a.Gomips
+	}
+	return name
+}
+
+// goBinary returns the go binary of the build of the given binary.
+func goBinary(ctx *context.Context, binary *artifact.Artifact) string {
+	for _, build := range ctx.Config.Builds {
+		if build.ID == binary.ExtraOr("ID", "") && build.GoBinary != "" {
+			return build.GoBinary
+		}
+	}
+	return "go"
+}
@@ -0,0 +1,301 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, "none", ctx.Config.SBOM.Artifacts)
+	require.Equal(t, []string{"spdx", "cyclonedx"}, ctx.Config.SBOM.Formats)
+}
+
+func TestDefaultInvalidFormat(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOM: config.SBOM{Formats: []string{"swid"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "invalid sbom format: swid: should be either spdx or cyclonedx")
+}
+
+func TestSkip(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	require.NoError(t, Pipe{}.Default(ctx))
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestInvalidArtifacts(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOM: config.SBOM{Artifacts: "docker"},
+	})
+	require.EqualError(t, Pipe{}.Run(ctx), "invalid list of artifacts to generate sboms for: docker")
+}
+
+func TestParseBuildInfo(t *testing.T) {
+	require.Equal(t, buildInfo{
+		Main: module{Path: "example.com/hello", Version: "(devel)"},
+		Deps: []module{
+			{Path: "example.com/dep", Version: "v1.0.0"},
+			{Path: "example.com/fork", Version: "v1.2.0"},
+			{Path: "example.com/local", Version: "v0.1.0"},
+		},
+	}, parseBuildInfo(`hello: go1.15.5
+	path	example.com/hello
+	mod	example.com/hello	(devel)
+	dep	example.com/dep	v1.0.0	h1:abc=
+	dep	example.com/orig	v1.1.0
+	=>	example.com/fork	v1.2.0	h1:def=
+	dep	example.com/local	v0.1.0
+	=>	./local	(devel)
+`))
+	require.Empty(t, parseBuildInfo("hello: go1.15.5\n").Main.Path)
+}
+
+func TestMerge(t *testing.T) {
+	require.Equal(t, buildInfo{
+		Main: module{Path: "example.com/a", Version: "(devel)"},
+		Deps: []module{
+			{Path: "example.com/b", Version: "(devel)"},
+			{Path: "example.com/dep", Version: "v1.0.0"},
+			{Path: "example.com/dep", Version: "v1.1.0"},
+			{Path: "example.com/other", Version: "v0.1.0"},
+		},
+	}, merge([]buildInfo{
+		{
+			Main: module{Path: "example.com/a", Version: "(devel)"},
+			Deps: []module{
+				{Path: "example.com/other", Version: "v0.1.0"},
+				{Path: "example.com/dep", Version: "v1.0.0"},
+			},
+		},
+		{
+			Main: module{Path: "example.com/a", Version: "(devel)"},
+			Deps: []module{{Path: "example.com/dep", Version: "v1.0.0"}},
+		},
+		{
+			Main: module{Path: "example.com/b", Version: "(devel)"},
+			Deps: []module{{Path: "example.com/dep", Version: "v1.1.0"}},
+		},
+		{},
+	}))
+}
+
+// project creates a go project using a local dependency in the current
+// directory and builds it.
+func project(t *testing.T) *artifact.Artifact {
+	require.NoError(t, os.Mkdir("dist", 0755))
+	require.NoError(t, os.Mkdir("dep", 0755))
+	require.NoError(t, ioutil.WriteFile("go.mod", []byte(`module example.com/hello
+
+go 1.15
+
+require example.com/dep v1.0.0
+
+replace example.com/dep => ./dep
+`), 0644))
+	require.NoError(t, ioutil.WriteFile("main.go", []byte(`package main
+
+import "example.com/dep"
+
+func main() { println(dep.X) }
+`), 0644))
+	require.NoError(t, ioutil.WriteFile("dep/go.mod", []byte("module example.com/dep\n"), 0644))
+	require.NoError(t, ioutil.WriteFile("dep/dep.go", []byte("package dep\n\nconst X = 1\n"), 0644))
+
+	var path = filepath.Join("dist", "hello_hello_linux_amd64", "hello")
+	var cmd = exec.Command("go", "build", "-o", path, ".")
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return &artifact.Artifact{
+		Name:   "hello",
+		Path:   path,
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"Binary": "hello",
+			"ID":     "hello",
+		},
+	}
+}
+
+func sbomContext(artifacts string) *context.Context {
+	var ctx = context.New(config.Project{
+		Dist: "dist",
+		SBOM: config.SBOM{Artifacts: artifacts},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Git.CommitDate = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	return ctx
+}
+
+func TestRun(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var binary = project(t)
+	require.NoError(t, ioutil.WriteFile("dist/hello.tar.gz", []byte("fake archive"), 0644))
+
+	var ctx = sbomContext("all")
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(binary)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "hello.tar.gz",
+		Path:   "dist/hello.tar.gz",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			"Builds": []*artifact.Artifact{binary},
+			"ID":     "default",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var sboms = ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(t, sboms, 4)
+	var names = map[string]*artifact.Artifact{}
+	for _, sbom := range sboms {
+		names[sbom.Name] = sbom
+		require.FileExists(t, sbom.Path)
+		require.Equal(t, "linux", sbom.Goos)
+	}
+	require.Contains(t, names, "hello_hello_linux_amd64.spdx.json")
+	require.Contains(t, names, "hello_hello_linux_amd64.cdx.json")
+	require.Contains(t, names, "hello.tar.gz.spdx.json")
+	require.Contains(t, names, "hello.tar.gz.cdx.json")
+	require.Equal(t, "hello", names["hello_hello_linux_amd64.spdx.json"].Extra["ID"])
+	require.Equal(t, "Binary", names["hello_hello_linux_amd64.spdx.json"].Extra["ArtifactType"])
+	require.Equal(t, "default", names["hello.tar.gz.cdx.json"].Extra["ID"])
+	require.Equal(t, "cyclonedx", names["hello.tar.gz.cdx.json"].Extra["Format"])
+	require.Equal(t, "Archive", names["hello.tar.gz.cdx.json"].Extra["ArtifactType"])
+
+	var spdx spdxDocument
+	bts, err := ioutil.ReadFile(names["hello_hello_linux_amd64.spdx.json"].Path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bts, &spdx))
+	require.Equal(t, "SPDX-2.2", spdx.SPDXVersion)
+	require.Equal(t, "2020-10-01T12:00:00Z", spdx.CreationInfo.Created)
+	require.Equal(t, "https://example.com/hello/sbom/hello_hello_linux_amd64-1.0.0", spdx.DocumentNamespace)
+	require.Len(t, spdx.Packages, 2)
+	require.Equal(t, "hello_hello_linux_amd64", spdx.Packages[0].Name)
+	require.Equal(t, "1.0.0", spdx.Packages[0].VersionInfo)
+	require.Equal(t, "pkg:golang/example.com/hello@v1.0.0", spdx.Packages[0].ExternalRefs[0].ReferenceLocator)
+	sum, err := binary.Checksum("sha256")
+	require.NoError(t, err)
+	require.Equal(t, []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: sum}}, spdx.Packages[0].Checksums)
+	require.Equal(t, "example.com/dep", spdx.Packages[1].Name)
+	require.Equal(t, "v1.0.0", spdx.Packages[1].VersionInfo)
+	require.Equal(t, []spdxRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelatedSPDXElement: "SPDXRef-Package-0", RelationshipType: "DESCRIBES"},
+		{SPDXElementID: "SPDXRef-Package-0", RelatedSPDXElement: "SPDXRef-Package-1", RelationshipType: "DEPENDS_ON"},
+	}, spdx.Relationships)
+
+	var cdx cdxDocument
+	bts, err = ioutil.ReadFile(names["hello.tar.gz.cdx.json"].Path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bts, &cdx))
+	require.Equal(t, "CycloneDX", cdx.BOMFormat)
+	require.Equal(t, "file", cdx.Metadata.Component.Type)
+	require.Equal(t, "hello.tar.gz", cdx.Metadata.Component.Name)
+	require.Equal(t, "pkg:golang/example.com/hello@v1.0.0", cdx.Metadata.Component.PURL)
+	require.Equal(t, []cdxComponent{{
+		Type:    "library",
+		BOMRef:  "pkg:golang/example.com/dep@v1.0.0",
+		Name:    "example.com/dep",
+		Version: "v1.0.0",
+		PURL:    "pkg:golang/example.com/dep@v1.0.0",
+	}}, cdx.Components)
+	require.Equal(t, []cdxDependency{{
+		Ref:       "pkg:golang/example.com/hello@v1.0.0",
+		DependsOn: []string{"pkg:golang/example.com/dep@v1.0.0"},
+	}}, cdx.Dependencies)
+}
+
+func TestRunWithoutBuildInfo(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var binary = project(t)
+	// not a go binary, so it has no modules to list
+	require.NoError(t, ioutil.WriteFile(binary.Path, []byte("#!/bin/sh"), 0755))
+
+	var ctx = sbomContext("binary")
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(binary)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var sboms = ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List()
+	require.Len(t, sboms, 2)
+	for _, sbom := range sboms {
+		bts, err := ioutil.ReadFile(sbom.Path)
+		require.NoError(t, err)
+		switch sbom.Extra["Format"] {
+		case "cyclonedx":
+			var cdx cdxDocument
+			require.NoError(t, json.Unmarshal(bts, &cdx))
+			require.Equal(t, "application", cdx.Metadata.Component.Type)
+			require.Empty(t, cdx.Metadata.Component.PURL)
+			require.Empty(t, cdx.Components)
+		case "spdx":
+			var spdx spdxDocument
+			require.NoError(t, json.Unmarshal(bts, &spdx))
+			require.Equal(t, "https://spdx.org/spdxdocs/hello_hello_linux_amd64-1.0.0", spdx.DocumentNamespace)
+			require.Len(t, spdx.Packages, 1)
+			require.Empty(t, spdx.Packages[0].ExternalRefs)
+		}
+	}
+}
+
+func TestRunSameBinaryName(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var binary = project(t)
+	var other = *binary
+	other.Extra = map[string]interface{}{
+		"Binary": "hello",
+		"ID":     "other",
+	}
+
+	var ctx = sbomContext("binary")
+	ctx.Config.SBOM.Formats = []string{"spdx"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(binary)
+	ctx.Artifacts.Add(&other)
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	var names []string
+	for _, sbom := range ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List() {
+		names = append(names, sbom.Name)
+	}
+	require.ElementsMatch(t, []string{"hello_hello_linux_amd64.spdx.json", "hello_other_linux_amd64.spdx.json"}, names)
+}
+
+func TestRunFilterByIDs(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	var binary = project(t)
+
+	var ctx = sbomContext("binary")
+	ctx.Config.SBOM.IDs = []string{"other"}
+	require.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(binary)
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List())
+}
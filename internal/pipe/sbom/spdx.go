@@ -0,0 +1,116 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// spdx JSON document, see https://spdx.github.io/spdx-spec/.
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+	RelationshipType   string `json:"relationshipType"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+func spdxModule(id string, mod module) spdxPackage {
+	var pkg = spdxPackage{
+		Name:             mod.Path,
+		SPDXID:           id,
+		VersionInfo:      mod.Version,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+	}
+	if ref := purl(mod); ref != "" {
+		pkg.ExternalRefs = []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE_MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  ref,
+		}}
+	}
+	return pkg
+}
+
+func spdx(s subject) ([]byte, error) {
+	const root = "SPDXRef-Package-0"
+	var main = spdxModule(root, s.Info.Main)
+	main.Name = s.Name
+	main.VersionInfo = s.Version
+	main.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: s.Checksum}}
+
+	// binaries without build info have no module to namespace the document
+	var namespace = fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", s.Name, s.Version)
+	if s.Info.Main.Path != "" {
+		namespace = fmt.Sprintf("https://%s/sbom/%s-%s", s.Info.Main.Path, s.Name, s.Version)
+	}
+	var doc = spdxDocument{
+		SPDXVersion:       "SPDX-2.2",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              s.Name,
+		DocumentNamespace: namespace,
+		CreationInfo: spdxCreationInfo{
+			Created:  s.Created,
+			Creators: []string{"Tool: goreleaser"},
+		},
+		Packages: []spdxPackage{main},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelatedSPDXElement: root,
+			RelationshipType:   "DESCRIBES",
+		}},
+	}
+	for i, dep := range s.Info.Deps {
+		var id = fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		doc.Packages = append(doc.Packages, spdxModule(id, dep))
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      root,
+			RelatedSPDXElement: id,
+			RelationshipType:   "DEPENDS_ON",
+		})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,106 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// DockerPipe is a publisher that signs the pushed docker images and
+// manifests by digest.
+type DockerPipe struct{}
+
+func (DockerPipe) String() string {
+	return "signing docker images"
+}
+
+// Default sets the Pipes defaults.
+func (DockerPipe) Default(ctx *context.Context) error {
+	var ids = ids.New("docker_signs")
+	for i := range ctx.Config.DockerSigns {
+		cfg := &ctx.Config.DockerSigns[i]
+		if cfg.Cmd == "" {
+			cfg.Cmd = "cosign"
+		}
+		if len(cfg.Args) == 0 {
+			cfg.Args = []string{"sign", "--key=cosign.key", "${artifact}@${digest}"}
+		}
+		if cfg.Artifacts == "" {
+			cfg.Artifacts = "none"
+		}
+		if cfg.ID == "" {
+			cfg.ID = "default"
+		}
+		ids.Inc(cfg.ID)
+	}
+	return ids.Validate()
+}
+
+// Publish signs the docker images.
+func (DockerPipe) Publish(ctx *context.Context) error {
+	if len(ctx.Config.DockerSigns) == 0 {
+		return pipe.Skip("docker_signs section is not configured")
+	}
+	if ctx.SkipSign {
+		return pipe.ErrSkipSignEnabled
+	}
+
+	var g = semerrgroup.NewSkipAware(semerrgroup.New(ctx.Parallelism))
+	for i := range ctx.Config.DockerSigns {
+		cfg := ctx.Config.DockerSigns[i]
+		g.Go(func() error {
+			var filters []artifact.Filter
+			switch cfg.Artifacts {
+			case "images":
+				filters = append(filters, artifact.ByType(artifact.DockerImage))
+			case "manifests":
+				filters = append(filters, artifact.ByType(artifact.DockerManifest))
+			case "all":
+				filters = append(filters, artifact.Or(
+					artifact.ByType(artifact.DockerImage),
+					artifact.ByType(artifact.DockerManifest),
+				))
+			case "none":
+				return pipe.Skip("docker_signs artifacts is none")
+			default:
+				return fmt.Errorf("invalid list of docker artifacts to sign: %s", cfg.Artifacts)
+			}
+			if len(cfg.IDs) > 0 {
+				filters = append(filters, artifact.ByIDs(cfg.IDs...))
+			}
+			for _, a := range ctx.Artifacts.Filter(artifact.And(filters...)).List() {
+				if err := signImage(ctx, cfg, a); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func signImage(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) error {
+	var digest = a.ExtraOr("Digest", "").(string)
+	if digest == "" {
+		return fmt.Errorf("sign: %s has no digest", a.Name)
+	}
+	var key = "docker_sign:" + cfg.ID
+	if ctx.Progress.IsUploaded(key, a.Name) {
+		log.WithField("image", a.Name).Info("already signed, skipping")
+		return nil
+	}
+
+	env := ctx.Env.Copy()
+	env["artifact"] = a.Name
+	env["digest"] = digest
+	if err := run(ctx, cfg, env); err != nil {
+		return err
+	}
+	return ctx.Progress.Uploaded(key, a.Name)
+}
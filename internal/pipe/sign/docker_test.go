@@ -0,0 +1,217 @@
+package sign
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/pipe/docker"
+	"github.com/goreleaser/goreleaser/internal/progress"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerSignDescription(t *testing.T) {
+	require.NotEmpty(t, DockerPipe{}.String())
+}
+
+func TestDockerSignDefault(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			DockerSigns: []config.Sign{{}},
+		},
+	}
+	require.NoError(t, DockerPipe{}.Default(ctx))
+	require.Equal(t, "cosign", ctx.Config.DockerSigns[0].Cmd)
+	require.Equal(t, []string{"sign", "--key=cosign.key", "${artifact}@${digest}"}, ctx.Config.DockerSigns[0].Args)
+	require.Equal(t, "none", ctx.Config.DockerSigns[0].Artifacts)
+	require.Equal(t, "default", ctx.Config.DockerSigns[0].ID)
+}
+
+func TestDockerSignDefaultDuplicatedIDs(t *testing.T) {
+	ctx := &context.Context{
+		Config: config.Project{
+			DockerSigns: []config.Sign{{}, {}},
+		},
+	}
+	require.EqualError(t, DockerPipe{}.Default(ctx), "found 2 docker_signs with the ID 'default', please fix your config")
+}
+
+func TestDockerSignSkipped(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		testlib.AssertSkipped(t, DockerPipe{}.Publish(context.New(config.Project{})))
+	})
+	t.Run("skip sign", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			DockerSigns: []config.Sign{{Artifacts: "all"}},
+		})
+		ctx.SkipSign = true
+		require.EqualError(t, DockerPipe{}.Publish(ctx), "artifact signing is disabled")
+	})
+	t.Run("none", func(t *testing.T) {
+		ctx := context.New(config.Project{
+			DockerSigns: []config.Sign{{Artifacts: "none"}},
+		})
+		testlib.AssertSkipped(t, DockerPipe{}.Publish(ctx))
+	})
+}
+
+func TestDockerSignInvalidArtifacts(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{Artifacts: "foo"}},
+	})
+	require.EqualError(t, DockerPipe{}.Publish(ctx), "invalid list of docker artifacts to sign: foo")
+}
+
+func TestDockerSignArtifacts(t *testing.T) {
+	for name, tt := range map[string]struct {
+		artifacts string
+		ids       []string
+		expected  string
+	}{
+		"images": {
+			artifacts: "images",
+			expected:  "img:amd64@sha256:1\nimg:arm64@sha256:2\n",
+		},
+		"manifests": {
+			artifacts: "manifests",
+			expected:  "img:latest@sha256:3\n",
+		},
+		"all": {
+			artifacts: "all",
+			expected:  "img:amd64@sha256:1\nimg:arm64@sha256:2\nimg:latest@sha256:3\n",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var out = filepath.Join(t.TempDir(), "signed")
+			ctx := context.New(config.Project{
+				DockerSigns: []config.Sign{{
+					Artifacts: tt.artifacts,
+					IDs:       tt.ids,
+					Cmd:       "sh",
+					Args:      []string{"-c", "echo ${artifact}@${digest} >> " + out},
+				}},
+			})
+			require.NoError(t, DockerPipe{}.Default(ctx))
+			addDockerArtifacts(ctx)
+			require.NoError(t, DockerPipe{}.Publish(ctx))
+			bts, err := ioutil.ReadFile(out)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(bts))
+		})
+	}
+}
+
+func TestDockerSignPushedIDs(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	_, back := testlib.Mktmp(t)
+	t.Cleanup(back)
+	var out = filepath.Join(t.TempDir(), "signed")
+	ctx := context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        "dist",
+		Builds:      []config.Build{{Binary: "mybin"}},
+		DockerManifests: []config.DockerManifest{{
+			ID:             "multi",
+			NameTemplate:   reg.Host + "/mybin:latest",
+			ImageTemplates: []string{reg.Host + "/mybin:amd64", reg.Host + "/mybin:arm64"},
+			Insecure:       true,
+		}},
+		DockerSigns: []config.Sign{{
+			Artifacts: "all",
+			IDs:       []string{"arm", "multi"},
+			Cmd:       "sh",
+			Args:      []string{"-c", "echo ${artifact} >> " + out},
+		}},
+	})
+	ctx.Progress = progress.New("dist")
+	for _, arch := range []string{"amd64", "arm64"} {
+		var path = filepath.Join("dist", "mybin_linux_"+arch, "mybin")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("binary "+arch), 0755))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name:   "mybin",
+			Path:   path,
+			Goos:   "linux",
+			Goarch: arch,
+			Type:   artifact.Binary,
+			Extra:  map[string]interface{}{"Binary": "mybin"},
+		})
+		ctx.Config.Dockers = append(ctx.Config.Dockers, config.Docker{
+			ID:             arch[:3],
+			Use:            docker.UseNative,
+			Insecure:       true,
+			Binaries:       []string{"mybin"},
+			Goarch:         arch,
+			ImageTemplates: []string{reg.Host + "/mybin:" + arch},
+		})
+	}
+	require.NoError(t, docker.Pipe{}.Default(ctx))
+	require.NoError(t, docker.Pipe{}.Run(ctx))
+	require.NoError(t, docker.Pipe{}.Publish(ctx))
+	require.NoError(t, docker.ManifestPipe{}.Publish(ctx))
+	require.NoError(t, DockerPipe{}.Default(ctx))
+	require.NoError(t, DockerPipe{}.Publish(ctx))
+
+	bts, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		reg.Host + "/mybin:arm64",
+		reg.Host + "/mybin:latest",
+	}, strings.Fields(string(bts)))
+}
+
+func TestDockerSignNoDigest(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{
+			Artifacts: "images",
+			Cmd:       "true",
+		}},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "img:latest",
+		Type: artifact.DockerImage,
+	})
+	require.EqualError(t, DockerPipe{}.Publish(ctx), "sign: img:latest has no digest")
+}
+
+func TestDockerSignFailed(t *testing.T) {
+	ctx := context.New(config.Project{
+		DockerSigns: []config.Sign{{
+			Artifacts: "images",
+			Cmd:       "false",
+		}},
+	})
+	addDockerArtifacts(ctx)
+	require.EqualError(t, DockerPipe{}.Publish(ctx), "sign: false failed")
+}
+
+func addDockerArtifacts(ctx *context.Context) {
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "img:amd64",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": "sha256:1",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "img:arm64",
+		Type: artifact.DockerImage,
+		Extra: map[string]interface{}{
+			"Digest": "sha256:2",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "img:latest",
+		Type: artifact.DockerManifest,
+		Extra: map[string]interface{}{
+			"Digest": "sha256:3",
+		},
+	})
+}
@@ -56,7 +56,6 @@ func (Pipe) Run(ctx *context.Context) error {
 	if ctx.SkipSign {
 		return pipe.ErrSkipSignEnabled
 	}
-
 	var g = semerrgroup.New(ctx.Parallelism)
 	for i := range ctx.Config.Signs {
 		cfg := ctx.Config.Signs[i]
@@ -73,6 +72,16 @@ func (Pipe) Run(ctx *context.Context) error {
 				if len(cfg.IDs) > 0 {
 					log.Warn("when artifacts is `source`, `ids` has no effect. ignoring")
 				}
+			case "attestation":
+				filters = append(filters, artifact.ByType(artifact.Attestation))
+				if len(cfg.IDs) > 0 {
+					log.Warn("when artifacts is `attestation`, `ids` has no effect. ignoring")
+				}
+			case "sbom":
+				filters = append(filters, artifact.ByType(artifact.SBOM))
+				if len(cfg.IDs) > 0 {
+					filters = append(filters, artifact.ByIDs(cfg.IDs...))
+				}
 			case "all":
 				filters = append(filters, artifact.Or(
 					artifact.ByType(artifact.UploadableArchive),
@@ -80,6 +89,9 @@ func (Pipe) Run(ctx *context.Context) error {
 					artifact.ByType(artifact.UploadableSourceArchive),
 					artifact.ByType(artifact.Checksum),
 					artifact.ByType(artifact.LinuxPackage),
+					artifact.ByType(artifact.WindowsInstaller),
+					artifact.ByType(artifact.SBOM),
+					artifact.ByType(artifact.Attestation),
 				))
 				if len(cfg.IDs) > 0 {
 					filters = append(filters, artifact.ByIDs(cfg.IDs...))
@@ -97,6 +109,10 @@ func (Pipe) Run(ctx *context.Context) error {
 
 func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact) error {
 	for _, a := range artifacts {
+		if signed(ctx, cfg, a) {
+			log.WithField("artifact", a.Name).Info("already signed, skipping")
+			continue
+		}
 		artifact, err := signone(ctx, cfg, a)
 		if err != nil {
 			return err
@@ -106,18 +122,54 @@ func sign(ctx *context.Context, cfg config.Sign, artifacts []*artifact.Artifact)
 	return nil
 }
 
+// signed tells whether the given artifact already has a signature of the given
+// config, e.g. from the saved state of a previous publish.
+func signed(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) bool {
+	return len(ctx.Artifacts.Filter(artifact.And(
+		artifact.ByType(artifact.Signature),
+		artifact.ByIDs(cfg.ID),
+		func(sig *artifact.Artifact) bool {
+			return sig.ExtraOr("Artifact", "") == a.Name
+		},
+	)).List()) > 0
+}
+
 func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*artifact.Artifact, error) {
 	env := ctx.Env.Copy()
 	env["artifact"] = a.Path
 	env["signature"] = expand(cfg.Signature, env)
 
+	if err := run(ctx, cfg, env); err != nil {
+		return nil, err
+	}
+
+	artifactPathBase, _ := filepath.Split(a.Path)
+
+	env["artifact"] = a.Name
+	name := expand(cfg.Signature, env)
+
+	sigFilename := filepath.Base(env["signature"])
+	return &artifact.Artifact{
+		Type: artifact.Signature,
+		Name: name,
+		Path: filepath.Join(artifactPathBase, sigFilename),
+		Extra: map[string]interface{}{
+			"ID":       cfg.ID,
+			"Artifact": a.Name,
+		},
+	}, nil
+}
+
+// run runs the sign command of the given config, expanding its args with
+// the given env.
+func run(ctx *context.Context, cfg config.Sign, env map[string]string) error {
 	// nolint:prealloc
 	var args []string
 	for _, a := range cfg.Args {
 		var arg = expand(a, env)
 		arg, err := tmpl.New(ctx).WithEnv(env).Apply(arg)
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: %s: invalid template: %w", a, err)
+			return fmt.Errorf("sign failed: %s: invalid template: %w", a, err)
 		}
 		args = append(args, arg)
 	}
@@ -128,7 +180,7 @@ func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*arti
 	} else if cfg.StdinFile != "" {
 		f, err := os.Open(cfg.StdinFile)
 		if err != nil {
-			return nil, fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
+			return fmt.Errorf("sign failed: cannot open file %s: %w", cfg.StdinFile, err)
 		}
 		defer f.Close()
 
@@ -147,23 +199,9 @@ func signone(ctx *context.Context, cfg config.Sign, a *artifact.Artifact) (*arti
 	}
 	log.WithField("cmd", cmd.Args).Info("signing")
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("sign: %s failed", cfg.Cmd)
+		return fmt.Errorf("sign: %s failed", cfg.Cmd)
 	}
-
-	artifactPathBase, _ := filepath.Split(a.Path)
-
-	env["artifact"] = a.Name
-	name := expand(cfg.Signature, env)
-
-	sigFilename := filepath.Base(env["signature"])
-	return &artifact.Artifact{
-		Type: artifact.Signature,
-		Name: name,
-		Path: filepath.Join(artifactPathBase, sigFilename),
-		Extra: map[string]interface{}{
-			"ID": cfg.ID,
-		},
-	}, nil
+	return nil
 }
 
 func expand(s string, env map[string]string) string {
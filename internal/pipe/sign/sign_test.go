@@ -72,6 +72,49 @@ func TestSignSkipped(t *testing.T) {
 	require.EqualError(t, err, "artifact signing is disabled")
 }
 
+func TestSignAlreadySigned(t *testing.T) {
+	var folder = t.TempDir()
+	ctx := context.New(config.Project{})
+	ctx.Config.Signs = []config.Sign{
+		{
+			ID:        "default",
+			Cmd:       "touch",
+			Args:      []string{"$signature"},
+			Signature: "${artifact}.sig",
+			Artifacts: "all",
+		},
+	}
+	for _, name := range []string{"a.tar.gz", "b.tar.gz"} {
+		var path = filepath.Join(folder, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(name), 0644))
+		ctx.Artifacts.Add(&artifact.Artifact{
+			Name: name,
+			Path: path,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	// a previous publish signed a.tar.gz
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "a.tar.gz.sig",
+		Path: filepath.Join(folder, "a.tar.gz.sig"),
+		Type: artifact.Signature,
+		Extra: map[string]interface{}{
+			"ID":       "default",
+			"Artifact": "a.tar.gz",
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.NoFileExists(t, filepath.Join(folder, "a.tar.gz.sig"))
+	require.FileExists(t, filepath.Join(folder, "b.tar.gz.sig"))
+	var sigs = ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List()
+	require.Len(t, sigs, 2)
+	require.Equal(t, "b.tar.gz.sig", sigs[1].Name)
+
+	// publishing again doesn't sign anything again
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List(), 2)
+}
+
 func TestSignInvalidArtifacts(t *testing.T) {
 	ctx := context.New(config.Project{})
 	ctx.Config.Signs = []config.Sign{
@@ -133,8 +176,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 		},
 		{
 			desc: "sign all artifacts",
@@ -147,8 +190,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 		},
 		{
 			desc: "multiple sign configs",
@@ -192,8 +235,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 		},
 		{
 			desc: "sign only checksums",
@@ -238,6 +281,34 @@ func TestSignArtifacts(t *testing.T) {
 			signaturePaths: []string{"artifact5.tar.gz.sig"},
 			signatureNames: []string{"artifact5.tar.gz.sig"},
 		},
+		{
+			desc: "sign only sbom",
+			ctx: context.New(
+				config.Project{
+					Signs: []config.Sign{
+						{
+							Artifacts: "sbom",
+						},
+					},
+				},
+			),
+			signaturePaths: []string{"artifact6.spdx.json.sig"},
+			signatureNames: []string{"artifact6.spdx.json.sig"},
+		},
+		{
+			desc: "sign only attestation",
+			ctx: context.New(
+				config.Project{
+					Signs: []config.Sign{
+						{
+							Artifacts: "attestation",
+						},
+					},
+				},
+			),
+			signaturePaths: []string{"artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact7.intoto.json.sig"},
+		},
 		{
 			desc: "sign all artifacts with env",
 			ctx: context.New(
@@ -260,8 +331,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 		},
 		{
 			desc: "sign all artifacts with template",
@@ -285,8 +356,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 		},
 		{
 			desc: "sign single with password from stdin",
@@ -313,8 +384,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 			user:           passwordUser,
 		},
 		{
@@ -342,8 +413,8 @@ func TestSignArtifacts(t *testing.T) {
 					},
 				},
 			),
-			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig"},
-			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig"},
+			signaturePaths: []string{"artifact1.sig", "artifact2.sig", "artifact3.sig", "checksum.sig", "checksum2.sig", "linux_amd64/artifact4.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
+			signatureNames: []string{"artifact1.sig", "artifact2.sig", "artifact3_1.0.0_linux_amd64.sig", "checksum.sig", "checksum2.sig", "artifact4_1.0.0_linux_amd64.sig", "artifact5.tar.gz.sig", "artifact6.spdx.json.sig", "artifact7.intoto.json.sig"},
 			user:           passwordUser,
 		},
 		{
@@ -400,6 +471,10 @@ func testSign(t *testing.T, ctx *context.Context, signaturePaths []string, signa
 	artifacts = append(artifacts, "linux_amd64/artifact4")
 	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, "artifact5.tar.gz"), []byte("foo"), 0644))
 	artifacts = append(artifacts, "artifact5.tar.gz")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, "artifact6.spdx.json"), []byte("foo"), 0644))
+	artifacts = append(artifacts, "artifact6.spdx.json")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpdir, "artifact7.intoto.json"), []byte("foo"), 0644))
+	artifacts = append(artifacts, "artifact7.intoto.json")
 	ctx.Artifacts.Add(&artifact.Artifact{
 		Name: "artifact1",
 		Path: filepath.Join(tmpdir, "artifact1"),
@@ -447,6 +522,19 @@ func testSign(t *testing.T, ctx *context.Context, signaturePaths []string, signa
 		Path: filepath.Join(tmpdir, "artifact5.tar.gz"),
 		Type: artifact.UploadableSourceArchive,
 	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "artifact6.spdx.json",
+		Path: filepath.Join(tmpdir, "artifact6.spdx.json"),
+		Type: artifact.SBOM,
+		Extra: map[string]interface{}{
+			"ID": "foo",
+		},
+	})
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name: "artifact7.intoto.json",
+		Path: filepath.Join(tmpdir, "artifact7.intoto.json"),
+		Type: artifact.Attestation,
+	})
 
 	// configure the pipeline
 	// make sure we are using the test keyring
@@ -0,0 +1,108 @@
+// Package state provides a pipe that saves the release state to the dist
+// folder, so a release prepared on one machine can be signed and published
+// from another one.
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Filename is the name of the state file inside the dist folder.
+const Filename = "context.json"
+
+// Pipe that writes the release state to dist.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "saving release state"
+}
+
+// State is everything a prepared release needs to be published later on.
+type State struct {
+	Config       config.Project       `json:"config"`
+	Git          context.GitInfo      `json:"git"`
+	Semver       context.Semver       `json:"semver"`
+	Version      string               `json:"version"`
+	Date         time.Time            `json:"date"`
+	ReleaseNotes string               `json:"release_notes"`
+	PreRelease   bool                 `json:"prerelease"`
+	Snapshot     bool                 `json:"snapshot"`
+	Artifacts    []*artifact.Artifact `json:"artifacts"`
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var state = State{
+		Config:       ctx.Config,
+		Git:          ctx.Git,
+		Semver:       ctx.Semver,
+		Version:      ctx.Version,
+		Date:         ctx.Date,
+		ReleaseNotes: ctx.ReleaseNotes,
+		PreRelease:   ctx.PreRelease,
+		Snapshot:     ctx.Snapshot,
+	}
+	for _, a := range ctx.Artifacts.List() {
+		// paths are stored relative to the project root so the state can be
+		// restored from another machine or folder.
+		var saved = *a
+		if filepath.IsAbs(saved.Path) {
+			if rel, err := filepath.Rel(wd, saved.Path); err == nil {
+				saved.Path = filepath.ToSlash(rel)
+			}
+		}
+		state.Artifacts = append(state.Artifacts, &saved)
+	}
+	bts, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, Filename)
+	log.WithField("file", path).Info("writing")
+	return ioutil.WriteFile(path, bts, 0644) //nolint: gosec
+}
+
+// Load reads the state saved in the given dist folder.
+func Load(dist string) (State, error) {
+	var state State
+	var path = filepath.Join(dist, Filename)
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return state, fmt.Errorf("failed to load release state, make sure to run `goreleaser release --prepare` first: %w", err)
+	}
+	if err := json.Unmarshal(bts, &state); err != nil {
+		return state, fmt.Errorf("failed to parse release state: %s: %w", path, err)
+	}
+	log.WithField("file", path).Info("loaded release state")
+	return state, nil
+}
+
+// Restore sets the state into the given context.
+func (s State) Restore(ctx *context.Context) {
+	ctx.Config = s.Config
+	ctx.Git = s.Git
+	ctx.Semver = s.Semver
+	ctx.Version = s.Version
+	ctx.Date = s.Date
+	ctx.ReleaseNotes = s.ReleaseNotes
+	ctx.PreRelease = s.PreRelease
+	ctx.Snapshot = s.Snapshot
+	ctx.Artifacts = artifact.New()
+	for _, a := range s.Artifacts {
+		ctx.Artifacts.Add(a)
+	}
+}
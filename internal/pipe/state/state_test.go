@@ -0,0 +1,105 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.Mkdir("dist", 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Dist:        "dist",
+		Builds: []config.Build{
+			{ID: "foo", Binary: "foo", Goos: []string{"linux"}},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		Commit:     "a1b2c3",
+		CommitDate: time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+	ctx.Semver = context.Semver{Major: 1, RawVersion: "1.0.0"}
+	ctx.Date = time.Date(2020, 10, 2, 12, 0, 0, 0, time.UTC)
+	ctx.ReleaseNotes = "some notes"
+	ctx.PreRelease = true
+	var bin = &artifact.Artifact{
+		Name:   "foo",
+		Path:   filepath.Join(folder, "dist", "foo_linux_amd64", "foo"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra: map[string]interface{}{
+			"ID": "foo",
+		},
+	}
+	ctx.Artifacts.Add(bin)
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo.tar.gz",
+		Path:   "dist/foo.tar.gz",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+		Extra: map[string]interface{}{
+			"ID":     "default",
+			"Builds": []*artifact.Artifact{bin},
+		},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+
+	st, err := Load("dist")
+	require.NoError(t, err)
+	var restored = context.New(st.Config)
+	st.Restore(restored)
+
+	require.Equal(t, ctx.Config, restored.Config)
+	require.Equal(t, ctx.Git, restored.Git)
+	require.Equal(t, ctx.Semver, restored.Semver)
+	require.Equal(t, ctx.Version, restored.Version)
+	require.True(t, ctx.Date.Equal(restored.Date))
+	require.Equal(t, ctx.ReleaseNotes, restored.ReleaseNotes)
+	require.True(t, restored.PreRelease)
+
+	var artifacts = restored.Artifacts.List()
+	require.Len(t, artifacts, 2)
+	require.Equal(t, "dist/foo_linux_amd64/foo", artifacts[0].Path)
+	require.Equal(t, artifact.Binary, artifacts[0].Type)
+	require.Equal(t, "dist/foo.tar.gz", artifacts[1].Path)
+	require.Equal(t, artifact.UploadableArchive, artifacts[1].Type)
+	require.Len(t, artifacts[1].ExtraOr("Builds", []*artifact.Artifact{}).([]*artifact.Artifact), 1)
+}
+
+func TestLoadMissing(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	_, err := Load("dist")
+	require.Error(t, err)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, back := testlib.Mktmp(t)
+	defer back()
+	require.NoError(t, os.Mkdir("dist", 0755))
+	f, err := os.Create(filepath.Join("dist", Filename))
+	require.NoError(t, err)
+	_, err = f.WriteString("not json")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = Load("dist")
+	require.Error(t, err)
+}
@@ -0,0 +1,199 @@
+// Package universalbinary merges the darwin binaries of a build into a
+// macOS universal binary.
+package universalbinary
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/ids"
+	"github.com/goreleaser/goreleaser/internal/pipe"
+	"github.com/goreleaser/goreleaser/internal/semerrgroup"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+)
+
+// Pipe for universal binaries.
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "universal binaries"
+}
+
+// Default sets the pipe defaults.
+func (Pipe) Default(ctx *context.Context) error {
+	var ids = ids.New("universal_binaries")
+	for i := range ctx.Config.UniversalBinaries {
+		var unibin = &ctx.Config.UniversalBinaries[i]
+		if unibin.ID == "" {
+			unibin.ID = ctx.Config.ProjectName
+		}
+		if unibin.NameTemplate == "" {
+			unibin.NameTemplate = "{{ .ProjectName }}"
+		}
+		ids.Inc(unibin.ID)
+	}
+	return ids.Validate()
+}
+
+// Run the pipe.
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.UniversalBinaries) == 0 {
+		return pipe.Skip("universal_binaries section is not configured")
+	}
+	var g = semerrgroup.New(ctx.Parallelism)
+	for _, unibin := range ctx.Config.UniversalBinaries {
+		unibin := unibin
+		g.Go(func() error {
+			return makeUniversalBinary(ctx, unibin)
+		})
+	}
+	return g.Wait()
+}
+
+func makeUniversalBinary(ctx *context.Context, unibin config.UniversalBinary) error {
+	var filter = artifact.And(
+		artifact.ByType(artifact.Binary),
+		artifact.ByGoos("darwin"),
+		artifact.ByIDs(unibin.ID),
+	)
+	var binaries = ctx.Artifacts.Filter(filter).List()
+	if len(binaries) == 0 && ctx.Partial() {
+		log.WithField("id", unibin.ID).Info("no darwin binaries were built, skipping")
+		return nil
+	}
+	if len(binaries) == 0 {
+		return fmt.Errorf("no darwin binaries found with id %s", unibin.ID)
+	}
+	name, err := tmpl.New(ctx).Apply(unibin.NameTemplate)
+	if err != nil {
+		return err
+	}
+	var path = filepath.Join(ctx.Config.Dist, unibin.ID+"_darwin_all", name)
+	log.WithField("binary", path).Info("creating universal binary")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := writeFat(path, binaries); err != nil {
+		return fmt.Errorf("failed to create universal binary %s: %w", path, err)
+	}
+	if unibin.Replace {
+		ctx.Artifacts.Remove(filter)
+	}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Type:   artifact.Binary,
+		Name:   name,
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "all",
+		Extra: map[string]interface{}{
+			"Binary":   name,
+			"Ext":      "",
+			"ID":       unibin.ID,
+			"Replaces": unibin.Replace,
+		},
+	})
+	return nil
+}
+
+const (
+	// fatHeaderSize is the size of the header of a fat file: its magic and
+	// number of architectures.
+	fatHeaderSize = 8
+	// fatArchSize is the size of the header of each architecture.
+	fatArchSize = 20
+)
+
+type fatArch struct {
+	cpu    macho.Cpu
+	subCpu uint32
+	align  uint32
+	data   []byte
+}
+
+// writeFat writes a universal binary with all the given binaries, laid out
+// like lipo does.
+func writeFat(path string, binaries []*artifact.Artifact) error {
+	var arches = make([]fatArch, 0, len(binaries))
+	for _, binary := range binaries {
+		arch, err := readArch(binary.Path)
+		if err != nil {
+			return err
+		}
+		arches = append(arches, arch)
+	}
+	sort.Slice(arches, func(i, j int) bool {
+		return arches[i].cpu < arches[j].cpu
+	})
+
+	var header = []uint32{macho.MagicFat, uint32(len(arches))}
+	var offset = uint64(fatHeaderSize + fatArchSize*len(arches))
+	var offsets = make([]uint64, len(arches))
+	for i, arch := range arches {
+		offset = align(offset, 1<<arch.align)
+		offsets[i] = offset
+		offset += uint64(len(arch.data))
+	}
+	if offset > math.MaxUint32 {
+		return fmt.Errorf("universal binary would be bigger than 4GB")
+	}
+	for i, arch := range arches {
+		header = append(header, uint32(arch.cpu), arch.subCpu, uint32(offsets[i]), uint32(len(arch.data)), arch.align)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := binary.Write(f, binary.BigEndian, header); err != nil {
+		return err
+	}
+	var written = uint64(fatHeaderSize + fatArchSize*len(arches))
+	for i, arch := range arches {
+		if _, err := f.Write(make([]byte, offsets[i]-written)); err != nil {
+			return err
+		}
+		if _, err := f.Write(arch.data); err != nil {
+			return err
+		}
+		written = offsets[i] + uint64(len(arch.data))
+	}
+	return f.Close()
+}
+
+func readArch(path string) (fatArch, error) {
+	f, err := macho.Open(path)
+	if err != nil {
+		return fatArch{}, fmt.Errorf("%s is not a mach-o binary: %w", path, err)
+	}
+	defer f.Close()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fatArch{}, err
+	}
+	// arm64 pages are 16KB, and 4KB everywhere else
+	var alignment uint32 = 12
+	if f.Cpu == macho.CpuArm64 {
+		alignment = 14
+	}
+	return fatArch{
+		cpu:    f.Cpu,
+		subCpu: f.SubCpu,
+		align:  alignment,
+		data:   data,
+	}, nil
+}
+
+func align(offset, alignment uint64) uint64 {
+	return (offset + alignment - 1) / alignment * alignment
+}
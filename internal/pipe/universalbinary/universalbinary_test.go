@@ -0,0 +1,228 @@
+package universalbinary
+
+import (
+	"debug/macho"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/goreleaser/goreleaser/pkg/context"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	require.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName:       "proj",
+		UniversalBinaries: []config.UniversalBinary{{}},
+	})
+	require.NoError(t, Pipe{}.Default(ctx))
+	require.Equal(t, config.UniversalBinary{
+		ID:           "proj",
+		NameTemplate: "{{ .ProjectName }}",
+	}, ctx.Config.UniversalBinaries[0])
+}
+
+func TestDefaultDuplicatedID(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName:       "proj",
+		UniversalBinaries: []config.UniversalBinary{{}, {ID: "proj"}},
+	})
+	require.EqualError(t, Pipe{}.Default(ctx), "found 2 universal_binaries with the ID 'proj', please fix your config")
+}
+
+func TestSkip(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestRun(t *testing.T) {
+	var dist = t.TempDir()
+	var amd64 = buildDarwin(t, dist, "amd64")
+	var arm64 = buildDarwin(t, dist, "arm64")
+
+	for name, replace := range map[string]bool{
+		"keep":    false,
+		"replace": true,
+	} {
+		replace := replace
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				ProjectName: "proj",
+				Dist:        dist,
+				UniversalBinaries: []config.UniversalBinary{
+					{Replace: replace, NameTemplate: "{{ .ProjectName }}_{{ .Env.FOO }}"},
+				},
+			})
+			ctx.Env["FOO"] = "bar"
+			require.NoError(t, Pipe{}.Default(ctx))
+			ctx.Artifacts.Add(amd64)
+			ctx.Artifacts.Add(arm64)
+			ctx.Artifacts.Add(&artifact.Artifact{
+				Name:   "proj",
+				Path:   "proj",
+				Goos:   "linux",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"ID": "proj"},
+			})
+			require.NoError(t, Pipe{}.Run(ctx))
+
+			var path = filepath.Join(dist, "proj_darwin_all", "proj_bar")
+			var universal = ctx.Artifacts.Filter(artifact.ByGoarch("all")).List()
+			require.Equal(t, []*artifact.Artifact{{
+				Name:   "proj_bar",
+				Path:   path,
+				Goos:   "darwin",
+				Goarch: "all",
+				Type:   artifact.Binary,
+				Extra: map[string]interface{}{
+					"Binary":   "proj_bar",
+					"Ext":      "",
+					"ID":       "proj",
+					"Replaces": replace,
+				},
+			}}, universal)
+
+			var darwin = ctx.Artifacts.Filter(artifact.And(
+				artifact.ByGoos("darwin"),
+				artifact.Or(artifact.ByGoarch("amd64"), artifact.ByGoarch("arm64")),
+			)).List()
+			if replace {
+				require.Empty(t, darwin)
+			} else {
+				require.Len(t, darwin, 2)
+			}
+			require.Len(t, ctx.Artifacts.Filter(artifact.ByGoos("linux")).List(), 1)
+
+			f, err := macho.OpenFat(path)
+			require.NoError(t, err)
+			defer f.Close()
+			require.Len(t, f.Arches, 2)
+			require.Equal(t, macho.CpuAmd64, f.Arches[0].Cpu)
+			require.Equal(t, macho.CpuArm64, f.Arches[1].Cpu)
+			require.Zero(t, f.Arches[0].Offset%(1<<12))
+			require.Zero(t, f.Arches[1].Offset%(1<<14))
+			for i, binary := range []*artifact.Artifact{amd64, arm64} {
+				bts, err := ioutil.ReadFile(binary.Path)
+				require.NoError(t, err)
+				require.Equal(t, uint32(len(bts)), f.Arches[i].Size)
+			}
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0755), info.Mode())
+		})
+	}
+}
+
+func TestRunPartial(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Dist: t.TempDir(),
+		UniversalBinaries: []config.UniversalBinary{
+			{ID: "foo", NameTemplate: "foo"},
+		},
+	})
+	ctx.SingleTarget = true
+	ctx.Targets = []string{"linux_amd64"}
+	ctx.Artifacts.Add(&artifact.Artifact{
+		Name:   "foo",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"ID": "foo"},
+	})
+	require.NoError(t, Pipe{}.Run(ctx))
+	require.Empty(t, ctx.Artifacts.Filter(artifact.ByGoarch("all")).List())
+}
+
+func TestRunErrors(t *testing.T) {
+	var dist = t.TempDir()
+	var notMacho = filepath.Join(dist, "notmacho")
+	require.NoError(t, ioutil.WriteFile(notMacho, []byte("nope"), 0755))
+
+	for name, tt := range map[string]struct {
+		unibin    config.UniversalBinary
+		artifacts []*artifact.Artifact
+		err       string
+	}{
+		"no binaries": {
+			unibin: config.UniversalBinary{ID: "foo", NameTemplate: "foo"},
+			artifacts: []*artifact.Artifact{{
+				Name:   "bar",
+				Goos:   "darwin",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"ID": "bar"},
+			}},
+			err: "no darwin binaries found with id foo",
+		},
+		"invalid template": {
+			unibin: config.UniversalBinary{ID: "foo", NameTemplate: "{{ .Foo }"},
+			artifacts: []*artifact.Artifact{{
+				Name:   "foo",
+				Goos:   "darwin",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"ID": "foo"},
+			}},
+			err: `template: tmpl:1: unexpected "}" in operand`,
+		},
+		"not mach-o": {
+			unibin: config.UniversalBinary{ID: "foo", NameTemplate: "foo"},
+			artifacts: []*artifact.Artifact{{
+				Name:   "foo",
+				Path:   notMacho,
+				Goos:   "darwin",
+				Goarch: "amd64",
+				Type:   artifact.Binary,
+				Extra:  map[string]interface{}{"ID": "foo"},
+			}},
+			err: "failed to create universal binary " + filepath.Join(dist, "foo_darwin_all", "foo") +
+				": " + notMacho + " is not a mach-o binary: invalid magic number in record at byte 0x0",
+		},
+	} {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Dist:              dist,
+				UniversalBinaries: []config.UniversalBinary{tt.unibin},
+			})
+			for _, a := range tt.artifacts {
+				ctx.Artifacts.Add(a)
+			}
+			require.EqualError(t, Pipe{}.Run(ctx), tt.err)
+		})
+	}
+}
+
+func buildDarwin(t *testing.T, dist, arch string) *artifact.Artifact {
+	t.Helper()
+	var folder = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "main.go"),
+		[]byte("package main\nfunc main() {println(0)}"),
+		0644,
+	))
+	var path = filepath.Join(dist, "proj_darwin_"+arch, "proj")
+	var cmd = exec.Command("go", "build", "-o", path, "main.go")
+	cmd.Dir = folder
+	cmd.Env = append(os.Environ(), "GOOS=darwin", "GOARCH="+arch, "CGO_ENABLED=0", "GO111MODULE=off")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return &artifact.Artifact{
+		Name:   "proj",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: arch,
+		Type:   artifact.Binary,
+		Extra:  map[string]interface{}{"ID": "proj"},
+	}
+}
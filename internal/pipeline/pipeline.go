@@ -4,6 +4,7 @@ package pipeline
 import (
 	"fmt"
 
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/semver"
 	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
 
@@ -18,11 +19,17 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/effectiveconfig"
 	"github.com/goreleaser/goreleaser/internal/pipe/env"
 	"github.com/goreleaser/goreleaser/internal/pipe/git"
+	"github.com/goreleaser/goreleaser/internal/pipe/metadata"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/postprocess"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/internal/pipe/publish"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
+	"github.com/goreleaser/goreleaser/internal/pipe/state"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -47,18 +54,75 @@ var BuildPipeline = []Piper{
 	effectiveconfig.Pipe{}, // writes the actual config (with defaults et al set) to dist
 	changelog.Pipe{},       // builds the release changelog
 	build.Pipe{},           // build
+	postprocess.Pipe{},     // run upx, strip, etc on the binaries
+	universalbinary.Pipe{}, // merge darwin binaries into universal binaries
 }
 
-// Pipeline contains all pipe implementations in order
+// BuildCmdPipeline is the pipeline run by `goreleaser build`.
+// nolint: gochecknoglobals
+var BuildCmdPipeline = join(
+	BuildPipeline,
+	[]Piper{
+		metadata.Pipe{}, // writes artifacts.json and metadata.json to dist
+	},
+)
+
+// PreparePipeline contains all pipes needed to build and package a release,
+// without signing nor publishing it.
 // nolint: gochecknoglobals
-var Pipeline = append(
+var PreparePipeline = join(
 	BuildPipeline,
-	archive.Pipe{},       // archive in tar.gz, zip or binary (which does no archiving at all)
-	sourcearchive.Pipe{}, // archive the source code using git-archive
-	nfpm.Pipe{},          // archive via fpm (deb, rpm) using "native" go impl
-	snapcraft.Pipe{},     // archive via snapcraft (snap)
-	checksums.Pipe{},     // checksums of the files
-	sign.Pipe{},          // sign artifacts
-	docker.Pipe{},        // create and push docker images
-	publish.Pipe{},       // publishes artifacts
+	[]Piper{
+		archive.Pipe{},       // archive in tar.gz, zip or binary (which does no archiving at all)
+		sbom.Pipe{},          // software bill of materials of the binaries and archives
+		sourcearchive.Pipe{}, // archive the source code using git-archive
+		nfpm.Pipe{},          // archive via fpm (deb, rpm) using "native" go impl
+		msi.Pipe{},           // windows installers via wixl or the wix toolset
+		snapcraft.Pipe{},     // archive via snapcraft (snap)
+		checksums.Pipe{},     // checksums of the files
+		provenance.Pipe{},    // in-toto provenance attestation of the files
+		docker.Pipe{},        // create docker images
+	},
 )
+
+// PrepareCmdPipeline is the pipeline run by `goreleaser release --prepare`.
+// nolint: gochecknoglobals
+var PrepareCmdPipeline = join(
+	PreparePipeline,
+	[]Piper{
+		metadata.Pipe{}, // writes artifacts.json and metadata.json to dist
+		state.Pipe{},    // saves the release state so it can be published later
+	},
+)
+
+// PublishPipeline contains the pipes that sign and publish a prepared release.
+// nolint: gochecknoglobals
+var PublishPipeline = []Piper{
+	sign.Pipe{},     // sign artifacts
+	state.Pipe{},    // saves the release state so a failed publish can be resumed
+	publish.Pipe{},  // publishes artifacts
+	metadata.Pipe{}, // writes artifacts.json and metadata.json to dist
+}
+
+// PublishCmdPipeline is the pipeline run by `goreleaser publish`.
+// nolint: gochecknoglobals
+var PublishCmdPipeline = join(
+	[]Piper{
+		env.Pipe{}, // load and validate environment variables
+	},
+	PublishPipeline,
+)
+
+// Pipeline contains all pipe implementations in order
+// nolint: gochecknoglobals
+var Pipeline = join(PreparePipeline, PublishPipeline)
+
+// join concatenates the given pipelines into a new one, so they never share
+// the same underlying array.
+func join(pipelines ...[]Piper) []Piper {
+	var result []Piper
+	for _, p := range pipelines {
+		result = append(result, p...)
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+// Package progress records which publishers and uploads of a release already
+// succeeded, so a failed publish can be resumed without redoing them.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Filename is the name of the progress file inside the dist folder.
+const Filename = "publish.json"
+
+// Progress of a publish.
+// All methods are safe to be called on a nil Progress, in which case nothing
+// is recorded and nothing is reported as done.
+type Progress struct {
+	Publishers map[string]bool            `json:"publishers"`
+	Uploads    map[string]map[string]bool `json:"uploads"`
+
+	path string
+	lock sync.Mutex
+}
+
+// New returns an empty progress which will be saved in the given dist folder.
+func New(dist string) *Progress {
+	return &Progress{
+		Publishers: map[string]bool{},
+		Uploads:    map[string]map[string]bool{},
+		path:       filepath.Join(dist, Filename),
+	}
+}
+
+// Load loads the progress previously saved in the given dist folder.
+// If there is no progress saved yet, an empty one is returned.
+func Load(dist string) (*Progress, error) {
+	var p = New(dist)
+	bts, err := ioutil.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		log.WithField("file", p.path).Debug("no previous publish progress found")
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bts, p); err != nil {
+		return nil, fmt.Errorf("failed to parse publish progress: %s: %w", p.path, err)
+	}
+	log.WithField("file", p.path).Info("resuming previous publish")
+	return p, nil
+}
+
+// IsPublished tells whether the given publisher already finished.
+func (p *Progress) IsPublished(publisher string) bool {
+	if p == nil {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.Publishers[publisher]
+}
+
+// Published records the given publisher as finished.
+func (p *Progress) Published(publisher string) error {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Publishers[publisher] = true
+	return p.save()
+}
+
+// IsUploaded tells whether the given publisher already uploaded something
+// under the given name.
+func (p *Progress) IsUploaded(publisher, name string) bool {
+	if p == nil {
+		return false
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.Uploads[publisher][name]
+}
+
+// Uploaded records that the given publisher uploaded something under the
+// given name.
+func (p *Progress) Uploaded(publisher, name string) error {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.Uploads[publisher] == nil {
+		p.Uploads[publisher] = map[string]bool{}
+	}
+	p.Uploads[publisher][name] = true
+	return p.save()
+}
+
+func (p *Progress) save() error {
+	bts, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(p.path, bts, 0644); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to save publish progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package progress
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestProgress(t *testing.T) {
+	var dist = t.TempDir()
+	var p = New(dist)
+	require.False(t, p.IsPublished("release"))
+	require.False(t, p.IsUploaded("release", "foo.tar.gz"))
+
+	require.NoError(t, p.Published("blob"))
+	var g errgroup.Group
+	for _, name := range []string{"foo.tar.gz", "bar.tar.gz", "checksums.txt"} {
+		name := name
+		g.Go(func() error {
+			return p.Uploaded("release", name)
+		})
+	}
+	require.NoError(t, g.Wait())
+
+	loaded, err := Load(dist)
+	require.NoError(t, err)
+	require.True(t, loaded.IsPublished("blob"))
+	require.False(t, loaded.IsPublished("release"))
+	require.True(t, loaded.IsUploaded("release", "foo.tar.gz"))
+	require.True(t, loaded.IsUploaded("release", "bar.tar.gz"))
+	require.True(t, loaded.IsUploaded("release", "checksums.txt"))
+	require.False(t, loaded.IsUploaded("blob", "foo.tar.gz"))
+}
+
+func TestLoadMissing(t *testing.T) {
+	p, err := Load(t.TempDir())
+	require.NoError(t, err)
+	require.False(t, p.IsPublished("release"))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	var dist = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dist, Filename), []byte("nope"), 0644))
+	_, err := Load(dist)
+	require.Error(t, err)
+}
+
+func TestNil(t *testing.T) {
+	var p *Progress
+	require.False(t, p.IsPublished("release"))
+	require.False(t, p.IsUploaded("release", "foo"))
+	require.NoError(t, p.Published("release"))
+	require.NoError(t, p.Uploaded("release", "foo"))
+}
+
+func TestSaveFails(t *testing.T) {
+	var p = New(filepath.Join(t.TempDir(), "nope"))
+	require.Error(t, p.Published("release"))
+}
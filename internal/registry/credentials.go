@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DockerCredentials returns the credentials for the given registry stored in
+// the docker CLI configuration (`$DOCKER_CONFIG/config.json`, or
+// `~/.docker/config.json`), as written by `docker login`.
+// Credential helpers are not supported.
+func DockerCredentials(registry string) (string, string, error) {
+	var dir = os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", nil
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	var cfg struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(bts, &cfg); err != nil {
+		return "", "", fmt.Errorf("failed to parse docker config: %w", err)
+	}
+	for key, auth := range cfg.Auths {
+		if configHost(key) != registry && !(registry == dockerHub && configHost(key) == "index.docker.io") {
+			continue
+		}
+		if auth.Auth == "" {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid docker config auth for %s: %w", key, err)
+		}
+		var parts = strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("invalid docker config auth for %s", key)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
+
+// configHost extracts the host of a docker config auths key, which might be
+// an URL like `https://index.docker.io/v1/`.
+func configHost(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	return strings.SplitN(key, "/", 2)[0]
+}
@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerCredentials(t *testing.T) {
+	var dir = t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{
+		"auths": {
+			"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+			"ghcr.io": {"username": "foo", "password": "bar"},
+			"broken.io": {"auth": "dXNlcg=="}
+		}
+	}`), 0644))
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", dir))
+	defer os.Unsetenv("DOCKER_CONFIG")
+
+	for registry, expected := range map[string][2]string{
+		"docker.io":   {"user", "pass"},
+		"ghcr.io":     {"foo", "bar"},
+		"quay.io":     {"", ""},
+		"localhost:5": {"", ""},
+	} {
+		t.Run(registry, func(t *testing.T) {
+			username, password, err := DockerCredentials(registry)
+			require.NoError(t, err)
+			require.Equal(t, expected[0], username)
+			require.Equal(t, expected[1], password)
+		})
+	}
+
+	_, _, err := DockerCredentials("broken.io")
+	require.EqualError(t, err, "invalid docker config auth for broken.io")
+}
+
+func TestDockerCredentialsNoConfig(t *testing.T) {
+	require.NoError(t, os.Setenv("DOCKER_CONFIG", t.TempDir()))
+	defer os.Unsetenv("DOCKER_CONFIG")
+	username, password, err := DockerCredentials("docker.io")
+	require.NoError(t, err)
+	require.Empty(t, username)
+	require.Empty(t, password)
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// ImageConfig is an OCI image configuration.
+type ImageConfig struct {
+	Created      *time.Time      `json:"created,omitempty"`
+	Author       string          `json:"author,omitempty"`
+	Architecture string          `json:"architecture"`
+	OS           string          `json:"os"`
+	Variant      string          `json:"variant,omitempty"`
+	Config       ContainerConfig `json:"config"`
+	RootFS       RootFS          `json:"rootfs"`
+	History      []History       `json:"history,omitempty"`
+}
+
+// ContainerConfig is the execution configuration of an image.
+type ContainerConfig struct {
+	User         string              `json:"User,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Volumes      map[string]struct{} `json:"Volumes,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	StopSignal   string              `json:"StopSignal,omitempty"`
+}
+
+// RootFS references the layers of an image by their uncompressed digests.
+type RootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+// History of a layer of an image.
+type History struct {
+	Created    *time.Time `json:"created,omitempty"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	Comment    string     `json:"comment,omitempty"`
+	EmptyLayer bool       `json:"empty_layer,omitempty"`
+}
+
+// Image is an image manifest along with its configuration.
+type Image struct {
+	Manifest Manifest
+	Config   ImageConfig
+}
+
+// Image gets the image with the given reference, picking the given platform
+// if the reference points to an index.
+func (c *Client) Image(ctx ctx.Context, repo, reference string, platform Platform) (Image, error) {
+	var image Image
+	bts, mediaType, err := c.Manifest(ctx, repo, reference,
+		MediaTypeImageIndex, MediaTypeImageManifest,
+		MediaTypeDockerManifestList, MediaTypeDockerManifest,
+	)
+	if err != nil {
+		return image, err
+	}
+	if mediaType == MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList {
+		var index Index
+		if err := json.Unmarshal(bts, &index); err != nil {
+			return image, fmt.Errorf("invalid index %s:%s: %w", repo, reference, err)
+		}
+		var digest string
+		for _, m := range index.Manifests {
+			if m.Platform != nil && m.Platform.OS == platform.OS &&
+				m.Platform.Architecture == platform.Architecture &&
+				(platform.Variant == "" || m.Platform.Variant == platform.Variant) {
+				digest = m.Digest
+				break
+			}
+		}
+		if digest == "" {
+			return image, fmt.Errorf("%s:%s has no image for %s/%s%s: %w", repo, reference, platform.OS, platform.Architecture, platform.Variant, ErrNotFound)
+		}
+		return c.Image(ctx, repo, digest, platform)
+	}
+	if err := json.Unmarshal(bts, &image.Manifest); err != nil {
+		return image, fmt.Errorf("invalid manifest %s:%s: %w", repo, reference, err)
+	}
+	r, err := c.Blob(ctx, repo, image.Manifest.Config.Digest)
+	if err != nil {
+		return image, err
+	}
+	defer r.Close()
+	cfg, err := ioutil.ReadAll(r)
+	if err != nil {
+		return image, err
+	}
+	if err := json.Unmarshal(cfg, &image.Config); err != nil {
+		return image, fmt.Errorf("invalid image config %s:%s: %w", repo, reference, err)
+	}
+	return image, nil
+}
@@ -0,0 +1,165 @@
+package registry
+
+import (
+	ctx "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AnnotationRefName is the annotation holding the reference of a manifest
+// in an OCI layout index.
+const AnnotationRefName = "org.opencontainers.image.ref.name"
+
+// Layout is an OCI image layout directory, see
+// https://github.com/opencontainers/image-spec/blob/master/image-layout.md.
+type Layout struct {
+	Path string
+}
+
+// NewLayout creates an empty OCI layout in the given directory.
+func NewLayout(path string) (Layout, error) {
+	var layout = Layout{Path: path}
+	if err := os.MkdirAll(filepath.Join(path, "blobs", "sha256"), 0755); err != nil {
+		return layout, fmt.Errorf("failed to create oci layout: %w", err)
+	}
+	if err := ioutil.WriteFile(
+		filepath.Join(path, "oci-layout"),
+		[]byte(`{"imageLayoutVersion":"1.0.0"}`),
+		0644,
+	); err != nil {
+		return layout, fmt.Errorf("failed to create oci layout: %w", err)
+	}
+	return layout, layout.writeIndex(Index{SchemaVersion: 2, Manifests: []Descriptor{}})
+}
+
+// BlobPath returns the path of the blob with the given digest.
+func (l Layout) BlobPath(digest string) string {
+	return filepath.Join(l.Path, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:"))
+}
+
+// WriteBlob writes the given content as a blob.
+func (l Layout) WriteBlob(mediaType string, content []byte) (Descriptor, error) {
+	var desc = Descriptor{
+		MediaType: mediaType,
+		Digest:    Digest(content),
+		Size:      int64(len(content)),
+	}
+	return desc, ioutil.WriteFile(l.BlobPath(desc.Digest), content, 0644)
+}
+
+// CopyBlob copies the given blob from a registry into the layout, checking
+// its digest.
+func (l Layout) CopyBlob(ctx ctx.Context, c *Client, repo string, desc Descriptor) error {
+	var path = l.BlobPath(desc.Digest)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	r, err := c.Blob(ctx, repo, desc.Digest)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := ioutil.TempFile(filepath.Dir(path), "tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	var h = sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download blob %s: %w", desc.Digest, err)
+	}
+	if digest := "sha256:" + hex.EncodeToString(h.Sum(nil)); digest != desc.Digest {
+		return fmt.Errorf("blob %s has wrong digest: %s", desc.Digest, digest)
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// AddManifest writes the given manifest as a blob and adds it to the index
+// of the layout, under the given references.
+func (l Layout) AddManifest(mediaType string, manifest []byte, refs ...string) (Descriptor, error) {
+	desc, err := l.WriteBlob(mediaType, manifest)
+	if err != nil {
+		return desc, err
+	}
+	index, err := l.Index()
+	if err != nil {
+		return desc, err
+	}
+	for _, ref := range refs {
+		var d = desc
+		d.Annotations = map[string]string{AnnotationRefName: ref}
+		index.Manifests = append(index.Manifests, d)
+	}
+	return desc, l.writeIndex(index)
+}
+
+// Index reads the index of the layout.
+func (l Layout) Index() (Index, error) {
+	var index Index
+	bts, err := ioutil.ReadFile(filepath.Join(l.Path, "index.json"))
+	if err != nil {
+		return index, fmt.Errorf("failed to read oci layout: %w", err)
+	}
+	if err := json.Unmarshal(bts, &index); err != nil {
+		return index, fmt.Errorf("failed to read oci layout: %w", err)
+	}
+	return index, nil
+}
+
+func (l Layout) writeIndex(index Index) error {
+	bts, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(l.Path, "index.json"), bts, 0644)
+}
+
+// Descriptor returns the descriptor of the manifest with the given reference
+// name in the layout.
+func (l Layout) Descriptor(name string) (Descriptor, error) {
+	index, err := l.Index()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	for _, desc := range index.Manifests {
+		if desc.Annotations[AnnotationRefName] == name {
+			return desc, nil
+		}
+	}
+	return Descriptor{}, fmt.Errorf("%s not found in oci layout %s", name, l.Path)
+}
+
+// Push pushes the manifest with the given reference name in the layout,
+// along with its blobs, to the given reference, returning the manifest
+// digest.
+func (l Layout) Push(ctx ctx.Context, c *Client, name string, ref Reference) (string, error) {
+	desc, err := l.Descriptor(name)
+	if err != nil {
+		return "", err
+	}
+	bts, err := ioutil.ReadFile(l.BlobPath(desc.Digest))
+	if err != nil {
+		return "", err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(bts, &manifest); err != nil {
+		return "", fmt.Errorf("invalid manifest %s: %w", desc.Digest, err)
+	}
+	for _, blob := range append([]Descriptor{manifest.Config}, manifest.Layers...) {
+		if _, err := c.PushFile(ctx, ref.Repository, blob.MediaType, l.BlobPath(blob.Digest)); err != nil {
+			return "", err
+		}
+	}
+	return c.PushManifest(ctx, ref.Repository, ref.Identifier(), desc.MediaType, bts)
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	var dir = filepath.Join(t.TempDir(), "layout")
+	layout, err := NewLayout(dir)
+	require.NoError(t, err)
+	bts, err := ioutil.ReadFile(filepath.Join(dir, "oci-layout"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"imageLayoutVersion":"1.0.0"}`, string(bts))
+	index, err := layout.Index()
+	require.NoError(t, err)
+	require.Empty(t, index.Manifests)
+
+	cfg, err := layout.WriteBlob(MediaTypeImageConfig, []byte("{}"))
+	require.NoError(t, err)
+	layer, err := layout.WriteBlob(MediaTypeImageLayer, []byte("layer"))
+	require.NoError(t, err)
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        cfg,
+		Layers:        []Descriptor{layer},
+	})
+	require.NoError(t, err)
+	desc, err := layout.AddManifest(MediaTypeImageManifest, manifest, "foo:v1", "foo:latest")
+	require.NoError(t, err)
+
+	index, err = layout.Index()
+	require.NoError(t, err)
+	require.Len(t, index.Manifests, 2)
+	require.Equal(t, "foo:v1", index.Manifests[0].Annotations[AnnotationRefName])
+	require.Equal(t, "foo:latest", index.Manifests[1].Annotations[AnnotationRefName])
+	require.Equal(t, desc.Digest, index.Manifests[1].Digest)
+
+	var reg = testlib.NewRegistry(t)
+	var c = New(reg.Host, Options{Insecure: true})
+	ref, err := ParseReference(reg.Host + "/foo/bar:v1")
+	require.NoError(t, err)
+	digest, err := layout.Push(context.Background(), c, "foo:v1", ref)
+	require.NoError(t, err)
+	require.Equal(t, desc.Digest, digest)
+	got, _, ok := reg.Manifest("foo/bar", "v1")
+	require.True(t, ok)
+	require.Equal(t, manifest, got)
+	content, ok := reg.Blob("foo/bar", layer.Digest)
+	require.True(t, ok)
+	require.Equal(t, "layer", string(content))
+
+	_, err = layout.Push(context.Background(), c, "nope", ref)
+	require.EqualError(t, err, "nope not found in oci layout "+dir)
+}
+
+func TestCopyBlob(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var c = New(reg.Host, Options{Insecure: true})
+	desc, err := c.PushBlob(context.Background(), "foo", MediaTypeImageLayer, []byte("layer"))
+	require.NoError(t, err)
+
+	layout, err := NewLayout(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, layout.CopyBlob(context.Background(), c, "foo", desc))
+	bts, err := ioutil.ReadFile(layout.BlobPath(desc.Digest))
+	require.NoError(t, err)
+	require.Equal(t, "layer", string(bts))
+
+	desc.Digest = Digest([]byte("nope"))
+	require.Error(t, layout.CopyBlob(context.Background(), c, "foo", desc))
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHub        = "docker.io"
+	dockerHubAPIHost = "registry-1.docker.io"
+)
+
+// Reference to an image or artifact in a registry, like
+// `ghcr.io/goreleaser/goreleaser:v1.0.0`.
+type Reference struct {
+	// Registry is the host of the registry.
+	Registry string
+	// Repository is the repository inside the registry.
+	Repository string
+	// Tag is the tag of the reference, empty if it has a digest.
+	Tag string
+	// Digest is the digest of the reference, if any.
+	Digest string
+}
+
+// ParseReference parses an image reference, with the same defaults as the
+// docker CLI: images without a registry are on Docker Hub, and images
+// without a tag or digest are tagged `latest`.
+func ParseReference(s string) (Reference, error) {
+	var ref Reference
+	var name = s
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !strings.HasPrefix(ref.Digest, "sha256:") {
+			return ref, fmt.Errorf("invalid reference: %s: unsupported digest", s)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if name == "" || ref.Tag == "" && strings.HasSuffix(name, ":") {
+		return ref, fmt.Errorf("invalid reference: %s", s)
+	}
+
+	var parts = strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = dockerHub
+		ref.Repository = name
+	}
+	if ref.Registry == dockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository != strings.ToLower(ref.Repository) {
+		return ref, fmt.Errorf("invalid reference: %s: repository must be lowercase", s)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// Host returns the host to use when talking to the registry API, which
+// differs from the registry name for Docker Hub.
+func (r Reference) Host() string {
+	if r.Registry == dockerHub {
+		return dockerHubAPIHost
+	}
+	return r.Registry
+}
+
+// Identifier returns the digest of the reference if any, its tag otherwise.
+func (r Reference) Identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// String returns the full reference.
+func (r Reference) String() string {
+	var s = r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
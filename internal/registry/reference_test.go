@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	for s, expected := range map[string]Reference{
+		"alpine":                    {Registry: "docker.io", Repository: "library/alpine", Tag: "latest"},
+		"alpine:3.12":               {Registry: "docker.io", Repository: "library/alpine", Tag: "3.12"},
+		"goreleaser/goreleaser:v1":  {Registry: "docker.io", Repository: "goreleaser/goreleaser", Tag: "v1"},
+		"ghcr.io/foo/bar":           {Registry: "ghcr.io", Repository: "foo/bar", Tag: "latest"},
+		"localhost/foo":             {Registry: "localhost", Repository: "foo", Tag: "latest"},
+		"localhost:5000/foo/bar:v1": {Registry: "localhost:5000", Repository: "foo/bar", Tag: "v1"},
+		"ghcr.io/foo@sha256:abc":    {Registry: "ghcr.io", Repository: "foo", Digest: "sha256:abc"},
+		"ghcr.io/foo:v1@sha256:abc": {Registry: "ghcr.io", Repository: "foo", Tag: "v1", Digest: "sha256:abc"},
+	} {
+		t.Run(s, func(t *testing.T) {
+			ref, err := ParseReference(s)
+			require.NoError(t, err)
+			require.Equal(t, expected, ref)
+		})
+	}
+}
+
+func TestParseReferenceInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		":latest",
+		"foo@md5:abc",
+		"ghcr.io/Foo/bar",
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseReference(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	ref, err := ParseReference("alpine")
+	require.NoError(t, err)
+	require.Equal(t, "registry-1.docker.io", ref.Host())
+	require.Equal(t, "latest", ref.Identifier())
+	require.Equal(t, "docker.io/library/alpine:latest", ref.String())
+
+	ref, err = ParseReference("ghcr.io/foo@sha256:abc")
+	require.NoError(t, err)
+	require.Equal(t, "ghcr.io", ref.Host())
+	require.Equal(t, "sha256:abc", ref.Identifier())
+	require.Equal(t, "ghcr.io/foo@sha256:abc", ref.String())
+}
@@ -0,0 +1,410 @@
+// Package registry implements a minimal client for the OCI distribution API,
+// enough to push and pull blobs and manifests.
+package registry
+
+import (
+	"bytes"
+	ctx "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Media types used by the OCI image and distribution specs.
+const (
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeImageConfig   = "application/vnd.oci.image.config.v1+json"
+	MediaTypeImageLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// ErrNotFound happens when a blob or manifest does not exist in the registry.
+var ErrNotFound = errors.New("not found")
+
+// Platform describes the platform an image manifest is built for.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Descriptor describes some content stored in a registry.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *Platform         `json:"platform,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is an OCI image index, also known as manifest list.
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Digest returns the sha256 digest of the given content.
+func Digest(content []byte) string {
+	var sum = sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Options to create a client.
+type Options struct {
+	Username string
+	Password string
+	// Insecure uses plain http instead of https.
+	Insecure bool
+}
+
+// Client of a registry.
+type Client struct {
+	host   string
+	opts   Options
+	http   *http.Client
+	lock   sync.Mutex
+	tokens map[string]string
+}
+
+// New creates a client for the registry at the given host.
+func New(host string, opts Options) *Client {
+	return &Client{
+		host:   host,
+		opts:   opts,
+		http:   http.DefaultClient,
+		tokens: map[string]string{},
+	}
+}
+
+func (c *Client) url(format string, args ...interface{}) string {
+	var scheme = "https"
+	if c.opts.Insecure {
+		scheme = "http"
+	}
+	return scheme + "://" + c.host + fmt.Sprintf(format, args...)
+}
+
+// PushBlob pushes the given content as a blob, unless the registry already
+// has it.
+func (c *Client) PushBlob(ctx ctx.Context, repo, mediaType string, content []byte) (Descriptor, error) {
+	var desc = Descriptor{
+		MediaType: mediaType,
+		Digest:    Digest(content),
+		Size:      int64(len(content)),
+	}
+	return desc, c.pushBlob(ctx, repo, desc, func() (io.Reader, error) {
+		return bytes.NewReader(content), nil
+	})
+}
+
+// PushFile pushes the file at the given path as a blob, unless the registry
+// already has it.
+func (c *Client) PushFile(ctx ctx.Context, repo, mediaType, path string) (Descriptor, error) {
+	f, err := os.Open(path) // #nosec
+	if err != nil {
+		return Descriptor{}, err
+	}
+	var h = sha256.New()
+	size, err := io.Copy(h, f)
+	_ = f.Close()
+	if err != nil {
+		return Descriptor{}, err
+	}
+	var desc = Descriptor{
+		MediaType: mediaType,
+		Digest:    "sha256:" + hex.EncodeToString(h.Sum(nil)),
+		Size:      size,
+	}
+	var files []io.Closer
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	return desc, c.pushBlob(ctx, repo, desc, func() (io.Reader, error) {
+		f, err := os.Open(path) // #nosec
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		return f, nil
+	})
+}
+
+func (c *Client) pushBlob(ctx ctx.Context, repo string, desc Descriptor, body func() (io.Reader, error)) error {
+	var log = log.WithField("repo", repo).WithField("digest", desc.Digest)
+	res, err := c.do(ctx, repo, http.MethodHead, c.url("/v2/%s/blobs/%s", repo, desc.Digest), "", nil, nil)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		log.Debug("blob already exists")
+		return nil
+	}
+
+	log.Debug("pushing blob")
+	res, err = c.do(ctx, repo, http.MethodPost, c.url("/v2/%s/blobs/uploads/", repo), "", nil, nil)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to start blob upload to %s: %s", repo, res.Status)
+	}
+	location, err := uploadLocation(res, desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	res, err = c.do(ctx, repo, http.MethodPut, location, "application/octet-stream", body, nil)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to upload blob %s to %s: %s", desc.Digest, repo, res.Status)
+	}
+	return nil
+}
+
+func uploadLocation(res *http.Response, digest string) (string, error) {
+	loc, err := res.Location()
+	if err != nil {
+		return "", fmt.Errorf("invalid blob upload location: %w", err)
+	}
+	var query = loc.Query()
+	query.Set("digest", digest)
+	loc.RawQuery = query.Encode()
+	return loc.String(), nil
+}
+
+// PushManifest pushes the given manifest under the given reference (a tag or
+// a digest) and returns its digest.
+func (c *Client) PushManifest(ctx ctx.Context, repo, reference, mediaType string, manifest []byte) (string, error) {
+	log.WithField("repo", repo).WithField("reference", reference).Debug("pushing manifest")
+	res, err := c.do(ctx, repo, http.MethodPut, c.url("/v2/%s/manifests/%s", repo, reference), mediaType, func() (io.Reader, error) {
+		return bytes.NewReader(manifest), nil
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		bts, _ := ioutil.ReadAll(res.Body)
+		return "", fmt.Errorf("failed to push manifest %s:%s: %s: %s", repo, reference, res.Status, string(bts))
+	}
+	var digest = Digest(manifest)
+	if d := res.Header.Get("Docker-Content-Digest"); d != "" && d != digest {
+		return "", fmt.Errorf("registry computed digest %s for manifest %s:%s, expected %s", d, repo, reference, digest)
+	}
+	return digest, nil
+}
+
+// Manifest gets the manifest with the given reference, returning its
+// content and media type.
+func (c *Client) Manifest(ctx ctx.Context, repo, reference string, accept ...string) ([]byte, string, error) {
+	var headers = http.Header{}
+	for _, a := range accept {
+		headers.Add("Accept", a)
+	}
+	res, err := c.do(ctx, repo, http.MethodGet, c.url("/v2/%s/manifests/%s", repo, reference), "", nil, headers)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, "", fmt.Errorf("manifest %s:%s: %w", repo, reference, ErrNotFound)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to get manifest %s:%s: %s", repo, reference, res.Status)
+	}
+	bts, err := ioutil.ReadAll(res.Body)
+	return bts, res.Header.Get("Content-Type"), err
+}
+
+// Blob gets the blob with the given digest.
+// The caller should close the returned reader.
+func (c *Client) Blob(ctx ctx.Context, repo, digest string) (io.ReadCloser, error) {
+	res, err := c.do(ctx, repo, http.MethodGet, c.url("/v2/%s/blobs/%s", repo, digest), "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("blob %s@%s: %w", repo, digest, ErrNotFound)
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, fmt.Errorf("failed to get blob %s@%s: %s", repo, digest, res.Status)
+	}
+	return res.Body, nil
+}
+
+// do executes a request, authenticating against the registry if it asks to.
+func (c *Client) do(ctx ctx.Context, repo, method, target, contentType string, body func() (io.Reader, error), headers http.Header) (*http.Response, error) {
+	var scope = "repository:" + repo + ":pull,push"
+	var newRequest = func() (*http.Request, error) {
+		var r io.Reader
+		if body != nil {
+			var err error
+			if r, err = body(); err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, r)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range headers {
+			req.Header[k] = v
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		c.authorize(req, scope)
+		return req, nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+	_ = res.Body.Close()
+	if err := c.login(ctx, res.Header.Get("WWW-Authenticate"), scope); err != nil {
+		return nil, err
+	}
+	if req, err = newRequest(); err != nil {
+		return nil, err
+	}
+	return c.http.Do(req)
+}
+
+func (c *Client) authorize(req *http.Request, scope string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if token, ok := c.tokens[scope]; ok {
+		if token == "" {
+			req.SetBasicAuth(c.opts.Username, c.opts.Password)
+			return
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// login handles the given authentication challenge, storing what is needed
+// to authorize the next requests.
+func (c *Client) login(ctx ctx.Context, challenge, scope string) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.opts.Username == "" {
+			return fmt.Errorf("registry %s requires authentication", c.host)
+		}
+		c.lock.Lock()
+		c.tokens[scope] = ""
+		c.lock.Unlock()
+		return nil
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, scope)
+		if err != nil {
+			return err
+		}
+		c.lock.Lock()
+		c.tokens[scope] = token
+		c.lock.Unlock()
+		return nil
+	default:
+		return fmt.Errorf("registry %s: unsupported authentication challenge: %q", c.host, challenge)
+	}
+}
+
+func (c *Client) fetchToken(ctx ctx.Context, params map[string]string, scope string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("registry %s: invalid token realm: %q", c.host, params["realm"])
+	}
+	var query = realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.opts.Username != "" {
+		req.SetBasicAuth(c.opts.Username, c.opts.Password)
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s: failed to get token: %s", c.host, res.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("registry %s: invalid token response: %w", c.host, err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header value like
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	var params = map[string]string{}
+	var parts = strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) < 2 {
+		return parts[0], params
+	}
+	for _, param := range strings.Split(parts[1], ",") {
+		var kv = strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return parts[0], params
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	require.Equal(t, "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", Digest([]byte("{}")))
+}
+
+func TestPushAndPull(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var c = New(reg.Host, Options{Insecure: true})
+	var ctx = context.Background()
+
+	blob, err := c.PushBlob(ctx, "foo/bar", MediaTypeImageConfig, []byte("{}"))
+	require.NoError(t, err)
+	require.Equal(t, Digest([]byte("{}")), blob.Digest)
+	require.EqualValues(t, 2, blob.Size)
+
+	var path = filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("lorem ipsum"), 0644))
+	layer, err := c.PushFile(ctx, "foo/bar", MediaTypeImageLayer, path)
+	require.NoError(t, err)
+	require.Equal(t, Digest([]byte("lorem ipsum")), layer.Digest)
+
+	// pushing again is a no-op
+	_, err = c.PushFile(ctx, "foo/bar", MediaTypeImageLayer, path)
+	require.NoError(t, err)
+
+	bts, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        blob,
+		Layers:        []Descriptor{layer},
+	})
+	require.NoError(t, err)
+	digest, err := c.PushManifest(ctx, "foo/bar", "v1.0.0", MediaTypeImageManifest, bts)
+	require.NoError(t, err)
+	require.Equal(t, Digest(bts), digest)
+
+	got, mediaType, err := c.Manifest(ctx, "foo/bar", "v1.0.0", MediaTypeImageManifest)
+	require.NoError(t, err)
+	require.Equal(t, MediaTypeImageManifest, mediaType)
+	require.Equal(t, bts, got)
+
+	r, err := c.Blob(ctx, "foo/bar", layer.Digest)
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "lorem ipsum", string(content))
+}
+
+func TestNotFound(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var c = New(reg.Host, Options{Insecure: true})
+	_, _, err := c.Manifest(context.Background(), "foo", "latest")
+	require.True(t, strings.Contains(err.Error(), "manifest foo:latest"))
+	require.True(t, errors.Is(err, ErrNotFound))
+	_, err = c.Blob(context.Background(), "foo", "sha256:nope")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestBasicAuth(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	reg.Username = "user"
+	reg.Password = "pass"
+
+	_, err := New(reg.Host, Options{Insecure: true}).
+		PushBlob(context.Background(), "foo", MediaTypeImageConfig, []byte("{}"))
+	require.EqualError(t, err, "registry "+reg.Host+" requires authentication")
+
+	_, err = New(reg.Host, Options{Insecure: true, Username: "user", Password: "pass"}).
+		PushBlob(context.Background(), "foo", MediaTypeImageConfig, []byte("{}"))
+	require.NoError(t, err)
+}
+
+func TestBearerAuth(t *testing.T) {
+	var reg = testlib.NewRegistry(t)
+	var auth = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		require.Equal(t, "user", user)
+		require.Equal(t, "pass", pass)
+		require.Equal(t, "registry.test", r.URL.Query().Get("service"))
+		require.Equal(t, "repository:foo:pull,push", r.URL.Query().Get("scope"))
+		_, _ = w.Write([]byte(`{"token":"s3cr3t"}`))
+	}))
+	t.Cleanup(auth.Close)
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+auth.URL+`/token",service="registry.test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	var c = New(strings.TrimPrefix(srv.URL, "http://"), Options{Insecure: true, Username: "user", Password: "pass"})
+	_, err := c.PushBlob(context.Background(), "foo", MediaTypeImageConfig, []byte("{}"))
+	require.NoError(t, err)
+	_, ok := reg.Blob("foo", Digest([]byte("{}")))
+	require.True(t, ok)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`)
+	require.Equal(t, "Bearer", scheme)
+	require.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+	}, params)
+
+	scheme, params = parseChallenge("Basic")
+	require.Equal(t, "Basic", scheme)
+	require.Empty(t, params)
+}
@@ -0,0 +1,153 @@
+package testlib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Registry is an in-memory OCI registry, good enough to push and pull
+// blobs and manifests in tests.
+type Registry struct {
+	// Host of the registry, to be used in image references.
+	Host string
+
+	// Username and Password, if set, are required as basic auth by the
+	// registry.
+	Username string
+	Password string
+
+	lock      sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string]registryManifest
+	uploads   int
+}
+
+type registryManifest struct {
+	mediaType string
+	content   []byte
+}
+
+var registryPathRe = regexp.MustCompile(`^/v2/(.+)/(blobs|manifests)/(uploads/)?(.*)$`)
+
+// NewRegistry starts an in-memory OCI registry, which is stopped when the
+// test ends.
+func NewRegistry(t *testing.T) *Registry {
+	var reg = &Registry{
+		blobs:     map[string][]byte{},
+		manifests: map[string]registryManifest{},
+	}
+	var srv = httptest.NewServer(reg)
+	t.Cleanup(srv.Close)
+	reg.Host = strings.TrimPrefix(srv.URL, "http://")
+	return reg
+}
+
+// Manifest returns the manifest stored under the given reference, if any.
+func (reg *Registry) Manifest(repo, reference string) ([]byte, string, bool) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	m, ok := reg.manifests[repo+":"+reference]
+	return m.content, m.mediaType, ok
+}
+
+// Blob returns the blob with the given digest, if any.
+func (reg *Registry) Blob(repo, digest string) ([]byte, bool) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	b, ok := reg.blobs[repo+"@"+digest]
+	return b, ok
+}
+
+// ServeHTTP implements the subset of the OCI distribution API used by
+// goreleaser.
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if reg.Username != "" {
+		if user, pass, ok := r.BasicAuth(); !ok || user != reg.Username || pass != reg.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="testlib"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	if r.URL.Path == "/v2/" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	var match = registryPathRe.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var repo, kind, upload, reference = match[1], match[2], match[3] != "", match[4]
+
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	switch {
+	case kind == "blobs" && upload && r.Method == http.MethodPost:
+		reg.uploads++
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d", repo, reg.uploads))
+		w.WriteHeader(http.StatusAccepted)
+	case kind == "blobs" && upload && r.Method == http.MethodPut:
+		bts, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var digest = r.URL.Query().Get("digest")
+		if digest != registryDigest(bts) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reg.blobs[repo+"@"+digest] = bts
+		w.WriteHeader(http.StatusCreated)
+	case kind == "blobs" && (r.Method == http.MethodHead || r.Method == http.MethodGet):
+		bts, ok := reg.blobs[repo+"@"+reference]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", reference)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(bts)
+		}
+	case kind == "manifests" && r.Method == http.MethodPut:
+		bts, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var m = registryManifest{mediaType: r.Header.Get("Content-Type"), content: bts}
+		var digest = registryDigest(bts)
+		reg.manifests[repo+":"+reference] = m
+		reg.manifests[repo+":"+digest] = m
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	case kind == "manifests" && (r.Method == http.MethodHead || r.Method == http.MethodGet):
+		m, ok := reg.manifests[repo+":"+reference]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", m.mediaType)
+		w.Header().Set("Docker-Content-Digest", registryDigest(m.content))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(m.content)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func registryDigest(bts []byte) string {
+	var sum = sha256.Sum256(bts)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -52,6 +52,7 @@ const (
 	binary       = "Binary"
 	artifactName = "ArtifactName"
 	artifactPath = "ArtifactPath"
+	digest       = "Digest"
 
 	// gitlab only.
 	artifactUploadHash = "ArtifactUploadHash"
@@ -131,6 +132,7 @@ func (t *Template) WithArtifact(a *artifact.Artifact, replacements map[string]st
 	t.fields[binary] = bin.(string)
 	t.fields[artifactName] = a.Name
 	t.fields[artifactPath] = a.Path
+	t.fields[digest] = a.ExtraOr(digest, "")
 	if val, ok := a.Extra["ArtifactUploadHash"]; ok {
 		t.fields[artifactUploadHash] = val
 	} else {
@@ -85,6 +85,21 @@ func TestWithArtifact(t *testing.T) {
 		require.Equal(tt, uploadHash, result)
 	})
 
+	t.Run("docker image with digest", func(tt *testing.T) {
+		tt.Parallel()
+		result, err := New(ctx).WithArtifact(
+			&artifact.Artifact{
+				Name: "user/image:v1.2.3",
+				Type: artifact.DockerImage,
+				Extra: map[string]interface{}{
+					"Digest": "sha256:abc",
+				},
+			}, map[string]string{},
+		).Apply("{{ .ArtifactName }}@{{ .Digest }}")
+		require.NoError(tt, err)
+		require.Equal(tt, "user/image:v1.2.3@sha256:abc", result)
+	})
+
 	t.Run("artifact without binary name", func(tt *testing.T) {
 		tt.Parallel()
 		result, err := New(ctx).WithArtifact(
@@ -1,35 +1,75 @@
-// Package archive provides tar.gz and zip archiving
+// Package archive provides tar.gz, tar.xz, tar.zst, tar.bz2, tar, zip and gz
+// archiving
 package archive
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/goreleaser/goreleaser/pkg/archive/gzip"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarbz2"
 	"github.com/goreleaser/goreleaser/pkg/archive/targz"
 	"github.com/goreleaser/goreleaser/pkg/archive/tarxz"
+	"github.com/goreleaser/goreleaser/pkg/archive/tarzst"
 	"github.com/goreleaser/goreleaser/pkg/archive/zip"
+	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
+// Formats are the supported archive formats, which are also the extensions of
+// their files.
+// nolint: gochecknoglobals
+var Formats = []string{"tar.gz", "tar.xz", "tar.zst", "tar.bz2", "tar", "zip", "gz"}
+
 // Archive represents a compression archive files from disk can be written to.
+// Add writes the file at the Source of f as its Destination, with its Info
+// taking precedence over the metadata of the file on disk.
 type Archive interface {
 	Close() error
-	Add(name, path string) error
+	Add(f config.File) error
 }
 
-// New archive.
-func New(file *os.File) Archive {
-	if strings.HasSuffix(file.Name(), ".tar.gz") {
-		return targz.New(file)
-	}
-	if strings.HasSuffix(file.Name(), ".gz") {
-		return gzip.New(file)
-	}
-	if strings.HasSuffix(file.Name(), ".tar.xz") {
-		return tarxz.New(file)
-	}
-	if strings.HasSuffix(file.Name(), ".zip") {
-		return zip.New(file)
+// Options of an archive.
+type Options struct {
+	// Level of compression, whose range depends on the format, e.g. 1 to 9
+	// for tar.gz or 1 to 22 for tar.zst. 0 is the best compression of the
+	// format, or the xz default for tar.xz. tar archives can't have one.
+	Level int
+
+	// Mtime is set as the modification time of all entries, which are also
+	// owned by root, so the archive only depends on their contents.
+	// The zero value keeps the metadata of the archived files.
+	Mtime time.Time
+}
+
+// New archive of the format given by the extension of the file.
+func New(file *os.File) (Archive, error) {
+	return NewWithOptions(file, Options{})
+}
+
+// NewWithOptions archive of the format given by the extension of the file.
+func NewWithOptions(file *os.File, opts Options) (Archive, error) {
+	var name = file.Name()
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"):
+		return targz.New(file, opts.Level, opts.Mtime)
+	case strings.HasSuffix(name, ".tar.xz"):
+		return tarxz.New(file, opts.Level, opts.Mtime)
+	case strings.HasSuffix(name, ".tar.zst"):
+		return tarzst.New(file, opts.Level, opts.Mtime)
+	case strings.HasSuffix(name, ".tar.bz2"):
+		return tarbz2.New(file, opts.Level, opts.Mtime)
+	case strings.HasSuffix(name, ".tar"):
+		if opts.Level != 0 {
+			return nil, fmt.Errorf("invalid compression level: %d: tar archives are not compressed", opts.Level)
+		}
+		return tar.New(file, opts.Mtime), nil
+	case strings.HasSuffix(name, ".gz"):
+		return gzip.New(file, opts.Level, opts.Mtime)
+	case strings.HasSuffix(name, ".zip"):
+		return zip.New(file, opts.Level, opts.Mtime)
 	}
-	return targz.New(file)
+	return nil, fmt.Errorf("unsupported archive format: %s: should be one of %s", name, strings.Join(Formats, ", "))
 }
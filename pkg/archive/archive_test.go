@@ -3,8 +3,11 @@ package archive
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,12 +18,12 @@ func TestArchive(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, os.Mkdir(folder+"/folder-inside", 0755))
 
-	for _, format := range []string{"tar.gz", "zip", "gz", "tar.xz", "willbeatargzanyway"} {
+	for _, format := range []string{"tar.gz", "zip", "gz", "tar.xz", "tar.zst", "tar.bz2", "tar"} {
 		format := format
 		t.Run(format, func(t *testing.T) {
 			var archive = newArchive(folder, format, t)
-			require.NoError(t, archive.Add("empty.txt", empty.Name()))
-			require.Error(t, archive.Add("dont.txt", empty.Name()+"_nope"))
+			require.NoError(t, archive.Add(config.File{Source: empty.Name(), Destination: "empty.txt"}))
+			require.Error(t, archive.Add(config.File{Source: empty.Name() + "_nope", Destination: "dont.txt"}))
 			require.NoError(t, archive.Close())
 		})
 	}
@@ -29,5 +32,81 @@ func TestArchive(t *testing.T) {
 func newArchive(folder, format string, t *testing.T) Archive {
 	file, err := os.Create(folder + "/folder." + format)
 	require.NoError(t, err)
-	return New(file)
+	archive, err := New(file)
+	require.NoError(t, err)
+	return archive
+}
+
+func TestArchiveUnsupportedFormat(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "folder.rar"))
+	require.NoError(t, err)
+	defer file.Close() // nolint: errcheck
+	_, err = New(file)
+	require.EqualError(t, err, "unsupported archive format: "+file.Name()+": should be one of tar.gz, tar.xz, tar.zst, tar.bz2, tar, zip, gz")
+}
+
+func TestArchiveLevel(t *testing.T) {
+	var folder = t.TempDir()
+	for format, level := range map[string]int{
+		"tar.gz":  10,
+		"tar.xz":  10,
+		"tar.zst": 23,
+		"tar.bz2": -1,
+		"zip":     10,
+		"gz":      10,
+	} {
+		format, level := format, level
+		t.Run(format, func(t *testing.T) {
+			file, err := os.Create(filepath.Join(folder, "ok."+format))
+			require.NoError(t, err)
+			defer file.Close() // nolint: errcheck
+			archive, err := NewWithOptions(file, Options{Level: 1})
+			require.NoError(t, err)
+			require.NoError(t, archive.Close())
+
+			file, err = os.Create(filepath.Join(folder, "invalid."+format))
+			require.NoError(t, err)
+			defer file.Close() // nolint: errcheck
+			_, err = NewWithOptions(file, Options{Level: level})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "compression level")
+		})
+	}
+}
+
+func TestArchiveLevelTar(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "invalid.tar"))
+	require.NoError(t, err)
+	defer file.Close() // nolint: errcheck
+	_, err = NewWithOptions(file, Options{Level: 1})
+	require.EqualError(t, err, "invalid compression level: 1: tar archives are not compressed")
+}
+
+func TestReproducibleArchive(t *testing.T) {
+	var folder = t.TempDir()
+	var path = filepath.Join(folder, "file.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("file"), 0644))
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, format := range Formats {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			var build = func(name string, modified time.Time) []byte {
+				require.NoError(t, os.Chtimes(path, modified, modified))
+				file, err := os.Create(filepath.Join(folder, name+"."+format))
+				require.NoError(t, err)
+				archive, err := NewWithOptions(file, Options{Mtime: mtime})
+				require.NoError(t, err)
+				require.NoError(t, archive.Add(config.File{Source: path, Destination: "file.txt"}))
+				require.NoError(t, archive.Close())
+				require.NoError(t, file.Close())
+				bts, err := ioutil.ReadFile(file.Name())
+				require.NoError(t, err)
+				return bts
+			}
+			var first = build("first", time.Now())
+			var second = build("second", time.Now().Add(-time.Hour))
+			require.Equal(t, first, second)
+		})
+	}
 }
@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Archive as gz.
 type Archive struct {
 	gw *gzip.Writer
+
+	mtime time.Time
 }
 
 // Close all closeables.
@@ -19,21 +24,33 @@ func (a Archive) Close() error {
 	return a.gw.Close()
 }
 
-// New gz archive.
-func New(target io.Writer) Archive {
-	// the error will be nil since the compression level is valid
-	gw, _ := gzip.NewWriterLevel(target, gzip.BestCompression)
-	return Archive{
-		gw: gw,
+// New gz archive with the given gzip compression level, from 1 to 9, or the
+// best compression if 0. A non-zero mtime is set as the modification time of
+// the archived file.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	if level == 0 {
+		level = gzip.BestCompression
 	}
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		return Archive{}, fmt.Errorf("invalid gzip compression level: %d: should be between 1 and 9", level)
+	}
+	gw, err := gzip.NewWriterLevel(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		gw:    gw,
+		mtime: mtime,
+	}, nil
 }
 
-// Add file to the archive.
-func (a Archive) Add(name, path string) error {
+// Add file to the archive. Only its modification time is kept, since gz has
+// no owners nor modes.
+func (a Archive) Add(f config.File) error {
 	if a.gw.Header.Name != "" {
-		return fmt.Errorf("gzip: failed to add %s, only one file can be archived in gz format", name)
+		return fmt.Errorf("gzip: failed to add %s, only one file can be archived in gz format", f.Destination)
 	}
-	file, err := os.Open(path) // #nosec
+	file, err := os.Open(f.Source) // #nosec
 	if err != nil {
 		return err
 	}
@@ -45,8 +62,14 @@ func (a Archive) Add(name, path string) error {
 	if info.IsDir() {
 		return nil
 	}
-	a.gw.Header.Name = name
+	a.gw.Header.Name = f.Destination
 	a.gw.Header.ModTime = info.ModTime()
+	if !a.mtime.IsZero() {
+		a.gw.Header.ModTime = a.mtime
+	}
+	if !f.Info.ParsedMTime.IsZero() {
+		a.gw.Header.ModTime = f.Info.ParsedMTime
+	}
 	_, err = io.Copy(a.gw, file)
 	return err
 }
@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,10 +18,11 @@ func TestGzFile(t *testing.T) {
 	f, err := os.Create(filepath.Join(tmp, "test.gz"))
 	require.NoError(t, err)
 	defer f.Close() // nolint: errcheck
-	archive := New(f)
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
 
-	require.NoError(t, archive.Add("sub1/sub2/subfoo.txt", "../testdata/sub1/sub2/subfoo.txt"))
-	require.EqualError(t, archive.Add("foo.txt", "../testdata/foo.txt"), "gzip: failed to add foo.txt, only one file can be archived in gz format")
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2/subfoo.txt", Destination: "sub1/sub2/subfoo.txt"}))
+	require.EqualError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}), "gzip: failed to add foo.txt, only one file can be archived in gz format")
 	require.NoError(t, archive.Close())
 
 	require.NoError(t, f.Close())
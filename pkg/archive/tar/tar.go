@@ -0,0 +1,103 @@
+// Package tar implements the Archive interface providing uncompressed tar
+// archiving.
+package tar
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Archive as tar.
+type Archive struct {
+	tw *tar.Writer
+
+	mtime time.Time
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	return a.tw.Close()
+}
+
+// New tar archive. A non-zero mtime is set as the modification time of all
+// its entries, which are also owned by root.
+func New(target io.Writer, mtime time.Time) Archive {
+	return Archive{
+		tw:    tar.NewWriter(target),
+		mtime: mtime,
+	}
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	file, err := os.Open(f.Source) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, f.Destination)
+	if err != nil {
+		return err
+	}
+	header.Name = f.Destination
+	if !a.mtime.IsZero() {
+		normalize(header, a.mtime)
+	}
+	apply(header, f.Info)
+	if err = a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	_, err = io.Copy(a.tw, file)
+	return err
+}
+
+func normalize(header *tar.Header, mtime time.Time) {
+	header.ModTime = mtime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid = 0
+	header.Gid = 0
+	header.Uname = ""
+	header.Gname = ""
+}
+
+// apply sets the file info given in the config, which takes precedence over
+// the one of the file on disk and the reproducible one.
+func apply(header *tar.Header, info config.FileInfo) {
+	if info.Owner != "" {
+		header.Uid, header.Uname = owner(info.Owner, header.Uid)
+	}
+	if info.Group != "" {
+		header.Gid, header.Gname = owner(info.Group, header.Gid)
+	}
+	if info.Mode != 0 {
+		header.Mode = int64(info.Mode.Perm())
+	}
+	if !info.ParsedMTime.IsZero() {
+		header.ModTime = info.ParsedMTime
+	}
+}
+
+// owner gives the id and the name of the given owner or group, which is either
+// numeric or a name. root is 0, and other names keep the given id.
+func owner(s string, id int) (int, string) {
+	if n, err := strconv.ParseUint(s, 10, 31); err == nil {
+		return int(n), ""
+	}
+	if s == "root" {
+		return 0, s
+	}
+	return id, s
+}
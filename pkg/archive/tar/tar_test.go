@@ -0,0 +1,141 @@
+package tar
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.tar"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive := New(f, time.Time{})
+
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	bts, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	r := tar.NewReader(bytes.NewReader(bts))
+	var paths []string
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, next.Name)
+		if next.Name == "sub1/executable" {
+			var ex = next.FileInfo().Mode() | 0111
+			require.Equal(t, next.FileInfo().Mode().String(), ex.String())
+		}
+	}
+	require.Equal(t, []string{
+		"foo.txt",
+		"sub1",
+		"sub1/bar.txt",
+		"sub1/executable",
+	}, paths)
+}
+
+func TestTarFileReproducible(t *testing.T) {
+	var buf bytes.Buffer
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive := New(&buf, mtime)
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Close())
+
+	var bts = buf.Bytes()
+	r := tar.NewReader(bytes.NewReader(bts))
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(next.ModTime), next.Name)
+		require.Equal(t, 0, next.Uid)
+		require.Empty(t, next.Uname)
+	}
+}
+
+func TestTarFileInfo(t *testing.T) {
+	var buf bytes.Buffer
+	var now = time.Now().Truncate(time.Second)
+	archive := New(&buf, now.Add(-time.Hour))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "etc/foo.conf",
+		Info: config.FileInfo{
+			Owner:       "carlos",
+			Group:       "root",
+			Mode:        0600,
+			ParsedMTime: now,
+		},
+	}))
+	require.NoError(t, archive.Close())
+
+	r := tar.NewReader(&buf)
+	next, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, "etc/foo.conf", next.Name)
+	require.Equal(t, "carlos", next.Uname)
+	require.Equal(t, 0, next.Uid)
+	require.Equal(t, "root", next.Gname)
+	require.Equal(t, 0, next.Gid)
+	require.Equal(t, int64(0600), next.Mode)
+	require.True(t, now.Equal(next.ModTime))
+}
+
+func TestTarFileInfoNumericOwner(t *testing.T) {
+	var buf bytes.Buffer
+	archive := New(&buf, time.Time{})
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "foo.txt",
+		Info: config.FileInfo{
+			Owner: "1000",
+			Group: "root",
+		},
+	}))
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/foo.txt",
+		Destination: "bar.txt",
+		Info: config.FileInfo{
+			Owner: "root",
+			Group: "100",
+		},
+	}))
+	require.NoError(t, archive.Close())
+
+	r := tar.NewReader(&buf)
+	next, err := r.Next()
+	require.NoError(t, err)
+	require.Equal(t, 1000, next.Uid)
+	require.Empty(t, next.Uname)
+	require.Equal(t, 0, next.Gid)
+	require.Equal(t, "root", next.Gname)
+
+	next, err = r.Next()
+	require.NoError(t, err)
+	require.Equal(t, 0, next.Uid)
+	require.Equal(t, "root", next.Uname)
+	require.Equal(t, 100, next.Gid)
+	require.Empty(t, next.Gname)
+}
@@ -0,0 +1,52 @@
+// Package tarbz2 implements the Archive interface providing tar.bz2 archiving
+// and compression.
+package tarbz2
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Archive as tar.bz2.
+type Archive struct {
+	bw *bzip2.Writer
+	tw tar.Archive
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.bw.Close()
+}
+
+// New tar.bz2 archive with the given bzip2 compression level, from 1 to 9, or
+// the best compression if 0. A non-zero mtime is set as the modification time
+// of all its entries, which are also owned by root.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	if level == 0 {
+		level = bzip2.BestCompression
+	}
+	if level < bzip2.BestSpeed || level > bzip2.BestCompression {
+		return Archive{}, fmt.Errorf("invalid bzip2 compression level: %d: should be between 1 and 9", level)
+	}
+	bw, err := bzip2.NewWriter(target, &bzip2.WriterConfig{Level: level})
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		bw: bw,
+		tw: tar.New(bw, mtime),
+	}, nil
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
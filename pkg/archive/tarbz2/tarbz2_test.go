@@ -0,0 +1,90 @@
+package tarbz2
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarBz2File(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.tar.bz2"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
+
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	bts, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	bzr, err := bzip2.NewReader(bytes.NewReader(bts), nil)
+	require.NoError(t, err)
+	defer bzr.Close() // nolint: errcheck
+	r := tar.NewReader(bzr)
+	var paths []string
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, next.Name)
+		if next.Name == "sub1/executable" {
+			var ex = next.FileInfo().Mode() | 0111
+			require.Equal(t, next.FileInfo().Mode().String(), ex.String())
+		}
+	}
+	require.Equal(t, []string{
+		"foo.txt",
+		"sub1",
+		"sub1/bar.txt",
+		"sub1/executable",
+	}, paths)
+}
+
+func TestTarBz2FileReproducible(t *testing.T) {
+	var buf bytes.Buffer
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive, err := New(&buf, 0, mtime)
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Close())
+
+	var bts = buf.Bytes()
+	bzr, err := bzip2.NewReader(bytes.NewReader(bts), nil)
+	require.NoError(t, err)
+	defer bzr.Close() // nolint: errcheck
+	r := tar.NewReader(bzr)
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(next.ModTime), next.Name)
+		require.Equal(t, 0, next.Uid)
+		require.Empty(t, next.Uname)
+	}
+}
+
+func TestTarBz2InvalidLevel(t *testing.T) {
+	_, err := New(ioutil.Discard, 10, time.Time{})
+	require.EqualError(t, err, "invalid bzip2 compression level: 10: should be between 1 and 9")
+}
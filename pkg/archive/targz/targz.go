@@ -3,16 +3,19 @@
 package targz
 
 import (
-	"archive/tar"
 	"compress/gzip"
+	"fmt"
 	"io"
-	"os"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Archive as tar.gz.
 type Archive struct {
 	gw *gzip.Writer
-	tw *tar.Writer
+	tw tar.Archive
 }
 
 // Close all closeables.
@@ -23,39 +26,27 @@ func (a Archive) Close() error {
 	return a.gw.Close()
 }
 
-// New tar.gz archive.
-func New(target io.Writer) Archive {
-	// the error will be nil since the compression level is valid
-	gw, _ := gzip.NewWriterLevel(target, gzip.BestCompression)
-	tw := tar.NewWriter(gw)
+// New tar.gz archive with the given gzip compression level, from 1 to 9, or
+// the best compression if 0. A non-zero mtime is set as the modification time
+// of all its entries, which are also owned by root.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	if level == 0 {
+		level = gzip.BestCompression
+	}
+	if level < gzip.BestSpeed || level > gzip.BestCompression {
+		return Archive{}, fmt.Errorf("invalid gzip compression level: %d: should be between 1 and 9", level)
+	}
+	gw, err := gzip.NewWriterLevel(target, level)
+	if err != nil {
+		return Archive{}, err
+	}
 	return Archive{
 		gw: gw,
-		tw: tw,
-	}
+		tw: tar.New(gw, mtime),
+	}, nil
 }
 
 // Add file to the archive.
-func (a Archive) Add(name, path string) error {
-	file, err := os.Open(path) // #nosec
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	info, err := file.Stat()
-	if err != nil {
-		return err
-	}
-	header, err := tar.FileInfoHeader(info, name)
-	if err != nil {
-		return err
-	}
-	header.Name = name
-	if err = a.tw.WriteHeader(header); err != nil {
-		return err
-	}
-	if info.IsDir() {
-		return nil
-	}
-	_, err = io.Copy(a.tw, file)
-	return err
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
 }
@@ -2,13 +2,16 @@ package targz
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,18 +21,19 @@ func TestTarGzFile(t *testing.T) {
 	f, err := os.Create(filepath.Join(tmp, "test.tar.gz"))
 	require.NoError(t, err)
 	defer f.Close() // nolint: errcheck
-	archive := New(f)
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
 
-	require.Error(t, archive.Add("nope.txt", "../testdata/nope.txt"))
-	require.NoError(t, archive.Add("foo.txt", "../testdata/foo.txt"))
-	require.NoError(t, archive.Add("sub1", "../testdata/sub1"))
-	require.NoError(t, archive.Add("sub1/bar.txt", "../testdata/sub1/bar.txt"))
-	require.NoError(t, archive.Add("sub1/executable", "../testdata/sub1/executable"))
-	require.NoError(t, archive.Add("sub1/sub2", "../testdata/sub1/sub2"))
-	require.NoError(t, archive.Add("sub1/sub2/subfoo.txt", "../testdata/sub1/sub2/subfoo.txt"))
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2", Destination: "sub1/sub2"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2/subfoo.txt", Destination: "sub1/sub2/subfoo.txt"}))
 
 	require.NoError(t, archive.Close())
-	require.Error(t, archive.Add("tar.go", "tar.go"))
+	require.Error(t, archive.Add(config.File{Source: "tar.go", Destination: "tar.go"}))
 	require.NoError(t, f.Close())
 
 	t.Log(f.Name())
@@ -69,3 +73,31 @@ func TestTarGzFile(t *testing.T) {
 		"sub1/sub2/subfoo.txt",
 	}, paths)
 }
+
+func TestTarGzFileReproducible(t *testing.T) {
+	var buf bytes.Buffer
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive, err := New(&buf, 0, mtime)
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Close())
+
+	gzf, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	defer gzf.Close() // nolint: errcheck
+
+	r := tar.NewReader(gzf)
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(next.ModTime), next.Name)
+		require.Equal(t, 0, next.Uid)
+		require.Equal(t, 0, next.Gid)
+		require.Empty(t, next.Uname)
+		require.Empty(t, next.Gname)
+	}
+}
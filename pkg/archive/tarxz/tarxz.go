@@ -3,17 +3,34 @@
 package tarxz
 
 import (
-	"archive/tar"
+	"fmt"
 	"io"
-	"os"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/ulikunitz/xz"
 )
 
+// dictCaps are the dictionary sizes of the compression levels, as in the
+// presets of xz.
+// nolint: gochecknoglobals
+var dictCaps = map[int]int{
+	1: 1 << 20,
+	2: 2 << 20,
+	3: 4 << 20,
+	4: 4 << 20,
+	5: 8 << 20,
+	6: 8 << 20,
+	7: 16 << 20,
+	8: 32 << 20,
+	9: 64 << 20,
+}
+
 // Archive as tar.xz.
 type Archive struct {
 	xzw *xz.Writer
-	tw  *tar.Writer
+	tw  tar.Archive
 }
 
 // Close all closeables.
@@ -24,38 +41,27 @@ func (a Archive) Close() error {
 	return a.xzw.Close()
 }
 
-// New tar.xz archive.
-func New(target io.Writer) Archive {
-	xzw, _ := xz.WriterConfig{DictCap: 16 * 1024 * 1024}.NewWriter(target)
-	tw := tar.NewWriter(xzw)
+// New tar.xz archive with the given xz compression level, from 1 to 9 as the
+// presets of xz, or 7 if 0. A non-zero mtime is set as the modification time
+// of all its entries, which are also owned by root.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	if level == 0 {
+		level = 7
+	}
+	if level < 1 || level > 9 {
+		return Archive{}, fmt.Errorf("invalid xz compression level: %d: should be between 1 and 9", level)
+	}
+	xzw, err := xz.WriterConfig{DictCap: dictCaps[level]}.NewWriter(target)
+	if err != nil {
+		return Archive{}, err
+	}
 	return Archive{
 		xzw: xzw,
-		tw:  tw,
-	}
+		tw:  tar.New(xzw, mtime),
+	}, nil
 }
 
 // Add file to the archive.
-func (a Archive) Add(name, path string) error {
-	file, err := os.Open(path) // #nosec
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	info, err := file.Stat()
-	if err != nil {
-		return err
-	}
-	header, err := tar.FileInfoHeader(info, name)
-	if err != nil {
-		return err
-	}
-	header.Name = name
-	if err = a.tw.WriteHeader(header); err != nil {
-		return err
-	}
-	if info.IsDir() {
-		return nil
-	}
-	_, err = io.Copy(a.tw, file)
-	return err
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
 }
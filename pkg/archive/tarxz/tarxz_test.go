@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 	"github.com/ulikunitz/xz"
 )
@@ -18,18 +20,19 @@ func TestTarXzFile(t *testing.T) {
 	f, err := os.Create(filepath.Join(tmp, "test.tar.xz"))
 	require.NoError(t, err)
 	defer f.Close() // nolint: errcheck
-	archive := New(f)
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
 
-	require.Error(t, archive.Add("nope.txt", "../testdata/nope.txt"))
-	require.NoError(t, archive.Add("foo.txt", "../testdata/foo.txt"))
-	require.NoError(t, archive.Add("sub1", "../testdata/sub1"))
-	require.NoError(t, archive.Add("sub1/bar.txt", "../testdata/sub1/bar.txt"))
-	require.NoError(t, archive.Add("sub1/executable", "../testdata/sub1/executable"))
-	require.NoError(t, archive.Add("sub1/sub2", "../testdata/sub1/sub2"))
-	require.NoError(t, archive.Add("sub1/sub2/subfoo.txt", "../testdata/sub1/sub2/subfoo.txt"))
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2", Destination: "sub1/sub2"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2/subfoo.txt", Destination: "sub1/sub2/subfoo.txt"}))
 
 	require.NoError(t, archive.Close())
-	require.Error(t, archive.Add("tar.go", "tar.go"))
+	require.Error(t, archive.Add(config.File{Source: "tar.go", Destination: "tar.go"}))
 	require.NoError(t, f.Close())
 
 	t.Log(f.Name())
@@ -69,3 +72,8 @@ func TestTarXzFile(t *testing.T) {
 		"sub1/sub2/subfoo.txt",
 	}, paths)
 }
+
+func TestTarXzInvalidLevel(t *testing.T) {
+	_, err := New(ioutil.Discard, 10, time.Time{})
+	require.EqualError(t, err, "invalid xz compression level: 10: should be between 1 and 9")
+}
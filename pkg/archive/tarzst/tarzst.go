@@ -0,0 +1,53 @@
+// Package tarzst implements the Archive interface providing tar.zst archiving
+// and compression.
+package tarzst
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/archive/tar"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Archive as tar.zst.
+type Archive struct {
+	zw *zstd.Encoder
+	tw tar.Archive
+}
+
+// Close all closeables.
+func (a Archive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+// New tar.zst archive with the given zstd compression level, from 1 to 22, or
+// the best compression if 0. A non-zero mtime is set as the modification time
+// of all its entries, which are also owned by root.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	var speed = zstd.SpeedBestCompression
+	if level != 0 {
+		if level < 1 || level > 22 {
+			return Archive{}, fmt.Errorf("invalid zstd compression level: %d: should be between 1 and 22", level)
+		}
+		speed = zstd.EncoderLevelFromZstd(level)
+	}
+	zw, err := zstd.NewWriter(target, zstd.WithEncoderLevel(speed))
+	if err != nil {
+		return Archive{}, err
+	}
+	return Archive{
+		zw: zw,
+		tw: tar.New(zw, mtime),
+	}, nil
+}
+
+// Add file to the archive.
+func (a Archive) Add(f config.File) error {
+	return a.tw.Add(f)
+}
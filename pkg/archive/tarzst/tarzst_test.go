@@ -0,0 +1,90 @@
+package tarzst
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarZstFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.tar.zst"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
+
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, f.Close())
+
+	bts, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	zr, err := zstd.NewReader(bytes.NewReader(bts))
+	require.NoError(t, err)
+	defer zr.Close()
+	r := tar.NewReader(zr)
+	var paths []string
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		paths = append(paths, next.Name)
+		if next.Name == "sub1/executable" {
+			var ex = next.FileInfo().Mode() | 0111
+			require.Equal(t, next.FileInfo().Mode().String(), ex.String())
+		}
+	}
+	require.Equal(t, []string{
+		"foo.txt",
+		"sub1",
+		"sub1/bar.txt",
+		"sub1/executable",
+	}, paths)
+}
+
+func TestTarZstFileReproducible(t *testing.T) {
+	var buf bytes.Buffer
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive, err := New(&buf, 0, mtime)
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Close())
+
+	var bts = buf.Bytes()
+	zr, err := zstd.NewReader(bytes.NewReader(bts))
+	require.NoError(t, err)
+	defer zr.Close()
+	r := tar.NewReader(zr)
+	for {
+		next, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.True(t, mtime.Equal(next.ModTime), next.Name)
+		require.Equal(t, 0, next.Uid)
+		require.Empty(t, next.Uname)
+	}
+}
+
+func TestTarZstInvalidLevel(t *testing.T) {
+	_, err := New(ioutil.Discard, 23, time.Time{})
+	require.EqualError(t, err, "invalid zstd compression level: 23: should be between 1 and 22")
+}
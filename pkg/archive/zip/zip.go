@@ -5,13 +5,19 @@ package zip
 import (
 	"archive/zip"
 	"compress/flate"
+	"fmt"
 	"io"
 	"os"
+	"time"
+
+	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
 // Archive zip struct.
 type Archive struct {
 	z *zip.Writer
+
+	mtime time.Time
 }
 
 // Close all closeables.
@@ -19,20 +25,30 @@ func (a Archive) Close() error {
 	return a.z.Close()
 }
 
-// New zip archive.
-func New(target io.Writer) Archive {
+// New zip archive with the given deflate compression level, from 1 to 9, or
+// the best compression if 0. A non-zero mtime is set as the modification time
+// of all its entries.
+func New(target io.Writer, level int, mtime time.Time) (Archive, error) {
+	if level == 0 {
+		level = flate.BestCompression
+	}
+	if level < flate.BestSpeed || level > flate.BestCompression {
+		return Archive{}, fmt.Errorf("invalid zip compression level: %d: should be between 1 and 9", level)
+	}
 	compressor := zip.NewWriter(target)
 	compressor.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
-		return flate.NewWriter(out, flate.BestCompression)
+		return flate.NewWriter(out, level)
 	})
 	return Archive{
-		z: compressor,
-	}
+		z:     compressor,
+		mtime: mtime,
+	}, nil
 }
 
-// Add a file to the zip archive.
-func (a Archive) Add(name, path string) (err error) {
-	file, err := os.Open(path) // #nosec
+// Add a file to the zip archive. Zip has no owners, so the owner and group of
+// the file are ignored.
+func (a Archive) Add(f config.File) (err error) {
+	file, err := os.Open(f.Source) // #nosec
 	if err != nil {
 		return
 	}
@@ -48,8 +64,17 @@ func (a Archive) Add(name, path string) (err error) {
 	if err != nil {
 		return err
 	}
-	header.Name = name
+	header.Name = f.Destination
 	header.Method = zip.Deflate
+	if !a.mtime.IsZero() {
+		header.Modified = a.mtime.UTC()
+	}
+	if f.Info.Mode != 0 {
+		header.SetMode(info.Mode()&^os.ModePerm | f.Info.Mode.Perm())
+	}
+	if !f.Info.ParsedMTime.IsZero() {
+		header.Modified = f.Info.ParsedMTime.UTC()
+	}
 	w, err := a.z.CreateHeader(header)
 	if err != nil {
 		return err
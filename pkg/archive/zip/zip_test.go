@@ -2,12 +2,15 @@ package zip
 
 import (
 	"archive/zip"
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/goreleaser/goreleaser/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,18 +21,19 @@ func TestZipFile(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Println(f.Name())
 	defer f.Close() // nolint: errcheck
-	archive := New(f)
+	archive, err := New(f, 0, time.Time{})
+	require.NoError(t, err)
 
-	require.Error(t, archive.Add("nope.txt", "../testdata/nope.txt"))
-	require.NoError(t, archive.Add("foo.txt", "../testdata/foo.txt"))
-	require.NoError(t, archive.Add("sub1", "../testdata/sub1"))
-	require.NoError(t, archive.Add("sub1/bar.txt", "../testdata/sub1/bar.txt"))
-	require.NoError(t, archive.Add("sub1/executable", "../testdata/sub1/executable"))
-	require.NoError(t, archive.Add("sub1/sub2", "../testdata/sub1/sub2"))
-	require.NoError(t, archive.Add("sub1/sub2/subfoo.txt", "../testdata/sub1/sub2/subfoo.txt"))
+	require.Error(t, archive.Add(config.File{Source: "../testdata/nope.txt", Destination: "nope.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1", Destination: "sub1"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/executable", Destination: "sub1/executable"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2", Destination: "sub1/sub2"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/sub2/subfoo.txt", Destination: "sub1/sub2/subfoo.txt"}))
 
 	require.NoError(t, archive.Close())
-	require.Error(t, archive.Add("tar.go", "tar.go"))
+	require.Error(t, archive.Add(config.File{Source: "tar.go", Destination: "tar.go"}))
 	require.NoError(t, f.Close())
 
 	t.Log(f.Name())
@@ -60,3 +64,43 @@ func TestZipFile(t *testing.T) {
 		"sub1/sub2/subfoo.txt",
 	}, paths)
 }
+
+func TestZipFileReproducible(t *testing.T) {
+	var buf bytes.Buffer
+	var mtime = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	archive, err := New(&buf, 0, mtime)
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/foo.txt", Destination: "foo.txt"}))
+	require.NoError(t, archive.Add(config.File{Source: "../testdata/sub1/bar.txt", Destination: "sub1/bar.txt"}))
+	require.NoError(t, archive.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.File, 2)
+	for _, zf := range r.File {
+		require.True(t, mtime.Equal(zf.Modified), zf.Name)
+	}
+}
+
+func TestZipFileInfo(t *testing.T) {
+	var buf bytes.Buffer
+	var now = time.Now().Truncate(time.Second)
+	archive, err := New(&buf, 0, time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, archive.Add(config.File{
+		Source:      "../testdata/sub1/executable",
+		Destination: "bin/executable",
+		Info: config.FileInfo{
+			Mode:        0700,
+			ParsedMTime: now,
+		},
+	}))
+	require.NoError(t, archive.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.File, 1)
+	require.Equal(t, "bin/executable", r.File[0].Name)
+	require.Equal(t, os.FileMode(0700), r.File[0].Mode())
+	require.True(t, now.Equal(r.File[0].Modified))
+}
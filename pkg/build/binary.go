@@ -0,0 +1,71 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+)
+
+// Binary returns the artifact of the binary built for a target with the given
+// options, its goarm or gomips taken from the target.
+func Binary(build config.Build, options Options) *artifact.Artifact {
+	var a = &artifact.Artifact{
+		Type:   artifact.Binary,
+		Path:   options.Path,
+		Name:   options.Name,
+		Goos:   options.Os,
+		Goarch: options.Arch,
+		Extra: map[string]interface{}{
+			"Binary": strings.TrimSuffix(filepath.Base(options.Path), options.Ext),
+			"Ext":    options.Ext,
+			"ID":     build.ID,
+		},
+	}
+	if parts := strings.Split(options.Target, "_"); len(parts) == 3 {
+		if strings.HasPrefix(options.Arch, "arm") {
+			a.Goarm = parts[2]
+		}
+		if strings.HasPrefix(options.Arch, "mips") {
+			a.Gomips = parts[2]
+		}
+	}
+	return a
+}
+
+// CopyFile copies the file at src to dst with the same mode, creating the
+// parent directories of dst.
+func CopyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", src)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// the mode of an existing dst is kept by OpenFile
+	return os.Chmod(dst, info.Mode())
+}
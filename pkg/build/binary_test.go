@@ -0,0 +1,78 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinary(t *testing.T) {
+	for target, expected := range map[string]artifact.Artifact{
+		"linux_amd64":          {Goos: "linux", Goarch: "amd64"},
+		"linux_arm_7":          {Goos: "linux", Goarch: "arm", Goarm: "7"},
+		"linux_mips_softfloat": {Goos: "linux", Goarch: "mips", Gomips: "softfloat"},
+	} {
+		target := target
+		expected := expected
+		t.Run(target, func(t *testing.T) {
+			expected.Type = artifact.Binary
+			expected.Name = "foo.exe"
+			expected.Path = filepath.Join("dist", "foo_"+target, "foo.exe")
+			expected.Extra = map[string]interface{}{
+				"Binary": "foo",
+				"Ext":    ".exe",
+				"ID":     "bar",
+			}
+			require.Equal(t, &expected, Binary(config.Build{ID: "bar"}, Options{
+				Name:   "foo.exe",
+				Path:   filepath.Join("dist", "foo_"+target, "foo.exe"),
+				Ext:    ".exe",
+				Target: target,
+				Os:     expected.Goos,
+				Arch:   expected.Goarch,
+			}))
+		})
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	var folder = t.TempDir()
+	var src = filepath.Join(folder, "src")
+	require.NoError(t, ioutil.WriteFile(src, []byte("foo"), 0755))
+
+	t.Run("new", func(t *testing.T) {
+		var dst = filepath.Join(folder, "a", "b", "dst")
+		require.NoError(t, CopyFile(src, dst))
+		bts, err := ioutil.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(bts))
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode())
+	})
+
+	t.Run("existing", func(t *testing.T) {
+		var dst = filepath.Join(folder, "existing")
+		require.NoError(t, ioutil.WriteFile(dst, []byte("previous content"), 0600))
+		require.NoError(t, CopyFile(src, dst))
+		bts, err := ioutil.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, "foo", string(bts))
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		require.EqualError(t, CopyFile(folder, filepath.Join(folder, "dir")), folder+" is a directory")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		require.Error(t, CopyFile(filepath.Join(folder, "nope"), filepath.Join(folder, "dst")))
+	})
+}
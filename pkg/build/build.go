@@ -28,7 +28,12 @@ func For(lang string) Builder {
 
 // Options to be passed down to a builder.
 type Options struct {
-	Name, Path, Ext, Target, Os, Arch string
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Ext    string `json:"ext"`
+	Target string `json:"target"`
+	Os     string `json:"os"`
+	Arch   string `json:"arch"`
 }
 
 // Builder defines a builder.
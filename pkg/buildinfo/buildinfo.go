@@ -0,0 +1,97 @@
+// Package buildinfo gives the version info GoReleaser embeds in the binaries
+// of builds with buildinfo enabled.
+//
+// Import it from the main package, so the linker can set its variables:
+//
+//	fmt.Println(buildinfo.Get())
+package buildinfo
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These are set by GoReleaser with -X ldflags.
+// nolint: gochecknoglobals
+var (
+	version    string
+	commit     string
+	date       string
+	builtBy    string
+	major      string
+	minor      string
+	patch      string
+	prerelease string
+)
+
+// Info is the version info of a binary.
+type Info struct {
+	Version    string
+	Commit     string
+	Date       time.Time
+	BuiltBy    string
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+}
+
+// Get gives the version info of the running binary.
+// If it wasn't built by GoReleaser, the version is the one of the main module
+// when it was built with go install, or "dev" otherwise.
+func Get() Info {
+	var info = Info{
+		Version:    version,
+		Commit:     commit,
+		BuiltBy:    builtBy,
+		Prerelease: prerelease,
+	}
+	if info.Version == "" {
+		info.Version = moduleVersion()
+	}
+	info.Date, _ = time.Parse(time.RFC3339, date)
+	info.Major, _ = strconv.ParseUint(major, 10, 64)
+	info.Minor, _ = strconv.ParseUint(minor, 10, 64)
+	info.Patch, _ = strconv.ParseUint(patch, 10, 64)
+	return info
+}
+
+// String gives the version info as a single line, e.g.
+// "1.2.3, commit 5a1a6d3, built at 2021-01-02T03:04:05Z by goreleaser".
+func (i Info) String() string {
+	var parts = []string{i.Version}
+	if i.Commit != "" {
+		parts = append(parts, "commit "+i.Commit)
+	}
+	var built []string
+	if !i.Date.IsZero() {
+		built = append(built, "at "+i.Date.Format(time.RFC3339))
+	}
+	if i.BuiltBy != "" {
+		built = append(built, "by "+i.BuiltBy)
+	}
+	if len(built) > 0 {
+		parts = append(parts, "built "+strings.Join(built, " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Semver gives the major, minor and patch numbers of the version, and its
+// prerelease if any, e.g. 1.2.3-rc1.
+func (i Info) Semver() string {
+	var s = fmt.Sprintf("%d.%d.%d", i.Major, i.Minor, i.Patch)
+	if i.Prerelease != "" {
+		s += "-" + i.Prerelease
+	}
+	return s
+}
+
+func moduleVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
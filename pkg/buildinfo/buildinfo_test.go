@@ -0,0 +1,52 @@
+package buildinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	version = "1.2.3-rc1"
+	commit = "5a1a6d3"
+	date = "2021-01-02T03:04:05Z"
+	builtBy = "goreleaser"
+	major = "1"
+	minor = "2"
+	patch = "3"
+	prerelease = "rc1"
+	defer reset()
+
+	var info = Get()
+	require.Equal(t, Info{
+		Version:    "1.2.3-rc1",
+		Commit:     "5a1a6d3",
+		Date:       time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		BuiltBy:    "goreleaser",
+		Major:      1,
+		Minor:      2,
+		Patch:      3,
+		Prerelease: "rc1",
+	}, info)
+	require.Equal(t, "1.2.3-rc1, commit 5a1a6d3, built at 2021-01-02T03:04:05Z by goreleaser", info.String())
+	require.Equal(t, "1.2.3-rc1", info.Semver())
+}
+
+func TestGetNotSet(t *testing.T) {
+	var info = Get()
+	require.Equal(t, Info{Version: "dev"}, info)
+	require.Equal(t, "dev", info.String())
+	require.Equal(t, "0.0.0", info.Semver())
+}
+
+func reset() {
+	version = ""
+	commit = ""
+	date = ""
+	builtBy = ""
+	major = ""
+	minor = ""
+	patch = ""
+	prerelease = ""
+}
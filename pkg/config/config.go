@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	yaml "gopkg.in/yaml.v2"
@@ -183,26 +184,104 @@ func (a *FlagArray) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // Build contains the build configuration section.
 type Build struct {
-	ID           string         `yaml:",omitempty"`
-	Goos         []string       `yaml:",omitempty"`
-	Goarch       []string       `yaml:",omitempty"`
-	Goarm        []string       `yaml:",omitempty"`
-	Gomips       []string       `yaml:",omitempty"`
-	Targets      []string       `yaml:",omitempty"`
-	Ignore       []IgnoredBuild `yaml:",omitempty"`
-	Dir          string         `yaml:",omitempty"`
-	Main         string         `yaml:",omitempty"`
-	Ldflags      StringArray    `yaml:",omitempty"`
-	Flags        FlagArray      `yaml:",omitempty"`
-	Binary       string         `yaml:",omitempty"`
-	Hooks        HookConfig     `yaml:",omitempty"`
-	Env          []string       `yaml:",omitempty"`
-	Lang         string         `yaml:",omitempty"`
-	Asmflags     StringArray    `yaml:",omitempty"`
-	Gcflags      StringArray    `yaml:",omitempty"`
-	ModTimestamp string         `yaml:"mod_timestamp,omitempty"`
-	Skip         bool           `yaml:",omitempty"`
-	GoBinary     string         `yaml:",omitempty"`
+	ID              string          `yaml:",omitempty"`
+	Goos            []string        `yaml:",omitempty"`
+	Goarch          []string        `yaml:",omitempty"`
+	Goarm           []string        `yaml:",omitempty"`
+	Gomips          []string        `yaml:",omitempty"`
+	Targets         []string        `yaml:",omitempty"`
+	Ignore          []IgnoredBuild  `yaml:",omitempty"`
+	Dir             string          `yaml:",omitempty"`
+	Main            string          `yaml:",omitempty"`
+	Ldflags         StringArray     `yaml:",omitempty"`
+	Flags           FlagArray       `yaml:",omitempty"`
+	Binary          string          `yaml:",omitempty"`
+	Hooks           HookConfig      `yaml:",omitempty"`
+	Env             []string        `yaml:",omitempty"`
+	Lang            string          `yaml:",omitempty"`
+	Asmflags        StringArray     `yaml:",omitempty"`
+	Gcflags         StringArray     `yaml:",omitempty"`
+	ModTimestamp    string          `yaml:"mod_timestamp,omitempty"`
+	Skip            bool            `yaml:",omitempty"`
+	GoBinary        string          `yaml:",omitempty"`
+	Cgo             Cgo             `yaml:",omitempty"`
+	Prebuilt        PrebuiltBuild   `yaml:",omitempty"`
+	Plugin          PluginBuild     `yaml:",omitempty"`
+	WindowsResource WindowsResource `yaml:"windows_resource,omitempty"`
+	PostProcess     []PostProcess   `yaml:"post_process,omitempty"`
+	DependsOn       []string        `yaml:"depends_on,omitempty"`
+	Parallelism     int             `yaml:",omitempty"`
+	BuildInfo       bool            `yaml:"buildinfo,omitempty"`
+}
+
+// PostProcess is a step run on the binaries of a build after they are built,
+// changing them in place: upx, strip or command.
+type PostProcess struct {
+	Type    string   `yaml:",omitempty"`
+	Cmd     string   `yaml:",omitempty"`
+	Args    []string `yaml:",omitempty"`
+	Env     []string `yaml:",omitempty"`
+	Targets []string `yaml:",omitempty"`
+	Exclude []string `yaml:",omitempty"`
+}
+
+// WindowsResource is the resource linked into the windows binaries of a
+// build: their icon, manifest and version info.
+type WindowsResource struct {
+	Icon        string             `yaml:",omitempty"`
+	Manifest    string             `yaml:",omitempty"`
+	VersionInfo WindowsVersionInfo `yaml:"version_info,omitempty"`
+}
+
+// WindowsVersionInfo are the strings of the VERSIONINFO of windows binaries.
+type WindowsVersionInfo struct {
+	CompanyName      string `yaml:"company_name,omitempty"`
+	FileDescription  string `yaml:"file_description,omitempty"`
+	FileVersion      string `yaml:"file_version,omitempty"`
+	InternalName     string `yaml:"internal_name,omitempty"`
+	LegalCopyright   string `yaml:"legal_copyright,omitempty"`
+	LegalTrademarks  string `yaml:"legal_trademarks,omitempty"`
+	OriginalFilename string `yaml:"original_filename,omitempty"`
+	ProductName      string `yaml:"product_name,omitempty"`
+	ProductVersion   string `yaml:"product_version,omitempty"`
+	Comments         string `yaml:",omitempty"`
+}
+
+// PrebuiltBuild configures builds of lang prebuilt, which import binaries
+// built by other tools.
+type PrebuiltBuild struct {
+	Path string `yaml:",omitempty"`
+}
+
+// PluginBuild configures builds of lang plugin, which run a command to build
+// each target.
+type PluginBuild struct {
+	Command string `yaml:",omitempty"`
+}
+
+// Cgo configures the C toolchain used to cross-compile cgo builds.
+type Cgo struct {
+	// Toolchain sets the default C toolchain of every target: zig or cross.
+	Toolchain string               `yaml:",omitempty"`
+	Targets   map[string]CgoTarget `yaml:",omitempty"`
+}
+
+// CgoTarget is the C toolchain of a build target.
+type CgoTarget struct {
+	CC      string   `yaml:"cc,omitempty"`
+	CXX     string   `yaml:"cxx,omitempty"`
+	Sysroot string   `yaml:",omitempty"`
+	Cflags  string   `yaml:",omitempty"`
+	Ldflags string   `yaml:",omitempty"`
+	Env     []string `yaml:",omitempty"`
+}
+
+// UniversalBinary merges the darwin binaries of a build into a macOS
+// universal binary.
+type UniversalBinary struct {
+	ID           string `yaml:"id,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
+	Replace      bool   `yaml:",omitempty"`
 }
 
 type HookConfig struct {
@@ -261,14 +340,64 @@ type FormatOverride struct {
 
 // Archive config used for the archive.
 type Archive struct {
-	ID              string            `yaml:",omitempty"`
-	Builds          []string          `yaml:",omitempty"`
-	NameTemplate    string            `yaml:"name_template,omitempty"`
-	Replacements    map[string]string `yaml:",omitempty"`
-	Format          string            `yaml:",omitempty"`
-	FormatOverrides []FormatOverride  `yaml:"format_overrides,omitempty"`
-	WrapInDirectory string            `yaml:"wrap_in_directory,omitempty"`
-	Files           []string          `yaml:",omitempty"`
+	ID               string            `yaml:",omitempty"`
+	Builds           []string          `yaml:",omitempty"`
+	NameTemplate     string            `yaml:"name_template,omitempty"`
+	Replacements     map[string]string `yaml:",omitempty"`
+	Format           string            `yaml:",omitempty"`
+	FormatOverrides  []FormatOverride  `yaml:"format_overrides,omitempty"`
+	CompressionLevel int               `yaml:"compression_level,omitempty"`
+	WrapInDirectory  string            `yaml:"wrap_in_directory,omitempty"`
+	Files            []File            `yaml:",omitempty"`
+	TemplatedFiles   []TemplatedFile   `yaml:"templated_files,omitempty"`
+	Manifest         bool              `yaml:",omitempty"`
+	Fat              bool              `yaml:",omitempty"`
+	PlatformTemplate string            `yaml:"platform_template,omitempty"`
+}
+
+// TemplatedFile is a file rendered from a template into an archive.
+type TemplatedFile struct {
+	Source      string   `yaml:"src,omitempty"`
+	Destination string   `yaml:"dst,omitempty"`
+	Info        FileInfo `yaml:"info,omitempty"`
+}
+
+// File is a file to add to an archive, either a glob or a glob with its
+// destination and file info inside the archive.
+type File struct {
+	Source      string   `yaml:"src,omitempty"`
+	Destination string   `yaml:"dst,omitempty"`
+	StripParent bool     `yaml:"strip_parent,omitempty"`
+	Info        FileInfo `yaml:"info,omitempty"`
+}
+
+// FileInfo overrides the metadata of a file inside an archive.
+type FileInfo struct {
+	Owner string      `yaml:"owner,omitempty"`
+	Group string      `yaml:"group,omitempty"`
+	Mode  os.FileMode `yaml:"mode,omitempty"`
+	MTime string      `yaml:"mtime,omitempty"`
+
+	// ParsedMTime is MTime, templated and parsed as RFC3339.
+	ParsedMTime time.Time `yaml:"-"`
+}
+
+type file File
+
+// UnmarshalYAML is a custom unmarshaler that accepts files as a single glob.
+func (f *File) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err == nil {
+		*f = File{Source: str}
+		return nil
+	}
+
+	var file file
+	if err := unmarshal(&file); err != nil {
+		return err
+	}
+	*f = File(file)
+	return nil
 }
 
 // Release config used for the GitHub/GitLab release.
@@ -339,6 +468,31 @@ type NFPMOverridables struct {
 	Scripts          NFPMScripts       `yaml:"scripts,omitempty"`
 }
 
+// MSI config used to create windows installers.
+type MSI struct {
+	ID               string            `yaml:",omitempty"`
+	Builds           []string          `yaml:",omitempty"`
+	FileNameTemplate string            `yaml:"file_name_template,omitempty"`
+	Replacements     map[string]string `yaml:",omitempty"`
+	Product          string            `yaml:",omitempty"`
+	Manufacturer     string            `yaml:",omitempty"`
+	Version          string            `yaml:",omitempty"`
+	UpgradeCode      string            `yaml:"upgrade_code,omitempty"`
+	InstallDir       string            `yaml:"install_dir,omitempty"`
+	Path             bool              `yaml:",omitempty"`
+	Shortcuts        []MSIShortcut     `yaml:",omitempty"`
+	WXS              string            `yaml:"wxs,omitempty"`
+	Toolchain        string            `yaml:",omitempty"`
+}
+
+// MSIShortcut is a shortcut to a binary installed by an MSI.
+type MSIShortcut struct {
+	Name      string `yaml:",omitempty"`
+	Binary    string `yaml:",omitempty"`
+	Arguments string `yaml:",omitempty"`
+	Location  string `yaml:",omitempty"`
+}
+
 // Sign config.
 type Sign struct {
 	ID        string   `yaml:"id,omitempty"`
@@ -396,13 +550,22 @@ type Snapshot struct {
 
 // Checksum config.
 type Checksum struct {
-	NameTemplate string `yaml:"name_template,omitempty"`
-	Algorithm    string `yaml:"algorithm,omitempty"`
-	Disable      bool   `yaml:"disable,omitempty"`
+	NameTemplate              string `yaml:"name_template,omitempty"`
+	Algorithm                 string `yaml:"algorithm,omitempty"`
+	Disable                   bool   `yaml:"disable,omitempty"`
+	DockerDigestsNameTemplate string `yaml:"docker_digests_name_template,omitempty"`
+}
+
+// SBOM config.
+type SBOM struct {
+	Artifacts string   `yaml:"artifacts,omitempty"`
+	Formats   []string `yaml:"formats,omitempty"`
+	IDs       []string `yaml:"ids,omitempty"`
 }
 
 // Docker image config.
 type Docker struct {
+	ID                 string   `yaml:"id,omitempty"`
 	Binaries           []string `yaml:",omitempty"`
 	Builds             []string `yaml:",omitempty"`
 	Goos               string   `yaml:",omitempty"`
@@ -413,6 +576,25 @@ type Docker struct {
 	SkipPush           string   `yaml:"skip_push,omitempty"`
 	Files              []string `yaml:"extra_files,omitempty"`
 	BuildFlagTemplates []string `yaml:"build_flag_templates,omitempty"`
+	Use                string   `yaml:",omitempty"`
+
+	// used by the native builder only
+	BaseImage  string            `yaml:"base_image,omitempty"`
+	Entrypoint []string          `yaml:",omitempty"`
+	Cmd        []string          `yaml:",omitempty"`
+	Env        []string          `yaml:",omitempty"`
+	Labels     map[string]string `yaml:",omitempty"`
+	User       string            `yaml:",omitempty"`
+	Insecure   bool              `yaml:",omitempty"`
+}
+
+// DockerManifest config.
+type DockerManifest struct {
+	ID             string   `yaml:"id,omitempty"`
+	NameTemplate   string   `yaml:"name_template,omitempty"`
+	ImageTemplates []string `yaml:"image_templates,omitempty"`
+	SkipPush       string   `yaml:"skip_push,omitempty"`
+	Insecure       bool     `yaml:",omitempty"`
 }
 
 // Filters config.
@@ -477,6 +659,23 @@ type Publisher struct {
 	Dir       string   `yaml:",omitempty"`
 	Cmd       string   `yaml:",omitempty"`
 	Env       []string `yaml:",omitempty"`
+	Protocol  string   `yaml:",omitempty"`
+}
+
+// OCIArtifact configuration.
+type OCIArtifact struct {
+	Name         string            `yaml:",omitempty"`
+	IDs          []string          `yaml:"ids,omitempty"`
+	Registry     string            `yaml:",omitempty"`
+	Repository   string            `yaml:",omitempty"`
+	Tag          string            `yaml:",omitempty"`
+	ArtifactType string            `yaml:"artifact_type,omitempty"`
+	Annotations  map[string]string `yaml:",omitempty"`
+	Username     string            `yaml:",omitempty"`
+	Password     string            `yaml:",omitempty"`
+	Insecure     bool              `yaml:",omitempty"`
+	Checksum     bool              `yaml:",omitempty"`
+	Signature    bool              `yaml:",omitempty"`
 }
 
 // Source configuration.
@@ -486,31 +685,72 @@ type Source struct {
 	Enabled      bool   `yaml:",omitempty"`
 }
 
+// Provenance configuration.
+type Provenance struct {
+	NameTemplate string `yaml:"name_template,omitempty"`
+	BuilderID    string `yaml:"builder_id,omitempty"`
+	Enabled      bool   `yaml:",omitempty"`
+}
+
+// Reproducible configures reproducible builds and archives.
+type Reproducible struct {
+	Enabled bool `yaml:",omitempty"`
+	Verify  bool `yaml:",omitempty"`
+}
+
+// type alias to prevent stack overflowing in the custom unmarshaler.
+type reproducible Reproducible
+
+// UnmarshalYAML is a custom unmarshaler that accepts either a bool or the
+// full reproducible section.
+func (a *Reproducible) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*a = Reproducible{Enabled: enabled}
+		return nil
+	}
+
+	var r reproducible
+	if err := unmarshal(&r); err != nil {
+		return err
+	}
+	*a = Reproducible(r)
+	return nil
+}
+
 // Project includes all project configuration.
 type Project struct {
-	ProjectName   string      `yaml:"project_name,omitempty"`
-	Env           []string    `yaml:",omitempty"`
-	Release       Release     `yaml:",omitempty"`
-	Milestones    []Milestone `yaml:",omitempty"`
-	Brews         []Homebrew  `yaml:",omitempty"`
-	Scoop         Scoop       `yaml:",omitempty"`
-	Builds        []Build     `yaml:",omitempty"`
-	Archives      []Archive   `yaml:",omitempty"`
-	NFPMs         []NFPM      `yaml:"nfpms,omitempty"`
-	Snapcrafts    []Snapcraft `yaml:",omitempty"`
-	Snapshot      Snapshot    `yaml:",omitempty"`
-	Checksum      Checksum    `yaml:",omitempty"`
-	Dockers       []Docker    `yaml:",omitempty"`
-	Artifactories []Upload    `yaml:",omitempty"`
-	Uploads       []Upload    `yaml:",omitempty"`
-	Blobs         []Blob      `yaml:"blobs,omitempty"`
-	Publishers    []Publisher `yaml:"publishers,omitempty"`
-	Changelog     Changelog   `yaml:",omitempty"`
-	Dist          string      `yaml:",omitempty"`
-	Signs         []Sign      `yaml:",omitempty"`
-	EnvFiles      EnvFiles    `yaml:"env_files,omitempty"`
-	Before        Before      `yaml:",omitempty"`
-	Source        Source      `yaml:",omitempty"`
+	ProjectName       string            `yaml:"project_name,omitempty"`
+	Env               []string          `yaml:",omitempty"`
+	Release           Release           `yaml:",omitempty"`
+	Milestones        []Milestone       `yaml:",omitempty"`
+	Brews             []Homebrew        `yaml:",omitempty"`
+	Scoop             Scoop             `yaml:",omitempty"`
+	Builds            []Build           `yaml:",omitempty"`
+	UniversalBinaries []UniversalBinary `yaml:"universal_binaries,omitempty"`
+	Archives          []Archive         `yaml:",omitempty"`
+	NFPMs             []NFPM            `yaml:"nfpms,omitempty"`
+	MSIs              []MSI             `yaml:"msis,omitempty"`
+	Snapcrafts        []Snapcraft       `yaml:",omitempty"`
+	Snapshot          Snapshot          `yaml:",omitempty"`
+	Checksum          Checksum          `yaml:",omitempty"`
+	SBOM              SBOM              `yaml:"sbom,omitempty"`
+	Provenance        Provenance        `yaml:",omitempty"`
+	Dockers           []Docker          `yaml:",omitempty"`
+	DockerManifests   []DockerManifest  `yaml:"docker_manifests,omitempty"`
+	Artifactories     []Upload          `yaml:",omitempty"`
+	Uploads           []Upload          `yaml:",omitempty"`
+	Blobs             []Blob            `yaml:"blobs,omitempty"`
+	Publishers        []Publisher       `yaml:"publishers,omitempty"`
+	OCIArtifacts      []OCIArtifact     `yaml:"oci_artifacts,omitempty"`
+	Changelog         Changelog         `yaml:",omitempty"`
+	Dist              string            `yaml:",omitempty"`
+	Signs             []Sign            `yaml:",omitempty"`
+	DockerSigns       []Sign            `yaml:"docker_signs,omitempty"`
+	EnvFiles          EnvFiles          `yaml:"env_files,omitempty"`
+	Before            Before            `yaml:",omitempty"`
+	Source            Source            `yaml:",omitempty"`
+	Reproducible      Reproducible      `yaml:",omitempty"`
 
 	// this is a hack ¯\_(ツ)_/¯
 	SingleBuild Build `yaml:"build,omitempty"`
@@ -57,6 +57,50 @@ func TestFileNotFound(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestUnmarshalArchiveFiles(t *testing.T) {
+	t.Run("mixed", func(t *testing.T) {
+		var conf = `
+archives:
+- files:
+  - README.md
+  - src: dist/man/*.1
+    dst: share/man/man1
+    strip_parent: true
+    info:
+      owner: root
+      group: wheel
+      mode: 0644
+      mtime: "{{ .CommitDate }}"
+`
+		prop, err := LoadReader(strings.NewReader(conf))
+		require.NoError(t, err)
+		require.Equal(t, []File{
+			{Source: "README.md"},
+			{
+				Source:      "dist/man/*.1",
+				Destination: "share/man/man1",
+				StripParent: true,
+				Info: FileInfo{
+					Owner: "root",
+					Group: "wheel",
+					Mode:  0644,
+					MTime: "{{ .CommitDate }}",
+				},
+			},
+		}, prop.Archives[0].Files)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var conf = `
+archives:
+- files:
+  - source: README.md
+`
+		_, err := LoadReader(strings.NewReader(conf))
+		require.EqualError(t, err, "yaml: unmarshal errors:\n  line 4: field source not found in type config.file")
+	})
+}
+
 func TestInvalidFields(t *testing.T) {
 	_, err := Load("testdata/invalid_config.yml")
 	require.EqualError(t, err, "yaml: unmarshal errors:\n  line 2: field invalid_yaml not found in type config.Build")
@@ -71,3 +115,32 @@ func TestConfigWithAnchors(t *testing.T) {
 	_, err := Load("testdata/anchor.yaml")
 	require.NoError(t, err)
 }
+
+func TestUnmarshalReproducible(t *testing.T) {
+	t.Run("bool", func(t *testing.T) {
+		prop, err := LoadReader(strings.NewReader("reproducible: true"))
+		require.NoError(t, err)
+		require.Equal(t, Reproducible{Enabled: true}, prop.Reproducible)
+	})
+
+	t.Run("section", func(t *testing.T) {
+		var conf = `
+reproducible:
+  enabled: true
+  verify: true
+`
+		prop, err := LoadReader(strings.NewReader(conf))
+		require.NoError(t, err)
+		require.Equal(t, Reproducible{Enabled: true, Verify: true}, prop.Reproducible)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var conf = `
+reproducible:
+  enabled: true
+  verifyy: true
+`
+		_, err := LoadReader(strings.NewReader(conf))
+		require.EqualError(t, err, "yaml: unmarshal errors:\n  line 4: field verifyy not found in type config.reproducible")
+	})
+}
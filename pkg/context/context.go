@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/progress"
 	"github.com/goreleaser/goreleaser/pkg/config"
 )
 
@@ -77,14 +78,26 @@ type Context struct {
 	Version            string
 	Snapshot           bool
 	SkipPostBuildHooks bool
+	BuildCache         bool
 	SkipPublish        bool
 	SkipSign           bool
 	SkipValidate       bool
 	RmDist             bool
+	Resume             bool
 	PreRelease         bool
 	Deprecated         bool
 	Parallelism        int
+	SingleTarget       bool
+	Targets            []string
+	BuildIDs           []string
 	Semver             Semver
+	Progress           *progress.Progress
+}
+
+// Partial tells whether only some of the builds or targets are being built,
+// e.g. with --single-target.
+func (ctx *Context) Partial() bool {
+	return ctx.SingleTarget || len(ctx.Targets) > 0 || len(ctx.BuildIDs) > 0
 }
 
 // Semver represents a semantic version.
@@ -13,14 +13,20 @@ import (
 	"github.com/goreleaser/goreleaser/internal/pipe/checksums"
 	"github.com/goreleaser/goreleaser/internal/pipe/docker"
 	"github.com/goreleaser/goreleaser/internal/pipe/milestone"
+	"github.com/goreleaser/goreleaser/internal/pipe/msi"
 	"github.com/goreleaser/goreleaser/internal/pipe/nfpm"
+	"github.com/goreleaser/goreleaser/internal/pipe/oci"
+	"github.com/goreleaser/goreleaser/internal/pipe/postprocess"
 	"github.com/goreleaser/goreleaser/internal/pipe/project"
+	"github.com/goreleaser/goreleaser/internal/pipe/provenance"
 	"github.com/goreleaser/goreleaser/internal/pipe/release"
+	"github.com/goreleaser/goreleaser/internal/pipe/sbom"
 	"github.com/goreleaser/goreleaser/internal/pipe/scoop"
 	"github.com/goreleaser/goreleaser/internal/pipe/sign"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapcraft"
 	"github.com/goreleaser/goreleaser/internal/pipe/snapshot"
 	"github.com/goreleaser/goreleaser/internal/pipe/sourcearchive"
+	"github.com/goreleaser/goreleaser/internal/pipe/universalbinary"
 	"github.com/goreleaser/goreleaser/pkg/context"
 )
 
@@ -40,15 +46,23 @@ var Defaulters = []Defaulter{
 	release.Pipe{},
 	project.Pipe{},
 	build.Pipe{},
+	postprocess.Pipe{},
+	universalbinary.Pipe{},
 	sourcearchive.Pipe{},
 	archive.Pipe{},
+	sbom.Pipe{},
 	nfpm.Pipe{},
+	msi.Pipe{},
 	snapcraft.Pipe{},
 	checksums.Pipe{},
+	provenance.Pipe{},
 	sign.Pipe{},
 	docker.Pipe{},
+	docker.ManifestPipe{},
+	sign.DockerPipe{},
 	artifactory.Pipe{},
 	blob.Pipe{},
+	oci.Pipe{},
 	brew.Pipe{},
 	scoop.Pipe{},
 	milestone.Pipe{},